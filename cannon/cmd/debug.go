@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/mattn/go-isatty"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ethereum-optimism/optimism/cannon/debugger"
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+)
+
+var (
+	DebugInputFlag = &cli.PathFlag{
+		Name:      "input",
+		Usage:     "path of input state to debug, in JSON or binary format.",
+		TakesFile: true,
+		Value:     "state.json",
+		Required:  true,
+	}
+	DebugMetaFlag = &cli.PathFlag{
+		Name:     "meta",
+		Usage:    "path to metadata file for symbol lookup of breakpoints and program counters. None if empty.",
+		Required: false,
+	}
+	DebugScriptFlag = &cli.PathFlag{
+		Name:      "script",
+		Usage:     "path of a file with debugger commands to execute, one per line. Commands are read from stdin if empty.",
+		TakesFile: true,
+	}
+)
+
+func Debug(ctx *cli.Context) error {
+	state, err := loadState(ctx.Path(DebugInputFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	l := Logger(os.Stderr, log.LvlInfo)
+	outLog := &mipsevm.LoggingWriter{Name: "program std-out", Log: l}
+	errLog := &mipsevm.LoggingWriter{Name: "program std-err", Log: l}
+
+	meta := &mipsevm.Metadata{Symbols: nil}
+	if metaPath := ctx.Path(DebugMetaFlag.Name); metaPath != "" {
+		if meta, err = loadJSON[mipsevm.Metadata](metaPath); err != nil {
+			return fmt.Errorf("failed to load metadata: %w", err)
+		}
+	}
+
+	args := preimageServerArgs(ctx)
+	po, err := NewProcessPreimageOracle(args[0], args[1:])
+	if err != nil {
+		return fmt.Errorf("failed to create pre-image oracle process: %w", err)
+	}
+	if err := po.Start(); err != nil {
+		return fmt.Errorf("failed to start pre-image oracle server: %w", err)
+	}
+	defer func() {
+		if err := po.Close(); err != nil {
+			l.Error("failed to close pre-image server", "err", err)
+		}
+	}()
+
+	var in io.Reader = os.Stdin
+	prompt := isatty.IsTerminal(os.Stdin.Fd())
+	if script := ctx.Path(DebugScriptFlag.Name); script != "" {
+		f, err := os.Open(script)
+		if err != nil {
+			return fmt.Errorf("failed to open script: %w", err)
+		}
+		defer f.Close()
+		in, prompt = f, false
+	}
+	us := mipsevm.NewInstrumentedState(state, po, outLog, errLog)
+	stepFn := us.Step
+	if po.cmd != nil {
+		stepFn = Guard(po.cmd.ProcessState, stepFn)
+	}
+	d := debugger.NewDebugger(state, stepFn, meta, ctx.App.Writer)
+	return d.Run(ctx.Context, in, prompt)
+}
+
+var DebugCommand = &cli.Command{
+	Name:        "debug",
+	Usage:       "Debug a VM state step by step.",
+	Description: "Execute a VM state step by step with breakpoints at steps, program counters or symbols, and inspect the registers, memory, pre-image key and proofs of steps. Commands are read interactively or from a script, see the help command.",
+	Action:      Debug,
+	Flags: []cli.Flag{
+		DebugInputFlag,
+		DebugMetaFlag,
+		DebugScriptFlag,
+	},
+}
@@ -6,29 +6,28 @@ import (
 	"os/exec"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/urfave/cli/v2"
 
 	"github.com/pkg/profile"
 
 	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	"github.com/ethereum-optimism/optimism/cannon/profiler"
+	"github.com/ethereum-optimism/optimism/cannon/prover"
 	preimage "github.com/ethereum-optimism/optimism/op-preimage"
 )
 
 var (
 	RunInputFlag = &cli.PathFlag{
 		Name:      "input",
-		Usage:     "path of input JSON state. Stdin if left empty.",
+		Usage:     "path of input state, in JSON or binary format.",
 		TakesFile: true,
 		Value:     "state.json",
 		Required:  true,
 	}
 	RunOutputFlag = &cli.PathFlag{
 		Name:      "output",
-		Usage:     "path of output JSON state. Stdout if left empty.",
+		Usage:     "path of output state. Written in binary format if the path ends in .bin, .bin.gz or .bin.zst, JSON otherwise. JSON to stdout if left empty.",
 		TakesFile: true,
 		Value:     "out.json",
 		Required:  false,
@@ -54,7 +53,7 @@ var (
 	}
 	RunSnapshotFmtFlag = &cli.StringFlag{
 		Name:     "snapshot-fmt",
-		Usage:    "format for snapshot output file names.",
+		Usage:    "format for snapshot output file names. Snapshots are written in binary format if the name ends in .bin, .bin.gz or .bin.zst, JSON otherwise.",
 		Value:    "state-%d.json",
 		Required: false,
 	}
@@ -80,23 +79,24 @@ var (
 		Name:  "pprof.cpu",
 		Usage: "enable pprof cpu profiling",
 	}
+	RunTraceProfileFlag = &cli.PathFlag{
+		Name:      "trace.profile",
+		Usage:     "path to write a pprof profile of the executed program to, with program counters resolved to symbols of the --meta file. Disabled if empty.",
+		TakesFile: true,
+	}
+	RunTraceCountersFlag = &cli.PathFlag{
+		Name:      "trace.counters",
+		Usage:     "path to write the step, syscall and pre-image counters of the executed program to, as JSON. Disabled if empty.",
+		TakesFile: true,
+	}
+	RunTraceSampleFlag = &cli.Uint64Flag{
+		Name:  "trace.sample",
+		Usage: "number of steps between program counter samples of the --trace.profile",
+		Value: 100,
+	}
 )
 
-type Proof struct {
-	Step uint64 `json:"step"`
-
-	Pre  common.Hash `json:"pre"`
-	Post common.Hash `json:"post"`
-
-	StateData hexutil.Bytes `json:"state-data"`
-	ProofData hexutil.Bytes `json:"proof-data"`
-
-	OracleKey   hexutil.Bytes `json:"oracle-key,omitempty"`
-	OracleValue hexutil.Bytes `json:"oracle-value,omitempty"`
-
-	StepInput   hexutil.Bytes `json:"step-input"`
-	OracleInput hexutil.Bytes `json:"oracle-input"`
-}
+type Proof = prover.Proof
 
 type rawHint string
 
@@ -184,7 +184,7 @@ func (p *ProcessPreimageOracle) Close() error {
 	return err
 }
 
-type StepFn func(proof bool) (*mipsevm.StepWitness, error)
+type StepFn = prover.StepFn
 
 func Guard(proc *os.ProcessState, fn StepFn) StepFn {
 	return func(proof bool) (*mipsevm.StepWitness, error) {
@@ -202,12 +202,28 @@ func Guard(proc *os.ProcessState, fn StepFn) StepFn {
 
 var _ mipsevm.PreimageOracle = (*ProcessPreimageOracle)(nil)
 
+// preimageServerArgs returns the pre-image server command and its args, passed after the first '--'.
+// The command is empty if no server is specified.
+func preimageServerArgs(ctx *cli.Context) []string {
+	args := ctx.Args().Slice()
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	return args
+}
+
 func Run(ctx *cli.Context) error {
 	if ctx.Bool(RunPProfCPU.Name) {
 		defer profile.Start(profile.NoShutdownHook, profile.ProfilePath("."), profile.CPUProfile).Stop()
 	}
 
-	state, err := loadJSON[mipsevm.State](ctx.Path(RunInputFlag.Name))
+	state, err := loadState(ctx.Path(RunInputFlag.Name))
 	if err != nil {
 		return err
 	}
@@ -216,18 +232,7 @@ func Run(ctx *cli.Context) error {
 	outLog := &mipsevm.LoggingWriter{Name: "program std-out", Log: l}
 	errLog := &mipsevm.LoggingWriter{Name: "program std-err", Log: l}
 
-	// split CLI args after first '--'
-	args := ctx.Args().Slice()
-	for i, arg := range args {
-		if arg == "--" {
-			args = args[i+1:]
-			break
-		}
-	}
-	if len(args) == 0 {
-		args = []string{""}
-	}
-
+	args := preimageServerArgs(ctx)
 	po, err := NewProcessPreimageOracle(args[0], args[1:])
 	if err != nil {
 		return fmt.Errorf("failed to create pre-image oracle process: %w", err)
@@ -258,7 +263,21 @@ func Run(ctx *cli.Context) error {
 		}
 	}
 
-	us := mipsevm.NewInstrumentedState(state, po, outLog, errLog)
+	var oracle mipsevm.PreimageOracle = po
+	var prof *profiler.Profiler
+	profilePath, countersPath := ctx.Path(RunTraceProfileFlag.Name), ctx.Path(RunTraceCountersFlag.Name)
+	if profilePath != "" || countersPath != "" {
+		prof = profiler.NewProfiler(meta, ctx.Uint64(RunTraceSampleFlag.Name))
+		oracle = prof.Oracle(po)
+		// write the profile even if the run is interrupted
+		defer func() {
+			if err := writeProfile(prof, profilePath, countersPath); err != nil {
+				l.Error("failed to write trace profile", "err", err)
+			}
+		}()
+	}
+
+	us := mipsevm.NewInstrumentedState(state, oracle, outLog, errLog)
 	proofFmt := ctx.String(RunProofFmtFlag.Name)
 	snapshotFmt := ctx.String(RunSnapshotFmtFlag.Name)
 
@@ -303,35 +322,20 @@ func Run(ctx *cli.Context) error {
 			break
 		}
 
+		if prof != nil {
+			prof.Observe(state)
+		}
+
 		if snapshotAt(state) {
-			if err := writeJSON[*mipsevm.State](fmt.Sprintf(snapshotFmt, step), state, false); err != nil {
+			if err := writeState(fmt.Sprintf(snapshotFmt, step), state, false); err != nil {
 				return fmt.Errorf("failed to write state snapshot: %w", err)
 			}
 		}
 
 		if proofAt(state) {
-			preStateHash := crypto.Keccak256Hash(state.EncodeWitness())
-			witness, err := stepFn(true)
+			proof, err := prover.ProveStep(state, stepFn)
 			if err != nil {
-				return fmt.Errorf("failed at proof-gen step %d (PC: %08x): %w", step, state.PC, err)
-			}
-			postStateHash := crypto.Keccak256Hash(state.EncodeWitness())
-			proof := &Proof{
-				Step:      step,
-				Pre:       preStateHash,
-				Post:      postStateHash,
-				StateData: witness.State,
-				ProofData: witness.MemProof,
-				StepInput: witness.EncodeStepInput(),
-			}
-			if witness.HasPreimage() {
-				inp, err := witness.EncodePreimageOracleInput()
-				if err != nil {
-					return fmt.Errorf("failed to encode pre-image oracle input: %w", err)
-				}
-				proof.OracleInput = inp
-				proof.OracleKey = witness.PreimageKey[:]
-				proof.OracleValue = witness.PreimageValue
+				return err
 			}
 			if err := writeJSON[*Proof](fmt.Sprintf(proofFmt, step), proof, true); err != nil {
 				return fmt.Errorf("failed to write proof data: %w", err)
@@ -344,7 +348,7 @@ func Run(ctx *cli.Context) error {
 		}
 	}
 
-	if err := writeJSON[*mipsevm.State](ctx.Path(RunOutputFlag.Name), state, true); err != nil {
+	if err := writeState(ctx.Path(RunOutputFlag.Name), state, true); err != nil {
 		return fmt.Errorf("failed to write state output: %w", err)
 	}
 	return nil
@@ -366,5 +370,32 @@ var RunCommand = &cli.Command{
 		RunMetaFlag,
 		RunInfoAtFlag,
 		RunPProfCPU,
+		RunTraceProfileFlag,
+		RunTraceCountersFlag,
+		RunTraceSampleFlag,
 	},
 }
+
+func writeProfile(prof *profiler.Profiler, profilePath string, countersPath string) error {
+	if profilePath != "" {
+		f, err := os.Create(profilePath)
+		if err != nil {
+			return fmt.Errorf("failed to create profile file: %w", err)
+		}
+		defer f.Close()
+		if err := prof.WriteProfile(f); err != nil {
+			return fmt.Errorf("failed to write profile: %w", err)
+		}
+	}
+	if countersPath != "" {
+		f, err := os.Create(countersPath)
+		if err != nil {
+			return fmt.Errorf("failed to create counters file: %w", err)
+		}
+		defer f.Close()
+		if err := prof.WriteCounters(f); err != nil {
+			return fmt.Errorf("failed to write counters: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	"github.com/ethereum-optimism/optimism/cannon/prover"
+)
+
+var (
+	ServeInputFlag = &cli.PathFlag{
+		Name:      "input",
+		Usage:     "path of input JSON state to prove the steps of.",
+		TakesFile: true,
+		Value:     "state.json",
+		Required:  true,
+	}
+	ServeDataDirFlag = &cli.PathFlag{
+		Name:      "datadir",
+		Usage:     "directory to store state snapshots and proofs in. Existing snapshots are resumed from, so it must only be used for the same input and pre-image server.",
+		TakesFile: true,
+		Required:  true,
+	}
+	ServeSnapshotFreqFlag = &cli.Uint64Flag{
+		Name:  "snapshot-freq",
+		Usage: "number of steps between state snapshots.",
+		Value: 100_000_000,
+	}
+	ServeMaxSnapshotsFlag = &cli.IntFlag{
+		Name:  "max-snapshots",
+		Usage: "number of state snapshots to keep, the least recently used are deleted first. 0 keeps all snapshots.",
+		Value: 100,
+	}
+	ServeHTTPAddrFlag = &cli.StringFlag{
+		Name:  "http.addr",
+		Usage: "address to serve proofs on.",
+		Value: "127.0.0.1",
+	}
+	ServeHTTPPortFlag = &cli.IntFlag{
+		Name:  "http.port",
+		Usage: "port to serve proofs on.",
+		Value: 8380,
+	}
+)
+
+func Serve(ctx *cli.Context) error {
+	l := Logger(os.Stderr, log.LvlInfo)
+	outLog := &mipsevm.LoggingWriter{Name: "program std-out", Log: l}
+	errLog := &mipsevm.LoggingWriter{Name: "program std-err", Log: l}
+
+	// The pre-image server runs for the lifetime of the service, so pre-images hinted by an earlier run
+	// are still served when a later run resumes from a snapshot after the hint.
+	args := preimageServerArgs(ctx)
+	po, err := NewProcessPreimageOracle(args[0], args[1:])
+	if err != nil {
+		return fmt.Errorf("failed to create pre-image oracle process: %w", err)
+	}
+	if err := po.Start(); err != nil {
+		return fmt.Errorf("failed to start pre-image oracle server: %w", err)
+	}
+	defer func() {
+		if err := po.Close(); err != nil {
+			l.Error("failed to close pre-image server", "err", err)
+		}
+	}()
+
+	cfg := prover.Config{
+		Dir:          ctx.Path(ServeDataDirFlag.Name),
+		SnapshotFreq: ctx.Uint64(ServeSnapshotFreqFlag.Name),
+		MaxSnapshots: ctx.Int(ServeMaxSnapshotsFlag.Name),
+	}
+	p, err := prover.NewProver(l, cfg, ctx.Path(ServeInputFlag.Name), po, outLog, errLog)
+	if err != nil {
+		return fmt.Errorf("failed to create prover: %w", err)
+	}
+	addr, port := ctx.String(ServeHTTPAddrFlag.Name), ctx.Int(ServeHTTPPortFlag.Name)
+	l.Info("serving proofs", "addr", addr, "port", port)
+	return prover.ListenAndServe(ctx.Context, p, addr, port)
+}
+
+var ServeCommand = &cli.Command{
+	Name:        "serve",
+	Usage:       "Serve proofs of VM steps, resuming from state snapshots.",
+	Description: "Serve proofs of VM steps over HTTP at /proof?step=<step>. Each proof is generated by resuming from the nearest state snapshot before the step, snapshots are written periodically as the VM runs.",
+	Action:      Serve,
+	Flags: []cli.Flag{
+		ServeInputFlag,
+		ServeDataDirFlag,
+		ServeSnapshotFreqFlag,
+		ServeMaxSnapshotsFlag,
+		ServeHTTPAddrFlag,
+		ServeHTTPPortFlag,
+	},
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+)
+
+// loadState loads the state at inputPath, in any of the state formats.
+func loadState(inputPath string) (*mipsevm.State, error) {
+	if inputPath == "" {
+		return nil, errors.New("no path specified")
+	}
+	return mipsevm.LoadStateFile(inputPath)
+}
+
+// writeState writes state to outputPath, in the format of its file extension.
+// If outputPath is empty, the state is written to stdout as JSON if outIfEmpty is true.
+func writeState(outputPath string, state *mipsevm.State, outIfEmpty bool) error {
+	if outputPath != "" {
+		return mipsevm.WriteStateFile(outputPath, state)
+	} else if !outIfEmpty {
+		return nil
+	}
+	if err := mipsevm.WriteState(os.Stdout, state, mipsevm.StateFormatJSON); err != nil {
+		return err
+	}
+	if _, err := os.Stdout.Write([]byte{'\n'}); err != nil {
+		return fmt.Errorf("failed to append new-line: %w", err)
+	}
+	return nil
+}
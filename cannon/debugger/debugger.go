@@ -0,0 +1,380 @@
+package debugger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	"github.com/ethereum-optimism/optimism/cannon/prover"
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrUnknownSymbol  = errors.New("unknown symbol")
+	ErrExited         = errors.New("program exited")
+)
+
+type breakpointKind string
+
+const (
+	breakAtStep   breakpointKind = "step"
+	breakAtPC     breakpointKind = "pc"
+	breakAtSymbol breakpointKind = "symbol"
+)
+
+type breakpoint struct {
+	id   int
+	kind breakpointKind
+	// value is the step for step breakpoints, otherwise the address to break at
+	value uint64
+	name  string
+}
+
+func (b *breakpoint) String() string {
+	switch b.kind {
+	case breakAtStep:
+		return fmt.Sprintf("#%d at step %d", b.id, b.value)
+	case breakAtSymbol:
+		return fmt.Sprintf("#%d at %s (pc %08x)", b.id, b.name, b.value)
+	default:
+		return fmt.Sprintf("#%d at pc %08x", b.id, b.value)
+	}
+}
+
+// Debugger executes a program step by step, driven by text commands, stopping at breakpoints.
+type Debugger struct {
+	state  *mipsevm.State
+	stepFn prover.StepFn
+	meta   *mipsevm.Metadata
+	out    io.Writer
+
+	breakpoints []*breakpoint
+	nextID      int
+}
+
+// NewDebugger creates a debugger of the program in state, executed by stepFn.
+// Command output is written to out.
+func NewDebugger(state *mipsevm.State, stepFn prover.StepFn, meta *mipsevm.Metadata, out io.Writer) *Debugger {
+	return &Debugger{
+		state:  state,
+		stepFn: stepFn,
+		meta:   meta,
+		out:    out,
+		nextID: 1,
+	}
+}
+
+// Run executes the commands read from in, one per line, until the input ends or a quit command.
+// Errors of a command are printed, and do not stop the debugger. Empty lines and lines starting with # are skipped.
+// If prompt is true, a prompt is printed before reading each command.
+func (d *Debugger) Run(ctx context.Context, in io.Reader, prompt bool) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		if prompt {
+			_, _ = fmt.Fprint(d.out, "(cannon) ")
+		}
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		quit, err := d.Exec(ctx, line)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(d.out, "error: %v\n", err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+// Exec executes a single command. It returns true if the command quits the debugger.
+func (d *Debugger) Exec(ctx context.Context, line string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false, nil
+	}
+	cmd, args := fields[0], fields[1:]
+	switch cmd {
+	case "help", "h":
+		d.help()
+	case "quit", "q":
+		return true, nil
+	case "step", "s":
+		n := uint64(1)
+		if len(args) > 0 {
+			v, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return false, fmt.Errorf("invalid step count %q: %w", args[0], err)
+			}
+			n = v
+		}
+		return false, d.stepN(ctx, n)
+	case "continue", "c":
+		return false, d.continueRun(ctx)
+	case "break", "b":
+		if len(args) != 1 {
+			return false, errors.New("usage: break <step>|0x<pc>|<symbol>")
+		}
+		return false, d.addBreakpoint(args[0])
+	case "delete", "d":
+		if len(args) != 1 {
+			return false, errors.New("usage: delete <breakpoint id>")
+		}
+		return false, d.deleteBreakpoint(args[0])
+	case "breakpoints", "bl":
+		for _, b := range d.breakpoints {
+			_, _ = fmt.Fprintln(d.out, b)
+		}
+	case "regs", "r":
+		d.printRegisters()
+	case "mem", "m":
+		return false, d.printMemory(args)
+	case "preimage", "p":
+		d.printPreimage()
+	case "where", "w":
+		d.printLocation()
+	case "proof":
+		return false, d.proveStep()
+	case "save":
+		if len(args) != 1 {
+			return false, errors.New("usage: save <path>")
+		}
+		if err := mipsevm.WriteStateFile(args[0], d.state); err != nil {
+			return false, err
+		}
+		_, _ = fmt.Fprintf(d.out, "saved state at step %d to %s\n", d.state.Step, args[0])
+	default:
+		return false, fmt.Errorf("%w %q, see help", ErrUnknownCommand, cmd)
+	}
+	return false, nil
+}
+
+func (d *Debugger) help() {
+	_, _ = fmt.Fprint(d.out, `commands:
+  step, s [n]                 execute n steps, default 1
+  continue, c                 run until a breakpoint is hit or the program exits
+  break, b <step>|0x<pc>|<fn> break at a step, program counter, or the entry of a symbol
+  delete, d <id>              delete a breakpoint
+  breakpoints, bl             list breakpoints
+  where, w                    show the step, program counter and symbol
+  regs, r                     show the registers
+  mem, m <addr> [words]       show memory words, default 8
+  preimage, p                 show the pre-image key and offset
+  proof                       execute one step, and show its proof
+  save <path>                 write the state to path, in the format of its extension
+  quit, q                     exit the debugger
+`)
+}
+
+// step executes a single step, converting panics of the pre-image oracle into errors.
+func (d *Debugger) step(proof bool) (wit *mipsevm.StepWitness, err error) {
+	if d.state.Exited {
+		return nil, fmt.Errorf("%w with code %d at step %d", ErrExited, d.state.ExitCode, d.state.Step)
+	}
+	step, pc := d.state.Step, d.state.PC
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("step %d (pc %08x) failed: %v", step, pc, r)
+		}
+	}()
+	wit, err = d.stepFn(proof)
+	if err != nil {
+		return nil, fmt.Errorf("step %d (pc %08x) failed: %w", step, pc, err)
+	}
+	return wit, nil
+}
+
+func (d *Debugger) stepN(ctx context.Context, n uint64) error {
+	for i := uint64(0); i < n; i++ {
+		if i%100 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if _, err := d.step(false); err != nil {
+			return err
+		}
+		if d.state.Exited {
+			break
+		}
+	}
+	d.printLocation()
+	return nil
+}
+
+// continueRun runs until a breakpoint is hit, after executing at least one step,
+// so continuing from a breakpoint does not stop at it again.
+func (d *Debugger) continueRun(ctx context.Context) error {
+	for {
+		if d.state.Step%100 == 0 { // don't do the ctx err check (includes lock) too often
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if _, err := d.step(false); err != nil {
+			return err
+		}
+		if d.state.Exited {
+			d.printLocation()
+			return nil
+		}
+		if b := d.hitBreakpoint(); b != nil {
+			_, _ = fmt.Fprintf(d.out, "hit breakpoint %s\n", b)
+			d.printLocation()
+			return nil
+		}
+	}
+}
+
+func (d *Debugger) hitBreakpoint() *breakpoint {
+	for _, b := range d.breakpoints {
+		if b.kind == breakAtStep && d.state.Step == b.value {
+			return b
+		}
+		if b.kind != breakAtStep && uint64(d.state.PC) == b.value {
+			return b
+		}
+	}
+	return nil
+}
+
+func (d *Debugger) addBreakpoint(arg string) error {
+	b := &breakpoint{id: d.nextID}
+	if strings.HasPrefix(arg, "0x") {
+		pc, err := strconv.ParseUint(arg[2:], 16, 32)
+		if err != nil {
+			return fmt.Errorf("invalid pc %q: %w", arg, err)
+		}
+		b.kind, b.value = breakAtPC, pc
+	} else if step, err := strconv.ParseUint(arg, 10, 64); err == nil {
+		if step <= d.state.Step {
+			return fmt.Errorf("step %d is not after the current step %d", step, d.state.Step)
+		}
+		b.kind, b.value = breakAtStep, step
+	} else {
+		sym, ok := d.lookupSymbol(arg)
+		if !ok {
+			return fmt.Errorf("%w %q", ErrUnknownSymbol, arg)
+		}
+		b.kind, b.value, b.name = breakAtSymbol, uint64(sym.Start), sym.Name
+	}
+	d.nextID++
+	d.breakpoints = append(d.breakpoints, b)
+	_, _ = fmt.Fprintf(d.out, "breakpoint %s\n", b)
+	return nil
+}
+
+func (d *Debugger) deleteBreakpoint(arg string) error {
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil {
+		return fmt.Errorf("invalid breakpoint id %q: %w", arg, err)
+	}
+	for i, b := range d.breakpoints {
+		if b.id == id {
+			d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no breakpoint #%d", id)
+}
+
+func (d *Debugger) lookupSymbol(name string) (mipsevm.Symbol, bool) {
+	for _, sym := range d.meta.Symbols {
+		if sym.Name == name {
+			return sym, true
+		}
+	}
+	return mipsevm.Symbol{}, false
+}
+
+func (d *Debugger) printLocation() {
+	s := d.state
+	if s.Exited {
+		_, _ = fmt.Fprintf(d.out, "step %d: exited with code %d\n", s.Step, s.ExitCode)
+		return
+	}
+	_, _ = fmt.Fprintf(d.out, "step %d: pc %08x insn %08x in %s\n", s.Step, s.PC, s.Memory.GetMemory(s.PC), d.meta.LookupSymbol(s.PC))
+}
+
+// registerNames are the conventional MIPS names of the general purpose registers.
+var registerNames = [32]string{
+	"zero", "at", "v0", "v1", "a0", "a1", "a2", "a3",
+	"t0", "t1", "t2", "t3", "t4", "t5", "t6", "t7",
+	"s0", "s1", "s2", "s3", "s4", "s5", "s6", "s7",
+	"t8", "t9", "k0", "k1", "gp", "sp", "fp", "ra",
+}
+
+func (d *Debugger) printRegisters() {
+	s := d.state
+	for i := 0; i < 32; i += 4 {
+		for j := i; j < i+4; j++ {
+			_, _ = fmt.Fprintf(d.out, "%4s %08x  ", registerNames[j], s.Registers[j])
+		}
+		_, _ = fmt.Fprintln(d.out)
+	}
+	_, _ = fmt.Fprintf(d.out, "  pc %08x  nextPC %08x  lo %08x  hi %08x  heap %08x\n", s.PC, s.NextPC, s.LO, s.HI, s.Heap)
+	_, _ = fmt.Fprintf(d.out, "step %d  exited %t  exit code %d\n", s.Step, s.Exited, s.ExitCode)
+}
+
+func (d *Debugger) printMemory(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("usage: mem <addr> [words]")
+	}
+	addr, err := strconv.ParseUint(strings.TrimPrefix(args[0], "0x"), 16, 32)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", args[0], err)
+	}
+	words := uint64(8)
+	if len(args) == 2 {
+		if words, err = strconv.ParseUint(args[1], 10, 32); err != nil {
+			return fmt.Errorf("invalid word count %q: %w", args[1], err)
+		}
+	}
+	addr &^= 3
+	for i := uint64(0); i < words; i++ {
+		a := uint32(addr + 4*i)
+		if i%4 == 0 {
+			if i > 0 {
+				_, _ = fmt.Fprintln(d.out)
+			}
+			_, _ = fmt.Fprintf(d.out, "%08x:", a)
+		}
+		_, _ = fmt.Fprintf(d.out, " %08x", d.state.Memory.GetMemory(a))
+	}
+	_, _ = fmt.Fprintln(d.out)
+	return nil
+}
+
+func (d *Debugger) printPreimage() {
+	s := d.state
+	_, _ = fmt.Fprintf(d.out, "key %s (type %d) offset %d\n", s.PreimageKey, s.PreimageKey[0], s.PreimageOffset)
+	if len(s.LastHint) > 0 {
+		_, _ = fmt.Fprintf(d.out, "buffered hint %x\n", []byte(s.LastHint))
+	}
+}
+
+func (d *Debugger) proveStep() error {
+	proof, err := prover.ProveStep(d.state, d.step)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(d.out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(proof); err != nil {
+		return err
+	}
+	d.printLocation()
+	return nil
+}
@@ -0,0 +1,130 @@
+package debugger
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+)
+
+// setupDebugger debugs a program incrementing $t0 three times in "loop", then exiting with code $t0 in "exit".
+func setupDebugger(t *testing.T) (*Debugger, *mipsevm.State, *bytes.Buffer) {
+	state := &mipsevm.State{PC: 0, NextPC: 4, Memory: mipsevm.NewMemory()}
+	state.Registers[9] = 3
+	// loop
+	state.Memory.SetMemory(0x00, 0x25080001) // addiu $t0, $t0, 1
+	state.Memory.SetMemory(0x04, 0x2529ffff) // addiu $t1, $t1, -1
+	state.Memory.SetMemory(0x08, 0x1520fffd) // bne $t1, $zero, loop
+	state.Memory.SetMemory(0x0c, 0x00000000) // nop
+	// exit
+	state.Memory.SetMemory(0x10, 0x24021096) // addiu $v0, $zero, 4246 (exit_group)
+	state.Memory.SetMemory(0x14, 0x00082021) // addu $a0, $zero, $t0
+	state.Memory.SetMemory(0x18, 0x0000000c) // syscall
+	state.PreimageKey = [32]byte{0x02, 0xab}
+	state.PreimageOffset = 7
+	meta := &mipsevm.Metadata{Symbols: []mipsevm.Symbol{
+		{Name: "loop", Start: 0x00, Size: 0x10},
+		{Name: "exit", Start: 0x10, Size: 0x0c},
+	}}
+	var out bytes.Buffer
+	us := mipsevm.NewInstrumentedState(state, nil, nil, nil)
+	return NewDebugger(state, us.Step, meta, &out), state, &out
+}
+
+func run(t *testing.T, d *Debugger, script string) {
+	require.NoError(t, d.Run(context.Background(), strings.NewReader(script), false))
+}
+
+func TestStep(t *testing.T) {
+	d, state, out := setupDebugger(t)
+	run(t, d, "step\nstep 3\n")
+	require.EqualValues(t, 4, state.Step)
+	require.EqualValues(t, 1, state.Registers[8])
+	require.Contains(t, out.String(), "step 1: pc 00000004 insn 2529ffff in loop\n")
+	require.Contains(t, out.String(), "step 4: pc 00000000 insn 25080001 in loop\n")
+}
+
+func TestBreakpoints(t *testing.T) {
+	t.Run("Symbol", func(t *testing.T) {
+		d, state, out := setupDebugger(t)
+		run(t, d, "break exit\ncontinue\n")
+		require.EqualValues(t, 0x10, state.PC)
+		require.EqualValues(t, 3, state.Registers[8])
+		require.Contains(t, out.String(), "hit breakpoint #1 at exit (pc 00000010)\n")
+	})
+
+	t.Run("PC", func(t *testing.T) {
+		d, state, _ := setupDebugger(t)
+		// the loop start is hit again on every iteration
+		run(t, d, "break 0x0\ncontinue\ncontinue\n")
+		require.EqualValues(t, 0, state.PC)
+		require.EqualValues(t, 8, state.Step)
+	})
+
+	t.Run("Step", func(t *testing.T) {
+		d, state, _ := setupDebugger(t)
+		run(t, d, "b 6\nc\n")
+		require.EqualValues(t, 6, state.Step)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		d, state, out := setupDebugger(t)
+		run(t, d, "b loop\nb 5\nd 1\nbl\nc\nc\n")
+		require.Contains(t, out.String(), "#2 at step 5\n")
+		require.NotContains(t, out.String(), "hit breakpoint #1")
+		require.True(t, state.Exited)
+		require.EqualValues(t, 3, state.ExitCode)
+		require.Contains(t, out.String(), "exited with code 3\n")
+	})
+
+	t.Run("UnknownSymbol", func(t *testing.T) {
+		d, _, out := setupDebugger(t)
+		run(t, d, "break main.main\n")
+		require.Contains(t, out.String(), "error: unknown symbol \"main.main\"\n")
+	})
+}
+
+func TestInspect(t *testing.T) {
+	d, _, out := setupDebugger(t)
+	run(t, d, "s 2\nregs\nmem 0x4 3\npreimage\n")
+	require.Contains(t, out.String(), "  t0 00000001    t1 00000002")
+	require.Contains(t, out.String(), "step 2  exited false  exit code 0\n")
+	require.Contains(t, out.String(), "00000004: 2529ffff 1520fffd 00000000\n")
+	require.Contains(t, out.String(), "key 0x02ab000000000000000000000000000000000000000000000000000000000000 (type 2) offset 7\n")
+}
+
+func TestProofAndSave(t *testing.T) {
+	d, state, out := setupDebugger(t)
+	path := filepath.Join(t.TempDir(), "state.bin")
+	run(t, d, "proof\nsave "+path+"\n")
+	require.EqualValues(t, 1, state.Step)
+	require.Contains(t, out.String(), `"step": 0,`)
+	saved, err := mipsevm.LoadStateFile(path)
+	require.NoError(t, err)
+	require.Equal(t, state.EncodeWitness(), saved.EncodeWitness())
+}
+
+func TestExitedAndErrors(t *testing.T) {
+	d, state, out := setupDebugger(t)
+	run(t, d, "c\nstep\nfoo\nq\nstep\n")
+	require.True(t, state.Exited)
+	require.Contains(t, out.String(), "error: program exited with code 3")
+	require.Contains(t, out.String(), "error: unknown command \"foo\"")
+	// commands after quit are not executed
+	require.Equal(t, 1, strings.Count(out.String(), "error: program exited"))
+}
+
+func TestMissingOracle(t *testing.T) {
+	d, state, out := setupDebugger(t)
+	state.Memory.SetMemory(0, 0x24020fa3) // addiu $v0, $zero, 4003 (read)
+	state.Memory.SetMemory(4, 0x24040005) // addiu $a0, $zero, 5 (pre-image fd)
+	state.Memory.SetMemory(8, 0x0000000c) // syscall
+	run(t, d, "s 3\nwhere\n")
+	require.Contains(t, out.String(), "error: step 2 (pc 00000008) failed")
+	require.EqualValues(t, 8, state.PC)
+}
@@ -21,6 +21,8 @@ func main() {
 	app.Commands = []*cli.Command{
 		cmd.LoadELFCommand,
 		cmd.RunCommand,
+		cmd.ServeCommand,
+		cmd.DebugCommand,
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -37,8 +37,12 @@ var zeroHashes = func() [256][32]byte {
 }()
 
 type Memory struct {
-	// generalized index -> merkle root or nil if invalidated
-	nodes map[uint64]*[32]byte
+	// generalized index -> merkle root, for every node above the pages with an allocated page in its sub-tree.
+	// Nodes on the path from a dirty page to the root are outdated until the next merkleization.
+	nodes map[uint64][32]byte
+	// gindices of the pages modified since the last merkleization, possibly with duplicates.
+	// A page is added when its root is invalidated, so every page with an invalid root is included.
+	dirty []uint64
 
 	// pageIndex -> cached page
 	pages map[uint32]*CachedPage
@@ -54,7 +58,7 @@ type Memory struct {
 
 func NewMemory() *Memory {
 	return &Memory{
-		nodes:        make(map[uint64]*[32]byte),
+		nodes:        make(map[uint64][32]byte),
 		pages:        make(map[uint32]*CachedPage),
 		lastPageKeys: [2]uint32{^uint32(0), ^uint32(0)}, // default to invalid keys, to not match any pages
 	}
@@ -80,23 +84,65 @@ func (m *Memory) Invalidate(addr uint32) {
 	}
 
 	// find page, and invalidate addr within it
-	if p, ok := m.pageLookup(addr >> PageAddrSize); ok {
-		prevValid := p.Ok[1]
-		p.Invalidate(addr & PageAddrMask)
-		if !prevValid { // if the page was already invalid before, then nodes to mem-root will also still be.
-			return
-		}
-	} else { // no page? nothing to invalidate
+	pageIndex := addr >> PageAddrSize
+	p, ok := m.pageLookup(pageIndex)
+	if !ok { // no page? nothing to invalidate
 		return
 	}
+	m.markDirty(pageIndex, p)
+	p.Invalidate(addr & PageAddrMask)
+}
 
-	// find the gindex of the first page covering the address
-	gindex := ((uint64(1) << 32) | uint64(addr)) >> PageAddrSize
+// markDirty schedules the nodes from the page to the root for merkleization, before the page is invalidated.
+// If the page root is invalid already, the page is already scheduled.
+func (m *Memory) markDirty(pageIndex uint32, p *CachedPage) {
+	if p.Ok[1] {
+		m.dirty = append(m.dirty, (1<<PageKeySize)|uint64(pageIndex))
+	}
+}
 
-	for gindex > 0 {
-		m.nodes[gindex] = nil
-		gindex >>= 1
+// merkleizeDirty recomputes the nodes on the paths from the dirty pages to the root, one level at a time,
+// so every node is hashed once, no matter how many dirty pages it covers.
+// The cost depends only on the pages modified since the last merkleization, not the memory size or run length.
+func (m *Memory) merkleizeDirty() {
+	if len(m.dirty) == 0 {
+		return
 	}
+	level := m.dirty
+	sort.Slice(level, func(i, j int) bool { return level[i] < level[j] })
+	n := 0
+	for _, gindex := range level {
+		if n > 0 && level[n-1] == gindex {
+			continue
+		}
+		m.nodes[gindex] = m.pages[uint32(gindex&PageKeyMask)].MerkleRoot()
+		level[n] = gindex
+		n++
+	}
+	level = level[:n]
+	for level[0] > 1 {
+		// parents of sorted nodes are sorted, so duplicates are adjacent
+		n = 0
+		for _, gindex := range level {
+			parent := gindex >> 1
+			if n > 0 && level[n-1] == parent {
+				continue
+			}
+			m.nodes[parent] = HashPair(m.node(parent<<1), m.node(parent<<1|1))
+			level[n] = parent
+			n++
+		}
+		level = level[:n]
+	}
+	m.dirty = m.dirty[:0]
+}
+
+// node returns the merkleized node at gindex above the pages, which is zeroed if no page exists in its sub-tree.
+func (m *Memory) node(gindex uint64) [32]byte {
+	if n, ok := m.nodes[gindex]; ok {
+		return n
+	}
+	return zeroHashes[28-bits.Len64(gindex)]
 }
 
 func (m *Memory) MerkleizeSubtree(gindex uint64) [32]byte {
@@ -114,22 +160,8 @@ func (m *Memory) MerkleizeSubtree(gindex uint64) [32]byte {
 			return zeroHashes[28-l] // page does not exist
 		}
 	}
-	if l > PageKeySize+1 {
-		panic("cannot jump into intermediate node of page")
-	}
-	n, ok := m.nodes[gindex]
-	if !ok {
-		// if the node doesn't exist, the whole sub-tree is zeroed
-		return zeroHashes[28-l]
-	}
-	if n != nil {
-		return *n
-	}
-	left := m.MerkleizeSubtree(gindex << 1)
-	right := m.MerkleizeSubtree((gindex << 1) | 1)
-	r := HashPair(left, right)
-	m.nodes[gindex] = &r
-	return r
+	m.merkleizeDirty()
+	return m.node(gindex)
 }
 
 func (m *Memory) MerkleProof(addr uint32) (out [28 * 32]byte) {
@@ -221,12 +253,7 @@ func (m *Memory) GetMemory(addr uint32) uint32 {
 func (m *Memory) AllocPage(pageIndex uint32) *CachedPage {
 	p := &CachedPage{Data: new(Page)}
 	m.pages[pageIndex] = p
-	// make nodes to root
-	k := (1 << PageKeySize) | uint64(pageIndex)
-	for k > 0 {
-		m.nodes[k] = nil
-		k >>= 1
-	}
+	m.dirty = append(m.dirty, (1<<PageKeySize)|uint64(pageIndex))
 	return p
 }
 
@@ -254,7 +281,8 @@ func (m *Memory) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &pages); err != nil {
 		return err
 	}
-	m.nodes = make(map[uint64]*[32]byte)
+	m.nodes = make(map[uint64][32]byte)
+	m.dirty = nil
 	m.pages = make(map[uint32]*CachedPage)
 	m.lastPageKeys = [2]uint32{^uint32(0), ^uint32(0)}
 	m.lastPage = [2]*CachedPage{nil, nil}
@@ -275,6 +303,7 @@ func (m *Memory) SetMemoryRange(addr uint32, r io.Reader) error {
 		if !ok {
 			p = m.AllocPage(pageIndex)
 		}
+		m.markDirty(pageIndex, p)
 		p.InvalidateFull()
 		n, err := r.Read(p.Data[pageAddr:])
 		if err != nil {
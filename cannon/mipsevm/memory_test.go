@@ -5,7 +5,9 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"io"
+	mathrand "math/rand"
 	"strings"
 	"testing"
 
@@ -187,3 +189,57 @@ func TestMemoryJSON(t *testing.T) {
 	require.NoError(t, json.Unmarshal(dat, &res))
 	require.Equal(t, uint32(123), res.GetMemory(8))
 }
+
+// merkleRootFromScratch computes the root of a copy of m, without any cached nodes.
+func merkleRootFromScratch(t require.TestingT, m *Memory) [32]byte {
+	dat, err := json.Marshal(m)
+	require.NoError(t, err)
+	var fresh Memory
+	require.NoError(t, json.Unmarshal(dat, &fresh))
+	return fresh.MerkleRoot()
+}
+
+func TestMemoryIncrementalMerkleRoot(t *testing.T) {
+	m := NewMemory()
+	rng := mathrand.New(mathrand.NewSource(1234))
+	for round := 0; round < 20; round++ {
+		for i := 0; i < 100; i++ {
+			// cluster writes in a few regions, so pages are both shared and newly allocated
+			addr := uint32(rng.Intn(4))<<28 | uint32(rng.Intn(1<<16))&^3
+			m.SetMemory(addr, rng.Uint32())
+		}
+		if round%3 == 0 {
+			// a proof within a dirty page must not leave its path to the root outdated
+			_ = m.MerkleizeSubtree((1 << (PageKeySize + 3)) | 5)
+		}
+		require.Equal(t, merkleRootFromScratch(t, m), m.MerkleRoot(), "round %d", round)
+	}
+	require.NoError(t, m.SetMemoryRange(0x1000_0ffc, bytes.NewReader(make([]byte, 2*PageSize))))
+	require.Equal(t, merkleRootFromScratch(t, m), m.MerkleRoot())
+}
+
+// BenchmarkMemoryMerkleProof measures the cost of a write followed by a proof, as done per --proof-at step,
+// after runs of different lengths. Only the pages written since the last proof are merkleized,
+// so the cost does not depend on the run length.
+func BenchmarkMemoryMerkleProof(b *testing.B) {
+	for _, runLength := range []int{1_000, 100_000, 1_000_000} {
+		runLength := runLength
+		b.Run(fmt.Sprintf("run-%d", runLength), func(b *testing.B) {
+			m := NewMemory()
+			rng := mathrand.New(mathrand.NewSource(1234))
+			randAddr := func() uint32 {
+				return uint32(rng.Intn(1<<26)) &^ 3 // 64 MiB of memory
+			}
+			for i := 0; i < runLength; i++ {
+				m.SetMemory(randAddr(), rng.Uint32())
+			}
+			_ = m.MerkleRoot()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				addr := randAddr()
+				m.SetMemory(addr, rng.Uint32())
+				_ = m.MerkleProof(addr)
+			}
+		})
+	}
+}
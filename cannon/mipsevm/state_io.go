@@ -0,0 +1,294 @@
+package mipsevm
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// StateFormat is a file encoding of the VM state.
+type StateFormat string
+
+const (
+	StateFormatJSON       StateFormat = "json"
+	StateFormatBinary     StateFormat = "bin"
+	StateFormatBinaryGzip StateFormat = "bin.gz"
+	StateFormatBinaryZstd StateFormat = "bin.zst"
+)
+
+var ErrInvalidBinaryState = errors.New("invalid binary state")
+
+const binaryStateVersion = 1
+
+// binaryStateMagic starts every binary state encoding, and can never start a JSON encoding.
+var binaryStateMagic = [4]byte{0x00, 'M', 'V', 'S'}
+
+const (
+	compressionNone uint8 = iota
+	compressionGzip
+	compressionZstd
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// StateFormatForPath returns the format of a state file by its extension.
+// Files not ending in .bin, .bin.gz or .bin.zst are JSON.
+func StateFormatForPath(path string) StateFormat {
+	for _, format := range []StateFormat{StateFormatBinary, StateFormatBinaryGzip, StateFormatBinaryZstd} {
+		if strings.HasSuffix(path, "."+string(format)) {
+			return format
+		}
+	}
+	return StateFormatJSON
+}
+
+// LoadStateFile reads the state at path, in any format.
+func LoadStateFile(path string) (*State, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file %q: %w", path, err)
+	}
+	defer f.Close()
+	state, err := ReadState(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %q: %w", path, err)
+	}
+	return state, nil
+}
+
+// WriteStateFile writes state to path, in the format of its extension.
+// The state is written to a temporary file first, so an interrupted write leaves no partial file at path.
+func WriteStateFile(path string, state *State) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open state file: %w", err)
+	}
+	if err := WriteState(f, state, StateFormatForPath(path)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadState decodes a state written with [WriteState], detecting its format.
+func ReadState(r io.Reader) (*State, error) {
+	br := bufio.NewReaderSize(r, 1<<20)
+	magic, err := br.Peek(len(binaryStateMagic))
+	if err == nil && bytes.Equal(magic, binaryStateMagic[:]) {
+		return decodeBinaryState(br)
+	}
+	var state State
+	if err := json.NewDecoder(br).Decode(&state); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON state: %w", err)
+	}
+	return &state, nil
+}
+
+// WriteState encodes state in the given format.
+func WriteState(w io.Writer, state *State, format StateFormat) error {
+	switch format {
+	case StateFormatJSON:
+		if err := json.NewEncoder(w).Encode(state); err != nil {
+			return fmt.Errorf("failed to encode to JSON: %w", err)
+		}
+		return nil
+	case StateFormatBinary:
+		return encodeBinaryState(w, state, compressionNone)
+	case StateFormatBinaryGzip:
+		return encodeBinaryState(w, state, compressionGzip)
+	case StateFormatBinaryZstd:
+		return encodeBinaryState(w, state, compressionZstd)
+	default:
+		return fmt.Errorf("unknown state format %q", format)
+	}
+}
+
+// encodeBinaryState writes the binary state encoding: the magic, a version and compression byte, and the body.
+// The body is compressed as a whole and consists of the VM registers, the last hint and then every memory page,
+// in ascending index order. The registers and every page are followed by a CRC-32C checksum.
+// All integers are big-endian.
+func encodeBinaryState(w io.Writer, state *State, compression uint8) error {
+	if _, err := w.Write(append(binaryStateMagic[:], binaryStateVersion, compression)); err != nil {
+		return err
+	}
+	var body io.WriteCloser
+	switch compression {
+	case compressionNone:
+		body = nopWriteCloser{w}
+	case compressionGzip:
+		gz, err := gzip.NewWriterLevel(w, gzip.BestSpeed)
+		if err != nil {
+			return err
+		}
+		body = gz
+	case compressionZstd:
+		zw, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		if err != nil {
+			return err
+		}
+		body = zw
+	default:
+		return fmt.Errorf("unknown compression %d", compression)
+	}
+	bw := bufio.NewWriterSize(body, 1<<20)
+
+	regs := make([]byte, 0, 32+4*6+2+8+4*32+4+len(state.LastHint))
+	regs = append(regs, state.PreimageKey[:]...)
+	regs = binary.BigEndian.AppendUint32(regs, state.PreimageOffset)
+	regs = binary.BigEndian.AppendUint32(regs, state.PC)
+	regs = binary.BigEndian.AppendUint32(regs, state.NextPC)
+	regs = binary.BigEndian.AppendUint32(regs, state.LO)
+	regs = binary.BigEndian.AppendUint32(regs, state.HI)
+	regs = binary.BigEndian.AppendUint32(regs, state.Heap)
+	regs = append(regs, state.ExitCode)
+	if state.Exited {
+		regs = append(regs, 1)
+	} else {
+		regs = append(regs, 0)
+	}
+	regs = binary.BigEndian.AppendUint64(regs, state.Step)
+	for _, r := range state.Registers {
+		regs = binary.BigEndian.AppendUint32(regs, r)
+	}
+	regs = binary.BigEndian.AppendUint32(regs, uint32(len(state.LastHint)))
+	regs = append(regs, state.LastHint...)
+	regs = binary.BigEndian.AppendUint32(regs, crc32.Checksum(regs, crcTable))
+	if _, err := bw.Write(regs); err != nil {
+		return err
+	}
+
+	indices := make([]uint32, 0, state.Memory.PageCount())
+	_ = state.Memory.ForEachPage(func(pageIndex uint32, page *Page) error {
+		indices = append(indices, pageIndex)
+		return nil
+	})
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	if err := binary.Write(bw, binary.BigEndian, uint32(len(indices))); err != nil {
+		return err
+	}
+	var index [4]byte
+	for _, pageIndex := range indices {
+		page, _ := state.Memory.pageLookup(pageIndex)
+		binary.BigEndian.PutUint32(index[:], pageIndex)
+		crc := crc32.Update(crc32.Checksum(index[:], crcTable), crcTable, page.Data[:])
+		if _, err := bw.Write(index[:]); err != nil {
+			return err
+		}
+		if _, err := bw.Write(page.Data[:]); err != nil {
+			return err
+		}
+		if err := binary.Write(bw, binary.BigEndian, crc); err != nil {
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return body.Close()
+}
+
+func decodeBinaryState(r io.Reader) (*State, error) {
+	var header [6]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("%w: failed to read header: %v", ErrInvalidBinaryState, err)
+	}
+	if version := header[4]; version != binaryStateVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidBinaryState, version)
+	}
+	switch compression := header[5]; compression {
+	case compressionNone:
+	case compressionGzip:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBinaryState, err)
+		}
+		defer gz.Close()
+		r = bufio.NewReaderSize(gz, 1<<20)
+	case compressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBinaryState, err)
+		}
+		defer zr.Close()
+		r = bufio.NewReaderSize(zr, 1<<20)
+	default:
+		return nil, fmt.Errorf("%w: unknown compression %d", ErrInvalidBinaryState, compression)
+	}
+
+	var regs [32 + 4*6 + 2 + 8 + 4*32 + 4]byte
+	if _, err := io.ReadFull(r, regs[:]); err != nil {
+		return nil, fmt.Errorf("%w: failed to read registers: %v", ErrInvalidBinaryState, err)
+	}
+	state := &State{Memory: NewMemory()}
+	copy(state.PreimageKey[:], regs[0:32])
+	state.PreimageOffset = binary.BigEndian.Uint32(regs[32:36])
+	state.PC = binary.BigEndian.Uint32(regs[36:40])
+	state.NextPC = binary.BigEndian.Uint32(regs[40:44])
+	state.LO = binary.BigEndian.Uint32(regs[44:48])
+	state.HI = binary.BigEndian.Uint32(regs[48:52])
+	state.Heap = binary.BigEndian.Uint32(regs[52:56])
+	state.ExitCode = regs[56]
+	state.Exited = regs[57] != 0
+	state.Step = binary.BigEndian.Uint64(regs[58:66])
+	for i := range state.Registers {
+		state.Registers[i] = binary.BigEndian.Uint32(regs[66+4*i:])
+	}
+	// The hint length is not checksummed yet, so the hint buffer only grows with the input actually read.
+	var hintBuf bytes.Buffer
+	hintLen := int64(binary.BigEndian.Uint32(regs[66+4*32:])) + 4
+	if _, err := io.CopyN(&hintBuf, r, hintLen); err != nil {
+		return nil, fmt.Errorf("%w: failed to read last hint: %v", ErrInvalidBinaryState, err)
+	}
+	hint := hintBuf.Bytes()
+	if len(hint) > 4 {
+		state.LastHint = hint[:len(hint)-4]
+	}
+	crc := crc32.Update(crc32.Checksum(regs[:], crcTable), crcTable, hint[:len(hint)-4])
+	if crc != binary.BigEndian.Uint32(hint[len(hint)-4:]) {
+		return nil, fmt.Errorf("%w: registers checksum mismatch", ErrInvalidBinaryState)
+	}
+
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, fmt.Errorf("%w: failed to read page count: %v", ErrInvalidBinaryState, err)
+	}
+	var buf [4 + PageSize + 4]byte
+	for i := uint32(0); i < count; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return nil, fmt.Errorf("%w: failed to read page %d: %v", ErrInvalidBinaryState, i, err)
+		}
+		pageIndex := binary.BigEndian.Uint32(buf[:4])
+		if crc32.Checksum(buf[:4+PageSize], crcTable) != binary.BigEndian.Uint32(buf[4+PageSize:]) {
+			return nil, fmt.Errorf("%w: checksum mismatch of page %d", ErrInvalidBinaryState, pageIndex)
+		}
+		if pageIndex > PageKeyMask {
+			return nil, fmt.Errorf("%w: page index %d out of range", ErrInvalidBinaryState, pageIndex)
+		}
+		if _, ok := state.Memory.pageLookup(pageIndex); ok {
+			return nil, fmt.Errorf("%w: duplicate page %d", ErrInvalidBinaryState, pageIndex)
+		}
+		copy(state.Memory.AllocPage(pageIndex).Data[:], buf[4:4+PageSize])
+	}
+	return state, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
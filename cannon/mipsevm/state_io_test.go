@@ -0,0 +1,137 @@
+package mipsevm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testState() *State {
+	state := &State{
+		Memory:         NewMemory(),
+		PreimageKey:    [32]byte{0x02, 0xaa},
+		PreimageOffset: 12,
+		PC:             0x1000,
+		NextPC:         0x1004,
+		LO:             1,
+		HI:             2,
+		Heap:           0x20000000,
+		ExitCode:       1,
+		Exited:         true,
+		Step:           123456789,
+		LastHint:       []byte{0, 0, 0, 3, 'a', 'b', 'c'},
+	}
+	for i := range state.Registers {
+		state.Registers[i] = uint32(i) * 0x01010101
+	}
+	state.Memory.SetMemory(0x1000, 0x3c01000a)
+	state.Memory.SetMemory(0x7fff_fff0, 0xdeadbeef)
+	state.Memory.SetMemory(0xffff_fffc, 0x12345678)
+	return state
+}
+
+func TestStateFormatForPath(t *testing.T) {
+	require.Equal(t, StateFormatJSON, StateFormatForPath("state.json"))
+	require.Equal(t, StateFormatJSON, StateFormatForPath("state"))
+	require.Equal(t, StateFormatBinary, StateFormatForPath("/tmp/state-%d.bin"))
+	require.Equal(t, StateFormatBinaryGzip, StateFormatForPath("state.bin.gz"))
+	require.Equal(t, StateFormatBinaryZstd, StateFormatForPath("state.bin.zst"))
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	for _, format := range []StateFormat{StateFormatJSON, StateFormatBinary, StateFormatBinaryGzip, StateFormatBinaryZstd} {
+		format := format
+		t.Run(string(format), func(t *testing.T) {
+			state := testState()
+			var buf bytes.Buffer
+			require.NoError(t, WriteState(&buf, state, format))
+			decoded, err := ReadState(&buf)
+			require.NoError(t, err)
+			require.Equal(t, state.EncodeWitness(), decoded.EncodeWitness())
+			require.Equal(t, state.LastHint, decoded.LastHint)
+			require.Equal(t, state.Memory.PageCount(), decoded.Memory.PageCount())
+		})
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	state := testState()
+	path := filepath.Join(t.TempDir(), "state.bin.zst")
+	require.NoError(t, WriteStateFile(path, state))
+	require.NoFileExists(t, path+".tmp")
+	decoded, err := LoadStateFile(path)
+	require.NoError(t, err)
+	require.Equal(t, state.EncodeWitness(), decoded.EncodeWitness())
+}
+
+func TestBinaryStateIsDeterministic(t *testing.T) {
+	state := testState()
+	var a, b bytes.Buffer
+	require.NoError(t, WriteState(&a, state, StateFormatBinary))
+	// Decoding from JSON creates the pages in a different order
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+	var fromJSON State
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	require.NoError(t, WriteState(&b, &fromJSON, StateFormatBinary))
+	require.Equal(t, a.Bytes(), b.Bytes())
+}
+
+func TestBinaryStateErrors(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteState(&buf, testState(), StateFormatBinary))
+	encoded := buf.Bytes()
+
+	t.Run("Version", func(t *testing.T) {
+		data := append([]byte(nil), encoded...)
+		data[4] = binaryStateVersion + 1
+		_, err := ReadState(bytes.NewReader(data))
+		require.ErrorIs(t, err, ErrInvalidBinaryState)
+	})
+
+	t.Run("Compression", func(t *testing.T) {
+		data := append([]byte(nil), encoded...)
+		data[5] = 99
+		_, err := ReadState(bytes.NewReader(data))
+		require.ErrorIs(t, err, ErrInvalidBinaryState)
+	})
+
+	t.Run("RegistersChecksum", func(t *testing.T) {
+		data := append([]byte(nil), encoded...)
+		data[6+36] ^= 0xff // PC
+		_, err := ReadState(bytes.NewReader(data))
+		require.ErrorIs(t, err, ErrInvalidBinaryState)
+		require.ErrorContains(t, err, "registers checksum")
+	})
+
+	t.Run("PageChecksum", func(t *testing.T) {
+		data := append([]byte(nil), encoded...)
+		data[len(data)-100] ^= 0xff
+		_, err := ReadState(bytes.NewReader(data))
+		require.ErrorIs(t, err, ErrInvalidBinaryState)
+		require.ErrorContains(t, err, "checksum mismatch of page")
+	})
+
+	t.Run("HintLength", func(t *testing.T) {
+		data := append([]byte(nil), encoded...)
+		binary.BigEndian.PutUint32(data[6+194:], math.MaxUint32)
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err := ReadState(bytes.NewReader(data))
+		runtime.ReadMemStats(&after)
+		require.ErrorIs(t, err, ErrInvalidBinaryState)
+		require.ErrorContains(t, err, "failed to read last hint")
+		require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(64<<20), "should not allocate the claimed hint length")
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		_, err := ReadState(bytes.NewReader(encoded[:len(encoded)-1]))
+		require.ErrorIs(t, err, ErrInvalidBinaryState)
+	})
+}
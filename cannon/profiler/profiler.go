@@ -0,0 +1,188 @@
+package profiler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/google/pprof/profile"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+)
+
+const (
+	sysRead = 4003
+
+	fdPreimageRead = 5
+)
+
+// syscallNames are the syscalls handled by mipsevm, all others are no-ops.
+var syscallNames = map[uint32]string{
+	4090: "mmap",
+	4045: "brk",
+	4120: "clone",
+	4246: "exit_group",
+	4003: "read",
+	4004: "write",
+	4055: "fcntl",
+}
+
+// PreimageCounters count the pre-image activity of a single pre-image key type.
+type PreimageCounters struct {
+	// Fetched is the number of pre-images requested from the oracle.
+	Fetched uint64 `json:"fetched"`
+	// Bytes is the total length of the fetched pre-images.
+	Bytes uint64 `json:"bytes"`
+	// Reads is the number of read syscalls on the pre-image file descriptor,
+	// each reading at most 4 bytes of a pre-image.
+	Reads uint64 `json:"reads"`
+}
+
+// Counters summarize the steps of a run.
+type Counters struct {
+	Steps uint64 `json:"steps"`
+	// Syscalls counts the syscalls by name.
+	Syscalls map[string]uint64 `json:"syscalls"`
+	// Preimages counts the pre-image activity by key type name.
+	Preimages map[string]*PreimageCounters `json:"preimages"`
+}
+
+// Profiler samples the program counter every sampleRate steps and counts syscalls and pre-image reads.
+// Samples are symbolized with the program metadata, to write a profile in pprof format.
+type Profiler struct {
+	meta       *mipsevm.Metadata
+	sampleRate uint64
+	samples    map[uint32]int64
+	counters   Counters
+}
+
+// NewProfiler creates a profiler sampling every sampleRate steps, or every step if sampleRate is 0.
+func NewProfiler(meta *mipsevm.Metadata, sampleRate uint64) *Profiler {
+	if sampleRate == 0 {
+		sampleRate = 1
+	}
+	return &Profiler{
+		meta:       meta,
+		sampleRate: sampleRate,
+		samples:    make(map[uint32]int64),
+		counters: Counters{
+			Syscalls:  make(map[string]uint64),
+			Preimages: make(map[string]*PreimageCounters),
+		},
+	}
+}
+
+// Observe records the step the state is about to execute. It must be called before every step.
+func (p *Profiler) Observe(state *mipsevm.State) {
+	p.counters.Steps++
+	if state.Step%p.sampleRate == 0 {
+		p.samples[state.PC]++
+	}
+	insn := state.Memory.GetMemory(state.PC)
+	if insn>>26 != 0 || insn&0x3f != 0xc { // not a syscall
+		return
+	}
+	num := state.Registers[2]
+	p.counters.Syscalls[syscallName(num)]++
+	if num == sysRead && state.Registers[4] == fdPreimageRead {
+		p.preimageCounters(state.PreimageKey[0]).Reads++
+	}
+}
+
+// Oracle wraps po to count the fetched pre-images.
+func (p *Profiler) Oracle(po mipsevm.PreimageOracle) mipsevm.PreimageOracle {
+	return &countingOracle{PreimageOracle: po, p: p}
+}
+
+// Counters returns the counters of the steps observed so far.
+func (p *Profiler) Counters() *Counters {
+	return &p.counters
+}
+
+// WriteCounters writes the counters as JSON.
+func (p *Profiler) WriteCounters(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&p.counters)
+}
+
+// Profile returns the samples as a pprof profile, with a location per sampled program counter.
+// Locations are attributed to the symbol containing them, the profile has no call stacks.
+func (p *Profiler) Profile() *profile.Profile {
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "steps", Unit: "count"}},
+		PeriodType: &profile.ValueType{Type: "steps", Unit: "count"},
+		Period:     int64(p.sampleRate),
+	}
+	pcs := make([]uint32, 0, len(p.samples))
+	for pc := range p.samples {
+		pcs = append(pcs, pc)
+	}
+	sort.Slice(pcs, func(i, j int) bool { return pcs[i] < pcs[j] })
+	functions := make(map[string]*profile.Function)
+	for _, pc := range pcs {
+		name := p.meta.LookupSymbol(pc)
+		fn, ok := functions[name]
+		if !ok {
+			fn = &profile.Function{ID: uint64(len(prof.Function) + 1), Name: name, SystemName: name}
+			functions[name] = fn
+			prof.Function = append(prof.Function, fn)
+		}
+		loc := &profile.Location{ID: uint64(len(prof.Location) + 1), Address: uint64(pc), Line: []profile.Line{{Function: fn}}}
+		prof.Location = append(prof.Location, loc)
+		count := p.samples[pc]
+		prof.Sample = append(prof.Sample, &profile.Sample{
+			Location: []*profile.Location{loc},
+			Value:    []int64{count, count * int64(p.sampleRate)},
+		})
+	}
+	return prof
+}
+
+// WriteProfile writes the samples as a gzip-compressed pprof profile.
+func (p *Profiler) WriteProfile(w io.Writer) error {
+	return p.Profile().Write(w)
+}
+
+func (p *Profiler) preimageCounters(typ byte) *PreimageCounters {
+	name := keyTypeName(preimage.KeyType(typ))
+	c, ok := p.counters.Preimages[name]
+	if !ok {
+		c = &PreimageCounters{}
+		p.counters.Preimages[name] = c
+	}
+	return c
+}
+
+type countingOracle struct {
+	mipsevm.PreimageOracle
+	p *Profiler
+}
+
+func (o *countingOracle) GetPreimage(k [32]byte) []byte {
+	data := o.PreimageOracle.GetPreimage(k)
+	c := o.p.preimageCounters(k[0])
+	c.Fetched++
+	c.Bytes += uint64(len(data))
+	return data
+}
+
+func syscallName(num uint32) string {
+	if name, ok := syscallNames[num]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown-%d", num)
+}
+
+func keyTypeName(typ preimage.KeyType) string {
+	switch typ {
+	case preimage.LocalKeyType:
+		return "local"
+	case preimage.Keccak256KeyType:
+		return "keccak256"
+	default:
+		return fmt.Sprintf("type-%d", typ)
+	}
+}
@@ -0,0 +1,115 @@
+package profiler
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+)
+
+type stubOracle map[[32]byte][]byte
+
+func (o stubOracle) Hint(v []byte) {}
+
+func (o stubOracle) GetPreimage(k [32]byte) []byte {
+	return o[k]
+}
+
+// runProgram runs a program calling brk from a loop in the "loop" function, then exit_group from "exit".
+func runProgram(t *testing.T, p *Profiler) {
+	state := &mipsevm.State{PC: 0, NextPC: 4, Memory: mipsevm.NewMemory()}
+	state.Registers[8] = 3
+	// loop: three iterations of brk
+	state.Memory.SetMemory(0x00, 0x24020fcd) // addiu $v0, $zero, 4045 (brk)
+	state.Memory.SetMemory(0x04, 0x0000000c) // syscall
+	state.Memory.SetMemory(0x08, 0x2508ffff) // addiu $t0, $t0, -1
+	state.Memory.SetMemory(0x0c, 0x1500fffc) // bne $t0, $zero, loop
+	state.Memory.SetMemory(0x10, 0x00000000) // nop
+	// exit
+	state.Memory.SetMemory(0x14, 0x24021096) // addiu $v0, $zero, 4246 (exit_group)
+	state.Memory.SetMemory(0x18, 0x0000000c) // syscall
+	us := mipsevm.NewInstrumentedState(state, nil, nil, nil)
+	for !state.Exited {
+		p.Observe(state)
+		_, err := us.Step(false)
+		require.NoError(t, err)
+	}
+}
+
+func testMetadata() *mipsevm.Metadata {
+	return &mipsevm.Metadata{Symbols: []mipsevm.Symbol{
+		{Name: "loop", Start: 0x00, Size: 0x14},
+		{Name: "exit", Start: 0x14, Size: 0x08},
+	}}
+}
+
+func TestCounters(t *testing.T) {
+	p := NewProfiler(testMetadata(), 1)
+	runProgram(t, p)
+	counters := p.Counters()
+	require.EqualValues(t, 3*5+2, counters.Steps)
+	require.Equal(t, map[string]uint64{"brk": 3, "exit_group": 1}, counters.Syscalls)
+	require.Empty(t, counters.Preimages)
+}
+
+func TestProfile(t *testing.T) {
+	p := NewProfiler(testMetadata(), 1)
+	runProgram(t, p)
+	var buf bytes.Buffer
+	require.NoError(t, p.WriteProfile(&buf))
+	prof, err := profile.Parse(&buf)
+	require.NoError(t, err)
+	require.NoError(t, prof.CheckValid())
+
+	steps := make(map[string]int64)
+	for _, sample := range prof.Sample {
+		steps[sample.Location[0].Line[0].Function.Name] += sample.Value[1]
+	}
+	require.Equal(t, map[string]int64{"loop": 15, "exit": 2}, steps)
+}
+
+func TestSampleRate(t *testing.T) {
+	p := NewProfiler(testMetadata(), 4)
+	runProgram(t, p)
+	prof := p.Profile()
+	require.EqualValues(t, 4, prof.Period)
+	var samples, steps int64
+	for _, sample := range prof.Sample {
+		samples += sample.Value[0]
+		steps += sample.Value[1]
+	}
+	// steps 0, 4, 8, 12 and 16 are sampled
+	require.EqualValues(t, 5, samples)
+	require.EqualValues(t, 20, steps)
+	// all steps are counted, regardless of the sample rate
+	require.EqualValues(t, 17, p.Counters().Steps)
+}
+
+func TestPreimageCounters(t *testing.T) {
+	p := NewProfiler(testMetadata(), 1)
+	local := preimage.LocalIndexKey(1).PreimageKey()
+	keccak := preimage.Keccak256Key{0xaa}.PreimageKey()
+	oracle := p.Oracle(stubOracle{local: []byte("abc"), keccak: make([]byte, 100)})
+	oracle.GetPreimage(local)
+	oracle.GetPreimage(keccak)
+	oracle.GetPreimage(keccak)
+
+	// read syscalls on the pre-image file descriptor are attributed to the type of the current key
+	state := &mipsevm.State{Memory: mipsevm.NewMemory(), PreimageKey: keccak}
+	state.Memory.SetMemory(0, 0x0000000c) // syscall
+	state.Registers[2] = sysRead
+	state.Registers[4] = fdPreimageRead
+	p.Observe(state)
+	state.Registers[4] = 0 // stdin
+	p.Observe(state)
+
+	require.Equal(t, map[string]*PreimageCounters{
+		"local":     {Fetched: 1, Bytes: 3},
+		"keccak256": {Fetched: 2, Bytes: 200, Reads: 1},
+	}, p.Counters().Preimages)
+	require.Equal(t, map[string]uint64{"read": 2}, p.Counters().Syscalls)
+}
@@ -0,0 +1,59 @@
+package prover
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+)
+
+type Proof struct {
+	Step uint64 `json:"step"`
+
+	Pre  common.Hash `json:"pre"`
+	Post common.Hash `json:"post"`
+
+	StateData hexutil.Bytes `json:"state-data"`
+	ProofData hexutil.Bytes `json:"proof-data"`
+
+	OracleKey   hexutil.Bytes `json:"oracle-key,omitempty"`
+	OracleValue hexutil.Bytes `json:"oracle-value,omitempty"`
+
+	StepInput   hexutil.Bytes `json:"step-input"`
+	OracleInput hexutil.Bytes `json:"oracle-input"`
+}
+
+// StepFn executes a single step of the VM, with a witness of the step if proof is true.
+type StepFn func(proof bool) (*mipsevm.StepWitness, error)
+
+// ProveStep executes the next step of state with stepFn and returns the proof of the step.
+func ProveStep(state *mipsevm.State, stepFn StepFn) (*Proof, error) {
+	step := state.Step
+	preStateHash := crypto.Keccak256Hash(state.EncodeWitness())
+	witness, err := stepFn(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed at proof-gen step %d (PC: %08x): %w", step, state.PC, err)
+	}
+	postStateHash := crypto.Keccak256Hash(state.EncodeWitness())
+	proof := &Proof{
+		Step:      step,
+		Pre:       preStateHash,
+		Post:      postStateHash,
+		StateData: witness.State,
+		ProofData: witness.MemProof,
+		StepInput: witness.EncodeStepInput(),
+	}
+	if witness.HasPreimage() {
+		inp, err := witness.EncodePreimageOracleInput()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode pre-image oracle input: %w", err)
+		}
+		proof.OracleInput = inp
+		proof.OracleKey = witness.PreimageKey[:]
+		proof.OracleValue = witness.PreimageValue
+	}
+	return proof, nil
+}
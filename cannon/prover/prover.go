@@ -0,0 +1,248 @@
+package prover
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+)
+
+const (
+	snapshotsDir = "snapshots"
+	proofsDir    = "proofs"
+	// snapshotExt selects the compressed binary state format for snapshots, the fastest to write and load.
+	snapshotExt = "." + string(mipsevm.StateFormatBinaryZstd)
+)
+
+// ErrProgramExited is returned when a proof is requested for a step after the program exited.
+var ErrProgramExited = errors.New("program exited")
+
+type Config struct {
+	// Dir is the directory to store the snapshots and proofs in.
+	// Existing snapshots and proofs are reused, so it must only be used for a single prestate and oracle.
+	Dir string
+	// SnapshotFreq is the number of steps between snapshots.
+	SnapshotFreq uint64
+	// MaxSnapshots is the number of snapshots to keep, the least recently used are deleted first.
+	// 0 keeps all snapshots.
+	MaxSnapshots int
+}
+
+// Prover generates proofs of the steps of a single program run,
+// resuming from the nearest snapshot of the run before the step rather than from the prestate.
+// Snapshots are written every SnapshotFreq steps as the program runs.
+// Runs are executed one at a time, as they share the pre-image oracle.
+type Prover struct {
+	mu       sync.Mutex
+	logger   log.Logger
+	cfg      Config
+	prestate string
+	oracle   mipsevm.PreimageOracle
+	stdOut   io.Writer
+	stdErr   io.Writer
+
+	// snapshots maps the step of each snapshot to the last time it was used, as a counter
+	snapshots map[uint64]uint64
+	uses      uint64
+}
+
+// NewProver creates a [Prover] of the run starting from the state at the prestate path,
+// with pre-images served by oracle. Snapshots already in cfg.Dir are resumed from.
+func NewProver(logger log.Logger, cfg Config, prestate string, oracle mipsevm.PreimageOracle, stdOut, stdErr io.Writer) (*Prover, error) {
+	if cfg.SnapshotFreq == 0 {
+		return nil, errors.New("snapshot frequency must not be 0")
+	}
+	for _, dir := range []string{snapshotsDir, proofsDir} {
+		if err := os.MkdirAll(filepath.Join(cfg.Dir, dir), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %v directory: %w", dir, err)
+		}
+	}
+	p := &Prover{
+		logger:    logger,
+		cfg:       cfg,
+		prestate:  prestate,
+		oracle:    oracle,
+		stdOut:    stdOut,
+		stdErr:    stdErr,
+		snapshots: make(map[uint64]uint64),
+	}
+	entries, err := os.ReadDir(filepath.Join(cfg.Dir, snapshotsDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), snapshotExt) {
+			continue
+		}
+		if step, err := strconv.ParseUint(strings.TrimSuffix(entry.Name(), snapshotExt), 10, 64); err == nil {
+			p.snapshots[step] = 0
+		}
+	}
+	logger.Info("Loaded snapshots", "count", len(p.snapshots))
+	return p, nil
+}
+
+// Proof returns the proof of the given step, generating it if it was not generated before.
+// Returns [ErrProgramExited] if the program exits before the step.
+func (p *Prover) Proof(ctx context.Context, step uint64) (*Proof, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	path := p.proofPath(step)
+	if proof, err := loadJSON[Proof](path); err == nil {
+		return proof, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	state, err := p.resumeState(step)
+	if err != nil {
+		return nil, err
+	}
+	us := mipsevm.NewInstrumentedState(state, p.oracle, p.stdOut, p.stdErr)
+	from := state.Step
+	p.logger.Info("Generating proof", "step", step, "from", from)
+	for state.Step < step && !state.Exited {
+		if state.Step%100 == 0 { // don't do the ctx err check (includes lock) too often
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if state.Step%p.cfg.SnapshotFreq == 0 && state.Step != from {
+			if err := p.snapshot(state); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := us.Step(false); err != nil {
+			return nil, fmt.Errorf("failed at step %d (PC: %08x): %w", state.Step, state.PC, err)
+		}
+	}
+	if state.Exited {
+		return nil, fmt.Errorf("%w at step %d with exit code %d", ErrProgramExited, state.Step, state.ExitCode)
+	}
+	proof, err := ProveStep(state, us.Step)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeJSON(path, proof); err != nil {
+		return nil, fmt.Errorf("failed to write proof data: %w", err)
+	}
+	if err := p.collectGarbage(); err != nil {
+		p.logger.Error("Failed to delete old snapshots", "err", err)
+	}
+	return proof, nil
+}
+
+// resumeState loads the latest snapshot at or before step, or the prestate if there is none.
+func (p *Prover) resumeState(step uint64) (*mipsevm.State, error) {
+	var from uint64
+	found := false
+	for snapshot := range p.snapshots {
+		if snapshot <= step && (!found || snapshot > from) {
+			from = snapshot
+			found = true
+		}
+	}
+	if !found {
+		state, err := mipsevm.LoadStateFile(p.prestate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load prestate: %w", err)
+		}
+		if state.Step > step {
+			return nil, fmt.Errorf("step %d is before the prestate at step %d", step, state.Step)
+		}
+		return state, nil
+	}
+	state, err := mipsevm.LoadStateFile(p.snapshotPath(from))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	p.touch(from)
+	return state, nil
+}
+
+// snapshot writes state as a snapshot, unless a snapshot of the step exists.
+func (p *Prover) snapshot(state *mipsevm.State) error {
+	if _, ok := p.snapshots[state.Step]; ok {
+		return nil
+	}
+	if err := mipsevm.WriteStateFile(p.snapshotPath(state.Step), state); err != nil {
+		return fmt.Errorf("failed to write state snapshot: %w", err)
+	}
+	p.touch(state.Step)
+	return nil
+}
+
+func (p *Prover) touch(step uint64) {
+	p.uses++
+	p.snapshots[step] = p.uses
+}
+
+// collectGarbage deletes the least recently used snapshots in excess of MaxSnapshots.
+func (p *Prover) collectGarbage() error {
+	if p.cfg.MaxSnapshots <= 0 || len(p.snapshots) <= p.cfg.MaxSnapshots {
+		return nil
+	}
+	steps := make([]uint64, 0, len(p.snapshots))
+	for step := range p.snapshots {
+		steps = append(steps, step)
+	}
+	sort.Slice(steps, func(i, j int) bool {
+		return p.snapshots[steps[i]] < p.snapshots[steps[j]]
+	})
+	for _, step := range steps[:len(steps)-p.cfg.MaxSnapshots] {
+		if err := os.Remove(p.snapshotPath(step)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		delete(p.snapshots, step)
+		p.logger.Debug("Deleted snapshot", "step", step)
+	}
+	return nil
+}
+
+func (p *Prover) snapshotPath(step uint64) string {
+	return filepath.Join(p.cfg.Dir, snapshotsDir, fmt.Sprintf("%d%s", step, snapshotExt))
+}
+
+func (p *Prover) proofPath(step uint64) string {
+	return filepath.Join(p.cfg.Dir, proofsDir, fmt.Sprintf("%d.json", step))
+}
+
+func loadJSON[X any](path string) (*X, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var value X
+	if err := json.NewDecoder(f).Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode file %q: %w", path, err)
+	}
+	return &value, nil
+}
+
+// writeJSON writes value to a temporary file first, so an interrupted write leaves no partial file at path.
+func writeJSON[X any](path string, value X) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(value); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to encode to JSON: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,137 @@
+package prover
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// writeCounterProgram writes the prestate of a program incrementing a register in an endless loop,
+// and exiting once a given number of steps is reached if exitAt is not 0.
+func writeCounterProgram(t *testing.T, dir string) string {
+	state := &mipsevm.State{PC: 0, NextPC: 4, Memory: mipsevm.NewMemory()}
+	state.Memory.SetMemory(0, 0x25080001) // addiu $t0, $t0, 1
+	state.Memory.SetMemory(4, 0x08000000) // j 0
+	state.Memory.SetMemory(8, 0)          // nop
+	path := filepath.Join(dir, "prestate.json")
+	require.NoError(t, writeJSON(path, state))
+	return path
+}
+
+// expectedProof proves step by running the program from the prestate.
+func expectedProof(t *testing.T, prestate string, step uint64) *Proof {
+	state, err := loadJSON[mipsevm.State](prestate)
+	require.NoError(t, err)
+	us := mipsevm.NewInstrumentedState(state, nil, os.Stdout, os.Stderr)
+	for state.Step < step {
+		_, err := us.Step(false)
+		require.NoError(t, err)
+	}
+	proof, err := ProveStep(state, us.Step)
+	require.NoError(t, err)
+	return proof
+}
+
+func setupProver(t *testing.T, maxSnapshots int) (*Prover, string, string) {
+	dir := t.TempDir()
+	prestate := writeCounterProgram(t, dir)
+	cfg := Config{Dir: filepath.Join(dir, "data"), SnapshotFreq: 10, MaxSnapshots: maxSnapshots}
+	p, err := NewProver(testlog.Logger(t, log.LvlError), cfg, prestate, nil, os.Stdout, os.Stderr)
+	require.NoError(t, err)
+	return p, prestate, cfg.Dir
+}
+
+func TestProof(t *testing.T) {
+	p, prestate, dir := setupProver(t, 0)
+
+	proof, err := p.Proof(context.Background(), 35)
+	require.NoError(t, err)
+	require.Equal(t, expectedProof(t, prestate, 35), proof)
+	require.Equal(t, map[uint64]uint64{10: 1, 20: 2, 30: 3}, p.snapshots)
+	require.FileExists(t, filepath.Join(dir, proofsDir, "35.json"))
+
+	// Resumes from the nearest snapshot
+	state, err := p.resumeState(27)
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), state.Step)
+	proof, err = p.Proof(context.Background(), 27)
+	require.NoError(t, err)
+	require.Equal(t, expectedProof(t, prestate, 27), proof)
+
+	// Proofs before the first snapshot are generated from the prestate
+	proof, err = p.Proof(context.Background(), 3)
+	require.NoError(t, err)
+	require.Equal(t, expectedProof(t, prestate, 3), proof)
+}
+
+func TestResumeSnapshotsFromDir(t *testing.T) {
+	p, prestate, _ := setupProver(t, 0)
+	_, err := p.Proof(context.Background(), 25)
+	require.NoError(t, err)
+
+	resumed, err := NewProver(testlog.Logger(t, log.LvlError), p.cfg, prestate, nil, os.Stdout, os.Stderr)
+	require.NoError(t, err)
+	require.Len(t, resumed.snapshots, 2)
+	proof, err := resumed.Proof(context.Background(), 22)
+	require.NoError(t, err)
+	require.Equal(t, expectedProof(t, prestate, 22), proof)
+}
+
+func TestCollectGarbage(t *testing.T) {
+	p, _, _ := setupProver(t, 2)
+	_, err := p.Proof(context.Background(), 45)
+	require.NoError(t, err)
+	// Only the two most recent snapshots are kept
+	require.Len(t, p.snapshots, 2)
+	require.Contains(t, p.snapshots, uint64(30))
+	require.Contains(t, p.snapshots, uint64(40))
+	require.NoFileExists(t, p.snapshotPath(10))
+
+	// Resuming from a snapshot makes it the most recently used
+	_, err = p.Proof(context.Background(), 33)
+	require.NoError(t, err)
+	_, err = p.Proof(context.Background(), 55)
+	require.NoError(t, err)
+	require.Len(t, p.snapshots, 2)
+	require.Contains(t, p.snapshots, uint64(50))
+	require.Contains(t, p.snapshots, uint64(40))
+}
+
+func TestProgramExited(t *testing.T) {
+	dir := t.TempDir()
+	state := &mipsevm.State{PC: 0, NextPC: 4, Memory: mipsevm.NewMemory(), Exited: true, Step: 5}
+	prestate := filepath.Join(dir, "prestate.json")
+	require.NoError(t, writeJSON(prestate, state))
+	p, err := NewProver(testlog.Logger(t, log.LvlError), Config{Dir: dir, SnapshotFreq: 10}, prestate, nil, os.Stdout, os.Stderr)
+	require.NoError(t, err)
+	_, err = p.Proof(context.Background(), 7)
+	require.ErrorIs(t, err, ErrProgramExited)
+}
+
+func TestHandler(t *testing.T) {
+	p, prestate, _ := setupProver(t, 0)
+	handler := Handler(p)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/proof?step=12", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var proof Proof
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&proof))
+	expected := expectedProof(t, prestate, 12)
+	require.Equal(t, expected.Post, proof.Post)
+	require.Equal(t, expected.StepInput, proof.StepInput)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/proof?step=abc", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,62 @@
+package prover
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// ListenAndServe serves the proofs of p as JSON at /proof?step=<step>, until ctx is done.
+func ListenAndServe(ctx context.Context, p *Prover, hostname string, port int) error {
+	mux := http.NewServeMux()
+	mux.Handle("/proof", Handler(p))
+	server := &http.Server{
+		Addr:    net.JoinHostPort(hostname, strconv.Itoa(port)),
+		Handler: mux,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		_ = server.Shutdown(context.Background())
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// Handler returns the handler serving the proof of a step of p.
+// Proofs of steps after the program exited are answered with 404 Not Found.
+func Handler(p *Prover) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		step, err := strconv.ParseUint(r.URL.Query().Get("step"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid step", http.StatusBadRequest)
+			return
+		}
+		proof, err := p.Proof(r.Context(), step)
+		if errors.Is(err, ErrProgramExited) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(proof); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
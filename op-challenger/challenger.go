@@ -5,16 +5,56 @@ import (
 	"fmt"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/dryrun"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault"
+	"github.com/ethereum-optimism/optimism/op-challenger/game"
+	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
+	"github.com/ethereum-optimism/optimism/op-challenger/version"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
 
 // Main is the programmatic entry-point for running op-challenger
 func Main(ctx context.Context, logger log.Logger, cfg *config.Config) error {
-	service, err := fault.NewService(ctx, logger, cfg)
+	m := metrics.NewMetrics()
+	m.RecordInfo(version.Version)
+	if cfg.MetricsConfig.Enabled {
+		logger.Info("starting metrics server", "addr", cfg.MetricsConfig.ListenAddr, "port", cfg.MetricsConfig.ListenPort)
+		go func() {
+			if err := m.Serve(ctx, cfg.MetricsConfig.ListenAddr, cfg.MetricsConfig.ListenPort); err != nil {
+				logger.Error("error starting metrics server", "err", err)
+			}
+		}()
+	}
+
+	var actions *dryrun.Log
+	if cfg.DryRun {
+		logger.Warn("dry run, transactions are simulated and not sent")
+		actions = dryrun.NewLog()
+		if cfg.DryRunHttpPort > 0 {
+			logger.Info("starting dry run server", "addr", cfg.DryRunHttpAddr, "port", cfg.DryRunHttpPort)
+			go func() {
+				if err := dryrun.ListenAndServe(ctx, actions, cfg.DryRunHttpAddr, cfg.DryRunHttpPort); err != nil {
+					logger.Error("error starting dry run server", "err", err)
+				}
+			}()
+		}
+	}
+
+	if cfg.GameFactoryAddress != (common.Address{}) {
+		service, err := game.NewService(ctx, logger, cfg, m, actions)
+		if err != nil {
+			return fmt.Errorf("failed to create the game service: %w", err)
+		}
+		m.RecordUp()
+		return service.Run(ctx)
+	}
+
+	service, err := fault.NewService(ctx, logger, cfg, m, actions)
 	if err != nil {
 		return fmt.Errorf("failed to create the fault service: %w", err)
 	}
+	m.RecordUp()
 
 	return service.MonitorGame(ctx)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
@@ -15,10 +16,20 @@ import (
 var (
 	l1EthRpc                = "http://example.com:8545"
 	gameAddressValue        = "0xaa00000000000000000000000000000000000000"
+	gameFactoryAddressValue = "0xbb00000000000000000000000000000000000000"
+	datadir                 = "./challenger_data"
 	cannonBin               = "./bin/cannon"
+	cannonServer            = "./bin/op-program"
+	cannonNetwork           = "op-goerli"
 	cannonPreState          = "./pre.json"
 	cannonDatadir           = "./test_data"
 	cannonL2                = "http://example.com:9545"
+	rollupRpc               = "http://example.com:7545"
+	splitDepth              = "2"
+	zkWasmProver            = "./bin/zkwasm-prover"
+	zkWasmImage             = "./bin/op-program-client.wasm"
+	zkWasmDatadir           = "./zkwasm_data"
+	zkWasmVerifier          = "0xcc00000000000000000000000000000000000000"
 	alphabetTrace           = "abcdefghijz"
 	agreeWithProposedOutput = "true"
 	gameDepth               = "4"
@@ -88,7 +99,7 @@ func TestTraceType(t *testing.T) {
 
 func TestGameAddress(t *testing.T) {
 	t.Run("Required", func(t *testing.T) {
-		verifyArgsInvalid(t, "flag game-address is required", addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-address"))
+		verifyArgsInvalid(t, "flag game-address or game-factory-address is required", addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-address"))
 	})
 
 	t.Run("Valid", func(t *testing.T) {
@@ -102,6 +113,86 @@ func TestGameAddress(t *testing.T) {
 	})
 }
 
+func TestGameFactoryAddress(t *testing.T) {
+	factoryArgs := func(args ...string) []string {
+		return addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-address",
+			append([]string{"--game-factory-address=" + gameFactoryAddressValue, "--datadir=" + datadir}, args...)...)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, factoryArgs())
+		require.Equal(t, common.HexToAddress(gameFactoryAddressValue), cfg.GameFactoryAddress)
+		require.Equal(t, common.Address{}, cfg.GameAddress)
+		require.Equal(t, datadir, cfg.Datadir)
+	})
+
+	t.Run("NotWithGameAddress", func(t *testing.T) {
+		verifyArgsInvalid(t, "flag game-address and game-factory-address must not both be set",
+			addRequiredArgs(config.TraceTypeAlphabet, "--game-factory-address="+gameFactoryAddressValue, "--datadir="+datadir))
+	})
+
+	t.Run("DatadirRequired", func(t *testing.T) {
+		verifyArgsInvalid(t, "flag datadir is required",
+			addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-address", "--game-factory-address="+gameFactoryAddressValue))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		verifyArgsInvalid(t, "invalid address: foo", addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-address",
+			"--game-factory-address=foo", "--datadir="+datadir))
+	})
+
+	t.Run("StartBlockDefault", func(t *testing.T) {
+		cfg := configForArgs(t, factoryArgs())
+		require.Zero(t, cfg.GameFactoryStartBlock)
+	})
+
+	t.Run("StartBlock", func(t *testing.T) {
+		cfg := configForArgs(t, factoryArgs("--game-factory-start-block=1234"))
+		require.Equal(t, uint64(1234), cfg.GameFactoryStartBlock)
+	})
+
+	t.Run("MaxConcurrencyDefault", func(t *testing.T) {
+		cfg := configForArgs(t, factoryArgs())
+		require.Equal(t, config.DefaultMaxConcurrency, cfg.MaxConcurrency)
+	})
+
+	t.Run("MaxConcurrency", func(t *testing.T) {
+		cfg := configForArgs(t, factoryArgs("--max-concurrency=12"))
+		require.Equal(t, uint(12), cfg.MaxConcurrency)
+	})
+}
+
+func TestBudget(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet))
+		require.Zero(t, cfg.GameBudget)
+		require.Zero(t, cfg.TotalBudget)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--game-budget=1000", "--total-budget=5000"))
+		require.Equal(t, uint64(1000), cfg.GameBudget)
+		require.Equal(t, uint64(5000), cfg.TotalBudget)
+	})
+}
+
+func TestDryRun(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet))
+		require.False(t, cfg.DryRun)
+		require.Equal(t, config.DefaultDryRunHttpAddr, cfg.DryRunHttpAddr)
+		require.Equal(t, 0, cfg.DryRunHttpPort)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet,
+			"--dry-run", "--dry-run-http-addr=0.0.0.0", "--dry-run-http-port=8080"))
+		require.True(t, cfg.DryRun)
+		require.Equal(t, "0.0.0.0", cfg.DryRunHttpAddr)
+		require.Equal(t, 8080, cfg.DryRunHttpPort)
+	})
+}
+
 func TestTxManagerFlagsSupported(t *testing.T) {
 	// Not a comprehensive list of flags, just enough to sanity check the txmgr.CLIFlags were defined
 	cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--"+txmgr.NumConfirmationsFlagName, "7"))
@@ -110,7 +201,7 @@ func TestTxManagerFlagsSupported(t *testing.T) {
 
 func TestAgreeWithProposedOutput(t *testing.T) {
 	t.Run("MustBeProvided", func(t *testing.T) {
-		verifyArgsInvalid(t, "flag agree-with-proposed-output is required", addRequiredArgsExcept(config.TraceTypeAlphabet, "--agree-with-proposed-output"))
+		verifyArgsInvalid(t, "flag agree-with-proposed-output or rollup-rpc is required", addRequiredArgsExcept(config.TraceTypeAlphabet, "--agree-with-proposed-output"))
 	})
 	t.Run("Enabled", func(t *testing.T) {
 		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--agree-with-proposed-output"))
@@ -126,6 +217,26 @@ func TestAgreeWithProposedOutput(t *testing.T) {
 	})
 }
 
+func TestRollupRpc(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		url := "http://example.com:7545"
+		cfg := configForArgs(t, addRequiredArgsExcept(config.TraceTypeAlphabet, "--agree-with-proposed-output", "--rollup-rpc="+url))
+		require.Equal(t, url, cfg.RollupRpc)
+		require.False(t, cfg.AgreeWithProposedOutput)
+	})
+
+	t.Run("NotWithAgreeWithProposedOutput", func(t *testing.T) {
+		verifyArgsInvalid(t, "flag agree-with-proposed-output and rollup-rpc must not both be set",
+			addRequiredArgs(config.TraceTypeAlphabet, "--rollup-rpc=http://example.com:7545"))
+	})
+}
+
+func TestMetricsFlagsSupported(t *testing.T) {
+	cfg := configForArgs(t, addRequiredArgs(config.TraceTypeAlphabet, "--metrics.enabled", "--metrics.port=7301"))
+	require.True(t, cfg.MetricsConfig.Enabled)
+	require.Equal(t, 7301, cfg.MetricsConfig.ListenPort)
+}
+
 func TestGameDepth(t *testing.T) {
 	t.Run("Required", func(t *testing.T) {
 		verifyArgsInvalid(t, "flag game-depth is required", addRequiredArgsExcept(config.TraceTypeAlphabet, "--game-depth"))
@@ -198,6 +309,110 @@ func TestCannonL2(t *testing.T) {
 	})
 }
 
+func TestCannonServer(t *testing.T) {
+	t.Run("NotRequiredForAlphabetTrace", func(t *testing.T) {
+		configForArgs(t, addRequiredArgsExcept(config.TraceTypeAlphabet, "--cannon-server"))
+	})
+
+	t.Run("RequiredForCannonTrace", func(t *testing.T) {
+		verifyArgsInvalid(t, "flag cannon-server is required", addRequiredArgsExcept(config.TraceTypeCannon, "--cannon-server"))
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon))
+		require.Equal(t, cannonServer, cfg.CannonServer)
+	})
+}
+
+func TestCannonNetwork(t *testing.T) {
+	t.Run("NotRequiredForAlphabetTrace", func(t *testing.T) {
+		configForArgs(t, addRequiredArgsExcept(config.TraceTypeAlphabet, "--cannon-network"))
+	})
+
+	t.Run("RequiredForCannonTrace", func(t *testing.T) {
+		verifyArgsInvalid(t, "flag cannon-network or cannon-rollup-config and cannon-l2-genesis is required",
+			addRequiredArgsExcept(config.TraceTypeCannon, "--cannon-network"))
+	})
+
+	t.Run("NotRequiredWhenRollupAndGenesisSpecified", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgsExcept(config.TraceTypeCannon, "--cannon-network",
+			"--cannon-rollup-config=rollup.json", "--cannon-l2-genesis=genesis.json"))
+		require.Equal(t, "rollup.json", cfg.CannonRollupConfigPath)
+		require.Equal(t, "genesis.json", cfg.CannonL2GenesisPath)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon))
+		require.Equal(t, cannonNetwork, cfg.CannonNetwork)
+	})
+}
+
+func TestCannonTimeout(t *testing.T) {
+	t.Run("DefaultNoLimit", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon))
+		require.Zero(t, cfg.CannonTimeout)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon, "--cannon-timeout=90m"))
+		require.Equal(t, 90*time.Minute, cfg.CannonTimeout)
+	})
+}
+
+func TestOutputCannon(t *testing.T) {
+	t.Run("RequiresCannonFlags", func(t *testing.T) {
+		verifyArgsInvalid(t, "flag cannon-bin is required", addRequiredArgsExcept(config.TraceTypeOutputCannon, "--cannon-bin"))
+	})
+
+	t.Run("RequiresRollupRpc", func(t *testing.T) {
+		verifyArgsInvalid(t, "flag rollup-rpc is required",
+			addRequiredArgsExcept(config.TraceTypeOutputCannon, "--rollup-rpc", "--agree-with-proposed-output"))
+	})
+
+	t.Run("RequiresSplitDepth", func(t *testing.T) {
+		verifyArgsInvalid(t, "flag split-depth is required", addRequiredArgsExcept(config.TraceTypeOutputCannon, "--split-depth"))
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeOutputCannon))
+		require.Equal(t, rollupRpc, cfg.RollupRpc)
+		require.Equal(t, 2, cfg.SplitDepth)
+		require.Equal(t, cannonBin, cfg.CannonBin)
+	})
+
+	t.Run("NotRequiredForCannon", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeCannon))
+		require.Equal(t, 0, cfg.SplitDepth)
+	})
+}
+
+func TestZkWasm(t *testing.T) {
+	for _, name := range []string{"--cannon-server", "--cannon-l2", "--zkwasm-prover", "--zkwasm-image", "--zkwasm-datadir", "--zkwasm-verifier"} {
+		name := name
+		t.Run("Requires"+name, func(t *testing.T) {
+			verifyArgsInvalid(t, fmt.Sprintf("flag %s is required", name[2:]), addRequiredArgsExcept(config.TraceTypeZkWasm, name))
+		})
+	}
+
+	t.Run("DoesNotRequireCannonBin", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeZkWasm))
+		require.Empty(t, cfg.CannonBin)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs(config.TraceTypeZkWasm))
+		require.Equal(t, zkWasmProver, cfg.ZkWasmProver)
+		require.Equal(t, zkWasmImage, cfg.ZkWasmImage)
+		require.Equal(t, zkWasmDatadir, cfg.ZkWasmDatadir)
+		require.Equal(t, common.HexToAddress(zkWasmVerifier), cfg.ZkWasmVerifier)
+	})
+
+	t.Run("InvalidVerifier", func(t *testing.T) {
+		verifyArgsInvalid(t, "invalid address: foo",
+			addRequiredArgsExcept(config.TraceTypeZkWasm, "--zkwasm-verifier", "--zkwasm-verifier=foo"))
+	})
+}
+
 func verifyArgsInvalid(t *testing.T, messageContains string, cliArgs []string) {
 	_, _, err := runWithArgs(cliArgs)
 	require.ErrorContains(t, err, messageContains)
@@ -244,11 +459,26 @@ func requiredArgs(traceType config.TraceType) map[string]string {
 	switch traceType {
 	case config.TraceTypeAlphabet:
 		args["--alphabet"] = alphabetTrace
-	case config.TraceTypeCannon:
+	case config.TraceTypeCannon, config.TraceTypeOutputCannon:
 		args["--cannon-bin"] = cannonBin
+		args["--cannon-server"] = cannonServer
+		args["--cannon-network"] = cannonNetwork
 		args["--cannon-prestate"] = cannonPreState
 		args["--cannon-datadir"] = cannonDatadir
 		args["--cannon-l2"] = cannonL2
+	case config.TraceTypeZkWasm:
+		args["--cannon-server"] = cannonServer
+		args["--cannon-network"] = cannonNetwork
+		args["--cannon-l2"] = cannonL2
+		args["--zkwasm-prover"] = zkWasmProver
+		args["--zkwasm-image"] = zkWasmImage
+		args["--zkwasm-datadir"] = zkWasmDatadir
+		args["--zkwasm-verifier"] = zkWasmVerifier
+	}
+	if traceType == config.TraceTypeOutputCannon {
+		delete(args, "--agree-with-proposed-output")
+		args["--rollup-rpc"] = rollupRpc
+		args["--split-depth"] = splitDepth
 	}
 	return args
 }
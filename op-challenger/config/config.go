@@ -3,7 +3,9 @@ package config
 import (
 	"errors"
 	"fmt"
+	"time"
 
+	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -13,20 +15,43 @@ var (
 	ErrMissingCannonDatadir          = errors.New("missing cannon datadir")
 	ErrMissingCannonL2               = errors.New("missing cannon L2")
 	ErrMissingCannonBin              = errors.New("missing cannon bin")
+	ErrMissingCannonServer           = errors.New("missing cannon server")
+	ErrMissingCannonRollupConfig     = errors.New("missing cannon network or rollup config path")
+	ErrMissingCannonL2Genesis        = errors.New("missing cannon network or l2 genesis path")
+	ErrCannonNetworkAndRollupConfig  = errors.New("only specify one of network or rollup config path")
+	ErrCannonNetworkAndL2Genesis     = errors.New("only specify one of network or l2 genesis path")
 	ErrMissingCannonAbsolutePreState = errors.New("missing cannon absolute pre-state")
 	ErrMissingAlphabetTrace          = errors.New("missing alphabet trace")
 	ErrMissingL1EthRPC               = errors.New("missing l1 eth rpc url")
 	ErrMissingGameAddress            = errors.New("missing game address")
+	ErrGameAddressAndFactory         = errors.New("only specify one of game address or game factory address")
+	ErrMissingDatadir                = errors.New("missing datadir")
+	ErrMaxConcurrencyZero            = errors.New("max concurrency must not be 0")
+	ErrAgreeWithOutputAndRollupRpc   = errors.New("only specify one of agree with proposed output or rollup rpc")
+	ErrMissingRollupRpc              = errors.New("missing rollup rpc")
+	ErrInvalidSplitDepth             = errors.New("split depth must be above 0 and below the game depth")
+	ErrMissingZkWasmProver           = errors.New("missing zkwasm prover")
+	ErrMissingZkWasmImage            = errors.New("missing zkwasm image")
+	ErrMissingZkWasmDatadir          = errors.New("missing zkwasm datadir")
+	ErrMissingZkWasmVerifier         = errors.New("missing zkwasm verifier address")
 )
 
+// DefaultMaxConcurrency is the default number of games acted on at once when discovering games from a factory.
+const DefaultMaxConcurrency = uint(4)
+
+// DefaultDryRunHttpAddr is the default address to serve the planned actions of a dry run on.
+const DefaultDryRunHttpAddr = "127.0.0.1"
+
 type TraceType string
 
 const (
-	TraceTypeAlphabet TraceType = "alphabet"
-	TraceTypeCannon   TraceType = "cannon"
+	TraceTypeAlphabet     TraceType = "alphabet"
+	TraceTypeCannon       TraceType = "cannon"
+	TraceTypeOutputCannon TraceType = "output_cannon"
+	TraceTypeZkWasm       TraceType = "zkwasm"
 )
 
-var TraceTypes = []TraceType{TraceTypeAlphabet, TraceTypeCannon}
+var TraceTypes = []TraceType{TraceTypeAlphabet, TraceTypeCannon, TraceTypeOutputCannon, TraceTypeZkWasm}
 
 func (t TraceType) String() string {
 	return string(t)
@@ -56,21 +81,48 @@ func ValidTraceType(value TraceType) bool {
 type Config struct {
 	L1EthRpc                string         // L1 RPC Url
 	GameAddress             common.Address // Address of the fault game
+	GameFactoryAddress      common.Address // Address of the dispute game factory to discover and play all fault games from
+	MaxConcurrency          uint           // Maximum number of games to act on at once, when using a game factory
+	GameFactoryStartBlock   uint64         // First L1 block to search for created games on a fresh datadir, 0 for the L1 head at startup
+	Datadir                 string         // Directory to store the games already handled, the spend on each game and the claims of each game in
 	AgreeWithProposedOutput bool           // Temporary config if we agree or disagree with the posted output
+	RollupRpc               string         // Trusted rollup node RPC to check the root claim of each game against, instead of AgreeWithProposedOutput
 	GameDepth               int            // Depth of the game tree
 
+	GameBudget  uint64 // Maximum gwei to spend on the moves, steps and proofs of each game, 0 for no limit
+	TotalBudget uint64 // Maximum gwei to spend on the moves, steps and proofs of all games, 0 for no limit
+
+	DryRun         bool   // Simulate transactions with eth_call and record them as planned actions, instead of sending them
+	DryRunHttpAddr string // Address to serve the planned actions of a dry run on
+	DryRunHttpPort int    // Port to serve the planned actions of a dry run on, 0 to not serve them
+
 	TraceType TraceType // Type of trace
 
 	// Specific to the alphabet trace provider
 	AlphabetTrace string // String for the AlphabetTraceProvider
 
 	// Specific to the cannon trace provider
-	CannonBin              string // Path to the cannon executable to run when generating trace data
-	CannonAbsolutePreState string // File to load the absolute pre-state for Cannon traces from
-	CannonDatadir          string // Cannon Data Directory
-	CannonL2               string // L2 RPC Url
-
-	TxMgrConfig txmgr.CLIConfig
+	CannonBin              string        // Path to the cannon executable to run when generating trace data
+	CannonServer           string        // Path to the op-program executable that provides the pre-image oracle server
+	CannonAbsolutePreState string        // File to load the absolute pre-state for Cannon traces from
+	CannonDatadir          string        // Cannon Data Directory
+	CannonL2               string        // L2 RPC Url
+	CannonNetwork          string        // Predefined network of the op-program server
+	CannonRollupConfigPath string        // Rollup config of the op-program server, if not using a predefined network
+	CannonL2GenesisPath    string        // L2 genesis of the op-program server, if not using a predefined network
+	CannonTimeout          time.Duration // Maximum time to generate a single proof, 0 for no limit
+
+	// Specific to the output cannon trace provider
+	SplitDepth int // Depth of the game tree the claims switch from L2 output roots to cannon states at
+
+	// Specific to the zkwasm trace provider, which also runs the op-program configured by the cannon server options
+	ZkWasmProver   string         // Path to the prover executable generating the zkWasm proof of the op-program run
+	ZkWasmImage    string         // Path to the op-program client wasm image to prove
+	ZkWasmDatadir  string         // Directory to store the pre-image trace, prover inputs and proof in
+	ZkWasmVerifier common.Address // Address of the contract verifying zkWasm proofs of the game
+
+	TxMgrConfig   txmgr.CLIConfig
+	MetricsConfig opmetrics.CLIConfig
 }
 
 func NewConfig(
@@ -84,12 +136,17 @@ func NewConfig(
 		L1EthRpc:    l1EthRpc,
 		GameAddress: gameAddress,
 
+		MaxConcurrency: DefaultMaxConcurrency,
+
+		DryRunHttpAddr: DefaultDryRunHttpAddr,
+
 		AgreeWithProposedOutput: agreeWithProposedOutput,
 		GameDepth:               gameDepth,
 
 		TraceType: traceType,
 
-		TxMgrConfig: txmgr.NewCLIConfig(l1EthRpc),
+		TxMgrConfig:   txmgr.NewCLIConfig(l1EthRpc),
+		MetricsConfig: opmetrics.DefaultCLIConfig(),
 	}
 }
 
@@ -97,24 +154,63 @@ func (c Config) Check() error {
 	if c.L1EthRpc == "" {
 		return ErrMissingL1EthRPC
 	}
-	if c.GameAddress == (common.Address{}) {
+	if c.GameAddress == (common.Address{}) && c.GameFactoryAddress == (common.Address{}) {
 		return ErrMissingGameAddress
 	}
+	if c.GameAddress != (common.Address{}) && c.GameFactoryAddress != (common.Address{}) {
+		return ErrGameAddressAndFactory
+	}
+	if c.GameFactoryAddress != (common.Address{}) {
+		if c.Datadir == "" {
+			return ErrMissingDatadir
+		}
+		if c.MaxConcurrency == 0 {
+			return ErrMaxConcurrencyZero
+		}
+	}
+	if c.AgreeWithProposedOutput && c.RollupRpc != "" {
+		return ErrAgreeWithOutputAndRollupRpc
+	}
 	if c.TraceType == "" {
 		return ErrMissingTraceType
 	}
-	if c.TraceType == TraceTypeCannon {
+	if c.TraceType == TraceTypeCannon || c.TraceType == TraceTypeOutputCannon {
 		if c.CannonBin == "" {
 			return ErrMissingCannonBin
 		}
+		if err := c.checkServer(); err != nil {
+			return err
+		}
 		if c.CannonAbsolutePreState == "" {
 			return ErrMissingCannonAbsolutePreState
 		}
 		if c.CannonDatadir == "" {
 			return ErrMissingCannonDatadir
 		}
-		if c.CannonL2 == "" {
-			return ErrMissingCannonL2
+	}
+	if c.TraceType == TraceTypeZkWasm {
+		if err := c.checkServer(); err != nil {
+			return err
+		}
+		if c.ZkWasmProver == "" {
+			return ErrMissingZkWasmProver
+		}
+		if c.ZkWasmImage == "" {
+			return ErrMissingZkWasmImage
+		}
+		if c.ZkWasmDatadir == "" {
+			return ErrMissingZkWasmDatadir
+		}
+		if c.ZkWasmVerifier == (common.Address{}) {
+			return ErrMissingZkWasmVerifier
+		}
+	}
+	if c.TraceType == TraceTypeOutputCannon {
+		if c.RollupRpc == "" {
+			return ErrMissingRollupRpc
+		}
+		if c.SplitDepth <= 0 || c.SplitDepth >= c.GameDepth {
+			return ErrInvalidSplitDepth
 		}
 	}
 	if c.TraceType == TraceTypeAlphabet && c.AlphabetTrace == "" {
@@ -123,5 +219,34 @@ func (c Config) Check() error {
 	if err := c.TxMgrConfig.Check(); err != nil {
 		return err
 	}
+	if err := c.MetricsConfig.Check(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkServer checks the options of the op-program run by the cannon and zkwasm trace providers.
+func (c Config) checkServer() error {
+	if c.CannonServer == "" {
+		return ErrMissingCannonServer
+	}
+	if c.CannonNetwork == "" {
+		if c.CannonRollupConfigPath == "" {
+			return ErrMissingCannonRollupConfig
+		}
+		if c.CannonL2GenesisPath == "" {
+			return ErrMissingCannonL2Genesis
+		}
+	} else {
+		if c.CannonRollupConfigPath != "" {
+			return ErrCannonNetworkAndRollupConfig
+		}
+		if c.CannonL2GenesisPath != "" {
+			return ErrCannonNetworkAndL2Genesis
+		}
+	}
+	if c.CannonL2 == "" {
+		return ErrMissingCannonL2
+	}
 	return nil
 }
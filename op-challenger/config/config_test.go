@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
@@ -11,11 +12,21 @@ import (
 var (
 	validL1EthRpc              = "http://localhost:8545"
 	validGameAddress           = common.HexToAddress("0x7bdd3b028C4796eF0EAf07d11394d0d9d8c24139")
+	validGameFactoryAddress    = common.HexToAddress("0x9bcd3b028C4796eF0EAf07d11394d0d9d8c24139")
+	validDatadir               = "/tmp/challenger"
 	validAlphabetTrace         = "abcdefgh"
 	validCannonBin             = "./bin/cannon"
+	validCannonOpProgramBin    = "./bin/op-program"
+	validCannonNetwork         = "mainnet"
 	validCannonAbsolutPreState = "pre.json"
 	validCannonDatadir         = "/tmp/cannon"
 	validCannonL2              = "http://localhost:9545"
+	validRollupRpc             = "http://localhost:7545"
+	validSplitDepth            = 2
+	validZkWasmProver          = "./bin/zkwasm-prover"
+	validZkWasmImage           = "./bin/op-program-client.wasm"
+	validZkWasmDatadir         = "/tmp/zkwasm"
+	validZkWasmVerifier        = common.HexToAddress("0x8bcd3b028C4796eF0EAf07d11394d0d9d8c24139")
 	agreeWithProposedOutput    = true
 	gameDepth                  = 4
 )
@@ -25,11 +36,26 @@ func validConfig(traceType TraceType) Config {
 	switch traceType {
 	case TraceTypeAlphabet:
 		cfg.AlphabetTrace = validAlphabetTrace
-	case TraceTypeCannon:
+	case TraceTypeCannon, TraceTypeOutputCannon:
 		cfg.CannonBin = validCannonBin
+		cfg.CannonServer = validCannonOpProgramBin
+		cfg.CannonNetwork = validCannonNetwork
 		cfg.CannonAbsolutePreState = validCannonAbsolutPreState
 		cfg.CannonDatadir = validCannonDatadir
 		cfg.CannonL2 = validCannonL2
+	case TraceTypeZkWasm:
+		cfg.CannonServer = validCannonOpProgramBin
+		cfg.CannonNetwork = validCannonNetwork
+		cfg.CannonL2 = validCannonL2
+		cfg.ZkWasmProver = validZkWasmProver
+		cfg.ZkWasmImage = validZkWasmImage
+		cfg.ZkWasmDatadir = validZkWasmDatadir
+		cfg.ZkWasmVerifier = validZkWasmVerifier
+	}
+	if traceType == TraceTypeOutputCannon {
+		cfg.AgreeWithProposedOutput = false
+		cfg.RollupRpc = validRollupRpc
+		cfg.SplitDepth = validSplitDepth
 	}
 	return cfg
 }
@@ -65,6 +91,55 @@ func TestGameAddressRequired(t *testing.T) {
 	require.ErrorIs(t, config.Check(), ErrMissingGameAddress)
 }
 
+func TestGameFactoryAddress(t *testing.T) {
+	factoryConfig := func() Config {
+		config := validConfig(TraceTypeCannon)
+		config.GameAddress = common.Address{}
+		config.GameFactoryAddress = validGameFactoryAddress
+		config.Datadir = validDatadir
+		return config
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		require.NoError(t, factoryConfig().Check())
+	})
+
+	t.Run("NotWithGameAddress", func(t *testing.T) {
+		config := factoryConfig()
+		config.GameAddress = validGameAddress
+		require.ErrorIs(t, config.Check(), ErrGameAddressAndFactory)
+	})
+
+	t.Run("DatadirRequired", func(t *testing.T) {
+		config := factoryConfig()
+		config.Datadir = ""
+		require.ErrorIs(t, config.Check(), ErrMissingDatadir)
+	})
+
+	t.Run("MaxConcurrencyNotZero", func(t *testing.T) {
+		config := factoryConfig()
+		config.MaxConcurrency = 0
+		require.ErrorIs(t, config.Check(), ErrMaxConcurrencyZero)
+	})
+}
+
+func TestAgreeWithProposedOutputAndRollupRpc(t *testing.T) {
+	config := validConfig(TraceTypeCannon)
+	config.AgreeWithProposedOutput = true
+	config.RollupRpc = "http://localhost:7545"
+	require.ErrorIs(t, config.Check(), ErrAgreeWithOutputAndRollupRpc)
+
+	config.AgreeWithProposedOutput = false
+	require.NoError(t, config.Check())
+}
+
+func TestMetricsConfig(t *testing.T) {
+	config := validConfig(TraceTypeCannon)
+	config.MetricsConfig.Enabled = true
+	config.MetricsConfig.ListenPort = -1
+	require.ErrorContains(t, config.Check(), "invalid metrics port")
+}
+
 func TestAlphabetTraceRequired(t *testing.T) {
 	config := validConfig(TraceTypeAlphabet)
 	config.AlphabetTrace = ""
@@ -94,3 +169,115 @@ func TestCannonL2Required(t *testing.T) {
 	config.CannonL2 = ""
 	require.ErrorIs(t, config.Check(), ErrMissingCannonL2)
 }
+
+func TestCannonServerRequired(t *testing.T) {
+	config := validConfig(TraceTypeCannon)
+	config.CannonServer = ""
+	require.ErrorIs(t, config.Check(), ErrMissingCannonServer)
+}
+
+func TestCannonNetworkOrRollupConfigRequired(t *testing.T) {
+	cfg := validConfig(TraceTypeCannon)
+	cfg.CannonNetwork = ""
+	cfg.CannonRollupConfigPath = ""
+	cfg.CannonL2GenesisPath = "genesis.json"
+	require.ErrorIs(t, cfg.Check(), ErrMissingCannonRollupConfig)
+}
+
+func TestCannonNetworkOrL2GenesisRequired(t *testing.T) {
+	cfg := validConfig(TraceTypeCannon)
+	cfg.CannonNetwork = ""
+	cfg.CannonRollupConfigPath = "foo.json"
+	cfg.CannonL2GenesisPath = ""
+	require.ErrorIs(t, cfg.Check(), ErrMissingCannonL2Genesis)
+}
+
+func TestCannonCustomConfig(t *testing.T) {
+	cfg := validConfig(TraceTypeCannon)
+	cfg.CannonNetwork = ""
+	cfg.CannonRollupConfigPath = "rollup.json"
+	cfg.CannonL2GenesisPath = "genesis.json"
+	require.NoError(t, cfg.Check())
+}
+
+func TestMustNotSpecifyNetworkAndRollup(t *testing.T) {
+	cfg := validConfig(TraceTypeCannon)
+	cfg.CannonRollupConfigPath = "foo.json"
+	require.ErrorIs(t, cfg.Check(), ErrCannonNetworkAndRollupConfig)
+}
+
+func TestMustNotSpecifyNetworkAndL2Genesis(t *testing.T) {
+	cfg := validConfig(TraceTypeCannon)
+	cfg.CannonL2GenesisPath = "foo.json"
+	require.ErrorIs(t, cfg.Check(), ErrCannonNetworkAndL2Genesis)
+}
+
+func TestOutputCannonRequiresCannonConfig(t *testing.T) {
+	config := validConfig(TraceTypeOutputCannon)
+	config.CannonBin = ""
+	require.ErrorIs(t, config.Check(), ErrMissingCannonBin)
+}
+
+func TestOutputCannonRollupRpcRequired(t *testing.T) {
+	config := validConfig(TraceTypeOutputCannon)
+	config.RollupRpc = ""
+	require.ErrorIs(t, config.Check(), ErrMissingRollupRpc)
+}
+
+func TestOutputCannonSplitDepth(t *testing.T) {
+	for _, splitDepth := range []int{-1, 0, gameDepth, gameDepth + 1} {
+		splitDepth := splitDepth
+		t.Run(fmt.Sprintf("Invalid_%v", splitDepth), func(t *testing.T) {
+			config := validConfig(TraceTypeOutputCannon)
+			config.SplitDepth = splitDepth
+			require.ErrorIs(t, config.Check(), ErrInvalidSplitDepth)
+		})
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		config := validConfig(TraceTypeOutputCannon)
+		config.SplitDepth = gameDepth - 1
+		require.NoError(t, config.Check())
+	})
+}
+
+func TestZkWasmRequiresServerConfig(t *testing.T) {
+	config := validConfig(TraceTypeZkWasm)
+	config.CannonServer = ""
+	require.ErrorIs(t, config.Check(), ErrMissingCannonServer)
+
+	config = validConfig(TraceTypeZkWasm)
+	config.CannonL2 = ""
+	require.ErrorIs(t, config.Check(), ErrMissingCannonL2)
+}
+
+func TestZkWasmDoesNotRequireCannonBin(t *testing.T) {
+	config := validConfig(TraceTypeZkWasm)
+	require.Empty(t, config.CannonBin)
+	require.Empty(t, config.CannonAbsolutePreState)
+	require.NoError(t, config.Check())
+}
+
+func TestZkWasmProverRequired(t *testing.T) {
+	config := validConfig(TraceTypeZkWasm)
+	config.ZkWasmProver = ""
+	require.ErrorIs(t, config.Check(), ErrMissingZkWasmProver)
+}
+
+func TestZkWasmImageRequired(t *testing.T) {
+	config := validConfig(TraceTypeZkWasm)
+	config.ZkWasmImage = ""
+	require.ErrorIs(t, config.Check(), ErrMissingZkWasmImage)
+}
+
+func TestZkWasmDatadirRequired(t *testing.T) {
+	config := validConfig(TraceTypeZkWasm)
+	config.ZkWasmDatadir = ""
+	require.ErrorIs(t, config.Check(), ErrMissingZkWasmDatadir)
+}
+
+func TestZkWasmVerifierRequired(t *testing.T) {
+	config := validConfig(TraceTypeZkWasm)
+	config.ZkWasmVerifier = common.Address{}
+	require.ErrorIs(t, config.Check(), ErrMissingZkWasmVerifier)
+}
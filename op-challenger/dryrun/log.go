@@ -0,0 +1,76 @@
+package dryrun
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Action is a transaction the challenger would have sent, and the result of simulating it.
+type Action struct {
+	Game    common.Address `json:"game"`
+	To      common.Address `json:"to"`
+	Method  string         `json:"method"`
+	Data    hexutil.Bytes  `json:"data"`
+	Reverts bool           `json:"reverts"`
+	Error   string         `json:"error,omitempty"`
+	// Planned is the time the action was first planned, Simulated the time it was last simulated
+	Planned   time.Time `json:"planned"`
+	Simulated time.Time `json:"simulated"`
+}
+
+type actionKey struct {
+	game common.Address
+	to   common.Address
+	data string
+}
+
+// Log records the actions planned in dry-run mode, shared between the games played.
+// The agent plans the same actions again on every poll until the game changes, so actions are de-duplicated.
+type Log struct {
+	lock    sync.Mutex
+	actions []Action
+	index   map[actionKey]int
+	now     func() time.Time
+}
+
+func NewLog() *Log {
+	return &Log{
+		index: make(map[actionKey]int),
+		now:   time.Now,
+	}
+}
+
+// Record records the action, updating the simulation result if it was planned before.
+// Returns true if the action is new.
+func (l *Log) Record(action Action) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	action.Simulated = now
+	key := actionKey{action.Game, action.To, string(action.Data)}
+	if i, ok := l.index[key]; ok {
+		action.Planned = l.actions[i].Planned
+		l.actions[i] = action
+		return false
+	}
+	action.Planned = now
+	l.index[key] = len(l.actions)
+	l.actions = append(l.actions, action)
+	return true
+}
+
+// Actions returns the actions planned for the game, in the order first planned, or for all games if game is the zero address.
+func (l *Log) Actions(game common.Address) []Action {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	actions := make([]Action, 0, len(l.actions))
+	for _, action := range l.actions {
+		if game == (common.Address{}) || action.Game == game {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
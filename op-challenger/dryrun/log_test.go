@@ -0,0 +1,72 @@
+package dryrun
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	gameA = common.Address{0xaa}
+	gameB = common.Address{0xbb}
+)
+
+func newTestLog() (*Log, *time.Time) {
+	now := time.Unix(1000, 0).UTC()
+	l := NewLog()
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestRecord(t *testing.T) {
+	l, now := newTestLog()
+	move := Action{Game: gameA, To: gameA, Method: "attack", Data: []byte{0x01}}
+	require.True(t, l.Record(move))
+	require.True(t, l.Record(Action{Game: gameB, To: gameB, Method: "attack", Data: []byte{0x01}}))
+
+	*now = now.Add(time.Minute)
+	move.Reverts = true
+	move.Error = "execution reverted"
+	require.False(t, l.Record(move), "should de-duplicate planned actions")
+
+	actions := l.Actions(gameA)
+	require.Len(t, actions, 1)
+	require.True(t, actions[0].Reverts, "should update the simulation result")
+	require.Equal(t, time.Unix(1000, 0).UTC(), actions[0].Planned)
+	require.Equal(t, *now, actions[0].Simulated)
+
+	require.Len(t, l.Actions(common.Address{}), 2)
+}
+
+func TestHandler(t *testing.T) {
+	l, _ := newTestLog()
+	l.Record(Action{Game: gameA, To: gameA, Method: "attack", Data: []byte{0x01}})
+	l.Record(Action{Game: gameB, To: gameB, Method: "step", Data: []byte{0x02}, Reverts: true, Error: "boom"})
+	handler := Handler(l)
+
+	get := func(url string) (int, []Action) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		var actions []Action
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &actions))
+		}
+		return rec.Code, actions
+	}
+
+	code, actions := get("/actions")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, l.Actions(common.Address{}), actions)
+
+	code, actions = get("/actions?game=" + gameB.Hex())
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, l.Actions(gameB), actions)
+
+	code, _ = get("/actions?game=foo")
+	require.Equal(t, http.StatusBadRequest, code)
+}
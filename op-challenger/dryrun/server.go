@@ -0,0 +1,45 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum-optimism/optimism/op-service/httputil"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ListenAndServe serves the planned actions as JSON at /actions, optionally filtered by ?game=<address>.
+func ListenAndServe(ctx context.Context, log *Log, hostname string, port int) error {
+	mux := http.NewServeMux()
+	mux.Handle("/actions", Handler(log))
+	server := &http.Server{
+		Addr:    net.JoinHostPort(hostname, strconv.Itoa(port)),
+		Handler: mux,
+	}
+	return httputil.ListenAndServeContext(ctx, server)
+}
+
+// Handler returns the handler serving the planned actions of log.
+func Handler(log *Log) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var game common.Address
+		if value := r.URL.Query().Get("game"); value != "" {
+			if !common.IsHexAddress(value) {
+				http.Error(w, "invalid game address", http.StatusBadRequest)
+				return
+			}
+			game = common.HexToAddress(value)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(log.Actions(game)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
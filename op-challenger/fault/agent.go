@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/solver"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-service/clock"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -24,22 +27,31 @@ type Agent struct {
 	loader                  Loader
 	responder               Responder
 	maxDepth                int
+	gameDuration            time.Duration
 	agreeWithProposedOutput bool
+	clock                   clock.Clock
 	log                     log.Logger
 }
 
-func NewAgent(loader Loader, maxDepth int, trace types.TraceProvider, responder Responder, agreeWithProposedOutput bool, log log.Logger) *Agent {
+// NewAgent creates an [Agent] for a game of gameDuration, the total time of the chess clocks of both sides.
+func NewAgent(loader Loader, maxDepth int, gameDuration time.Duration, traces types.TraceSelector, responder Responder, agreeWithProposedOutput bool, log log.Logger) *Agent {
 	return &Agent{
-		solver:                  solver.NewSolver(maxDepth, trace),
+		solver:                  solver.NewSolverForTraces(maxDepth, traces),
 		loader:                  loader,
 		responder:               responder,
 		maxDepth:                maxDepth,
+		gameDuration:            gameDuration,
 		agreeWithProposedOutput: agreeWithProposedOutput,
+		clock:                   clock.SystemClock,
 		log:                     log,
 	}
 }
 
 // Act iterates the game & performs all of the next actions.
+// Claims are countered in order of the time left to counter them, the most urgent first.
+// Moves are skipped once their clock has expired, as the game would reject them,
+// and moves and steps are skipped below a claim already settled, as they cannot change the outcome.
+// Acting stops once the budget of the game has been spent.
 func (a *Agent) Act(ctx context.Context) error {
 	if a.tryResolve(ctx) {
 		return nil
@@ -48,21 +60,101 @@ func (a *Agent) Act(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("create game from contracts: %w", err)
 	}
-	// Create counter claims
-	for _, claim := range game.Claims() {
-		if err := a.move(ctx, claim, game); err != nil && !errors.Is(err, types.ErrGameDepthReached) {
-			log.Error("Failed to move", "err", err)
+	now := uint64(a.clock.Now().Unix())
+	settled := a.settled(game, now)
+	for _, claim := range a.schedule(game) {
+		if ancestor, ok := settledAncestor(claim, game, settled); ok {
+			a.log.Debug("Claim already settled, skipping", "depth", claim.Depth(), "index_at_depth", claim.IndexAtDepth(), "value", claim.Value,
+				"settled_depth", ancestor.Depth(), "settled_index_at_depth", ancestor.IndexAtDepth())
+			continue
 		}
-	}
-	// Step on all leaf claims
-	for _, claim := range game.Claims() {
-		if err := a.step(ctx, claim, game); err != nil {
-			log.Error("Failed to step", "err", err)
+		deadline := a.deadline(claim, game)
+		if deadline > now {
+			// Create counter claims
+			if err := a.move(ctx, claim, game); errors.Is(err, ErrBudgetExceeded) {
+				a.log.Warn("Stopped acting on game", "err", err)
+				return nil
+			} else if err != nil && !errors.Is(err, types.ErrGameDepthReached) {
+				a.log.Error("Failed to move", "err", err)
+			}
+		} else if claim.Depth() != a.maxDepth {
+			a.log.Debug("Clock expired, skipping move", "depth", claim.Depth(), "index_at_depth", claim.IndexAtDepth(), "value", claim.Value)
+		}
+		// Step on leaf claims, which is not bound by the clock
+		if err := a.step(ctx, claim, game); errors.Is(err, ErrBudgetExceeded) {
+			a.log.Warn("Stopped acting on game", "err", err)
+			return nil
+		} else if err != nil {
+			a.log.Error("Failed to step", "err", err)
 		}
 	}
 	return nil
 }
 
+// schedule returns the claims of game in order of their deadline, the earliest first.
+func (a *Agent) schedule(game types.Game) []types.Claim {
+	claims := game.Claims()
+	deadlines := make(map[int]uint64, len(claims))
+	for _, claim := range claims {
+		deadlines[claim.ContractIndex] = a.deadline(claim, game)
+	}
+	sort.SliceStable(claims, func(i, j int) bool {
+		return deadlines[claims[i].ContractIndex] < deadlines[claims[j].ContractIndex]
+	})
+	return claims
+}
+
+// deadline returns the time, in seconds, until which a move against claim can be made.
+// The clock of the move accumulates the time the side of the move has spent before, on the clock of the parent of claim,
+// and the time since claim was made. The game rejects the move once it exceeds half the game duration.
+func (a *Agent) deadline(claim types.Claim, game types.Game) uint64 {
+	var spent uint64
+	if parent, err := game.GetParent(claim); err == nil {
+		spent = parent.Clock.Duration
+	}
+	allowed := uint64(a.gameDuration/time.Second) / 2
+	if spent >= allowed {
+		return 0
+	}
+	return claim.Clock.Timestamp + allowed - spent
+}
+
+// settled returns the contract indices of the claims of game which are countered whatever happens next.
+// Such a claim has a child which is not countered, and can no longer be as the clock to counter it has expired.
+// Leaf claims can be stepped on at any time, so never settle a claim.
+// The game resolves a claim as countered if any of its children is not, so no move below a settled claim can change the outcome.
+func (a *Agent) settled(game types.Game, now uint64) map[int]bool {
+	claims := game.Claims()
+	hasChildren := make(map[int]bool, len(claims))
+	for _, claim := range claims {
+		if !claim.IsRoot() {
+			hasChildren[claim.ParentContractIndex] = true
+		}
+	}
+	settled := make(map[int]bool)
+	for _, claim := range claims {
+		if claim.IsRoot() || claim.Depth() == a.maxDepth || hasChildren[claim.ContractIndex] || a.deadline(claim, game) > now {
+			continue
+		}
+		settled[claim.ParentContractIndex] = true
+	}
+	return settled
+}
+
+// settledAncestor returns the closest claim to claim, including claim itself, that is in settled.
+func settledAncestor(claim types.Claim, game types.Game, settled map[int]bool) (types.Claim, bool) {
+	for {
+		if settled[claim.ContractIndex] {
+			return claim, true
+		}
+		parent, err := game.GetParent(claim)
+		if err != nil {
+			return types.Claim{}, false
+		}
+		claim = parent
+	}
+}
+
 // tryResolve resolves the game if it is in a terminal state
 // and returns true if the game resolves successfully.
 func (a *Agent) tryResolve(ctx context.Context) bool {
@@ -95,7 +187,7 @@ func (a *Agent) newGameFromContracts(ctx context.Context) (types.Game, error) {
 
 // move determines & executes the next move given a claim
 func (a *Agent) move(ctx context.Context, claim types.Claim, game types.Game) error {
-	nextMove, err := a.solver.NextMove(claim, game.AgreeWithClaimLevel(claim))
+	nextMove, err := a.solver.NextMove(game, claim, game.AgreeWithClaimLevel(claim))
 	if err != nil {
 		return fmt.Errorf("execute next move: %w", err)
 	}
@@ -133,7 +225,7 @@ func (a *Agent) step(ctx context.Context, claim types.Claim, game types.Game) er
 	}
 
 	a.log.Info("Attempting step", "claim_depth", claim.Depth(), "maxDepth", a.maxDepth)
-	step, err := a.solver.AttemptStep(claim, agreeWithClaimLevel)
+	step, err := a.solver.AttemptStep(game, claim, agreeWithClaimLevel)
 	if err != nil {
 		return fmt.Errorf("attempt step: %w", err)
 	}
@@ -0,0 +1,168 @@
+package fault
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/alphabet"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-service/clock"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testGameDepth    = 3
+	testGameDuration = 1000 * time.Second
+	testGameStart    = uint64(1_690_000_000)
+)
+
+type stubResponder struct {
+	moves []types.Claim
+	err   error
+}
+
+func (s *stubResponder) CanResolve(_ context.Context) bool {
+	return false
+}
+
+func (s *stubResponder) Resolve(_ context.Context) error {
+	panic("agent should not resolve")
+}
+
+func (s *stubResponder) Respond(_ context.Context, response types.Claim) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.moves = append(s.moves, response)
+	return nil
+}
+
+func (s *stubResponder) Step(_ context.Context, _ types.StepCallData) error {
+	return s.err
+}
+
+// setupAgent creates an agent disagreeing with the root claim of a game where the
+// root claim is countered by a correct claim, which is countered by two invalid claims
+// made at the given times, after the correct claim spent 100s on its clock.
+func setupAgent(t *testing.T, leftMadeAt uint64, rightMadeAt uint64) (*Agent, *stubResponder, *clock.DeterministicClock) {
+	trace := alphabet.NewAlphabetProvider("abcdefgh", testGameDepth)
+	correct := func(gindex uint64) common.Hash {
+		pos := types.NewPositionFromGIndex(gindex)
+		value, err := trace.Get(pos.TraceIndex(testGameDepth))
+		require.NoError(t, err)
+		return value
+	}
+	root := types.Claim{
+		ClaimData: types.ClaimData{Value: common.Hash{0xaa}, Position: types.NewPositionFromGIndex(1)},
+		Clock:     types.Clock{Timestamp: testGameStart},
+	}
+	agreed := types.Claim{
+		ClaimData:     types.ClaimData{Value: correct(2), Position: types.NewPositionFromGIndex(2)},
+		Parent:        root.ClaimData,
+		Clock:         types.Clock{Duration: 100, Timestamp: testGameStart + 100},
+		ContractIndex: 1,
+	}
+	left := types.Claim{
+		ClaimData:           types.ClaimData{Value: common.Hash{0xbb}, Position: types.NewPositionFromGIndex(4)},
+		Parent:              agreed.ClaimData,
+		Clock:               types.Clock{Duration: leftMadeAt - testGameStart - 100, Timestamp: leftMadeAt},
+		ContractIndex:       2,
+		ParentContractIndex: 1,
+	}
+	right := types.Claim{
+		ClaimData:           types.ClaimData{Value: common.Hash{0xcc}, Position: types.NewPositionFromGIndex(5)},
+		Parent:              agreed.ClaimData,
+		Clock:               types.Clock{Duration: rightMadeAt - testGameStart - 100, Timestamp: rightMadeAt},
+		ContractIndex:       3,
+		ParentContractIndex: 1,
+	}
+	loader := &stubClaimLoader{claims: []types.Claim{root, agreed, left, right}}
+	responder := &stubResponder{}
+	agent := NewAgent(loader, testGameDepth, testGameDuration, types.NewSingleTrace(trace, testGameDepth), responder, true, testlog.Logger(t, log.LvlError))
+	cl := clock.NewDeterministicClock(time.Unix(int64(testGameStart+200), 0))
+	agent.clock = cl
+	return agent, responder, cl
+}
+
+func TestAgent_MovesMostUrgentFirst(t *testing.T) {
+	// The right claim was made first, so has less time left to counter it
+	agent, responder, _ := setupAgent(t, testGameStart+150, testGameStart+120)
+	require.NoError(t, agent.Act(context.Background()))
+	require.Len(t, responder.moves, 2)
+	require.Equal(t, common.Hash{0xcc}, responder.moves[0].Parent.Value)
+	require.Equal(t, common.Hash{0xbb}, responder.moves[1].Parent.Value)
+}
+
+func TestAgent_SkipsExpiredMoves(t *testing.T) {
+	agent, responder, cl := setupAgent(t, testGameStart+150, testGameStart+120)
+	// Counter the right claim with a leaf claim, which can be stepped on at any time,
+	// so the right claim does not settle the correct claim once its clock expires.
+	loader := agent.loader.(*stubClaimLoader)
+	right := loader.claims[3]
+	loader.claims = append(loader.claims, types.Claim{
+		ClaimData:           types.ClaimData{Value: common.Hash{0xdd}, Position: right.Attack()},
+		Parent:              right.ClaimData,
+		Clock:               types.Clock{Duration: 100, Timestamp: testGameStart + 130},
+		ContractIndex:       4,
+		ParentContractIndex: 3,
+	})
+	// The clock of the challenger spent 100s before the claims were made,
+	// so moves against them expire 400s after they were made.
+	cl.AdvanceTime(time.Duration(testGameStart+520-uint64(cl.Now().Unix())) * time.Second)
+	require.NoError(t, agent.Act(context.Background()))
+	require.Len(t, responder.moves, 1)
+	require.Equal(t, common.Hash{0xbb}, responder.moves[0].Parent.Value)
+}
+
+func TestAgent_StopsWhenBudgetExceeded(t *testing.T) {
+	agent, responder, _ := setupAgent(t, testGameStart+150, testGameStart+120)
+	responder.err = ErrBudgetExceeded
+	require.NoError(t, agent.Act(context.Background()))
+	require.Empty(t, responder.moves)
+}
+
+// setupSettledAgent creates an agent disagreeing with the root claim of a game where the root claim is countered
+// by a correct claim made 100s into the game, and by an invalid claim made 10s into the game,
+// which is countered by an invalid claim made 150s into the game.
+func setupSettledAgent(t *testing.T) (*Agent, *stubResponder, *clock.DeterministicClock) {
+	agent, responder, cl := setupAgent(t, testGameStart+150, testGameStart+120)
+	loader := agent.loader.(*stubClaimLoader)
+	root, agreed := loader.claims[0], loader.claims[1]
+	invalid := types.Claim{
+		ClaimData:     types.ClaimData{Value: common.Hash{0xdd}, Position: agreed.Position},
+		Parent:        root.ClaimData,
+		Clock:         types.Clock{Duration: 10, Timestamp: testGameStart + 10},
+		ContractIndex: 2,
+	}
+	counter := types.Claim{
+		ClaimData:           types.ClaimData{Value: common.Hash{0xee}, Position: types.NewPositionFromGIndex(4)},
+		Parent:              invalid.ClaimData,
+		Clock:               types.Clock{Duration: 140, Timestamp: testGameStart + 150},
+		ContractIndex:       3,
+		ParentContractIndex: 2,
+	}
+	loader.claims = []types.Claim{root, agreed, invalid, counter}
+	return agent, responder, cl
+}
+
+func TestAgent_MovesBelowUnsettledClaims(t *testing.T) {
+	agent, responder, cl := setupSettledAgent(t)
+	// The correct claim can still be countered until 600s into the game
+	cl.AdvanceTime(time.Duration(testGameStart+590-uint64(cl.Now().Unix())) * time.Second)
+	require.NoError(t, agent.Act(context.Background()))
+	require.Len(t, responder.moves, 1)
+	require.Equal(t, common.Hash{0xee}, responder.moves[0].Parent.Value)
+}
+
+func TestAgent_SkipsMovesBelowSettledClaims(t *testing.T) {
+	agent, responder, cl := setupSettledAgent(t)
+	// The correct claim can no longer be countered, so the root claim is countered whatever happens below it,
+	// though the counter to the invalid claim can still be countered.
+	cl.AdvanceTime(time.Duration(testGameStart+610-uint64(cl.Now().Unix())) * time.Second)
+	require.NoError(t, agent.Act(context.Background()))
+	require.Empty(t, responder.moves)
+}
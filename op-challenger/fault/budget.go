@@ -0,0 +1,149 @@
+package fault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const budgetFile = "budget.json"
+
+// ErrBudgetExceeded is returned when the spend on a game, or on all games, has reached its budget.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Budget tracks the spend on the transactions sent for each game, in wei,
+// and bounds it per game and across all games.
+// It is shared by the players of all games, so is safe for concurrent use.
+// The spend is kept in memory, unless the budget is opened from a directory to persist it across restarts.
+type Budget struct {
+	mu sync.Mutex
+	// perGame and total are the budgets of each game and of all games, nil for no limit
+	perGame *big.Int
+	total   *big.Int
+
+	spent      map[common.Address]*big.Int
+	totalSpent *big.Int
+	// path is the file the spend on each game is persisted to, empty to keep it in memory only
+	path string
+}
+
+// NewBudget creates a [Budget] of perGame wei for each game and total wei for all games.
+// A nil or zero budget is not limited.
+func NewBudget(perGame *big.Int, total *big.Int) *Budget {
+	return &Budget{
+		perGame:    limit(perGame),
+		total:      limit(total),
+		spent:      make(map[common.Address]*big.Int),
+		totalSpent: new(big.Int),
+	}
+}
+
+// NewBudgetFromConfig creates the [Budget] of cfg.GameBudget and cfg.TotalBudget, which are in gwei.
+func NewBudgetFromConfig(cfg *config.Config) *Budget {
+	return NewBudget(gweiToWei(cfg.GameBudget), gweiToWei(cfg.TotalBudget))
+}
+
+// OpenBudgetFromConfig creates the [Budget] of cfg like [NewBudgetFromConfig],
+// persisting the spend on each game in dir and loading the spend recorded there before.
+func OpenBudgetFromConfig(cfg *config.Config, dir string) (*Budget, error) {
+	b := NewBudgetFromConfig(cfg)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create datadir %v: %w", dir, err)
+	}
+	b.path = filepath.Join(dir, budgetFile)
+	data, err := os.ReadFile(b.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read budget spend %v: %w", b.path, err)
+	}
+	if err := json.Unmarshal(data, &b.spent); err != nil {
+		return nil, fmt.Errorf("failed to parse budget spend %v: %w", b.path, err)
+	}
+	if b.spent == nil {
+		b.spent = make(map[common.Address]*big.Int)
+	}
+	for _, spent := range b.spent {
+		b.totalSpent.Add(b.totalSpent, spent)
+	}
+	return b, nil
+}
+
+func gweiToWei(gwei uint64) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(gwei), big.NewInt(params.GWei))
+}
+
+func limit(budget *big.Int) *big.Int {
+	if budget == nil || budget.Sign() == 0 {
+		return nil
+	}
+	return new(big.Int).Set(budget)
+}
+
+// Check returns [ErrBudgetExceeded] if the spend on game, or on all games, has reached its budget.
+// A transaction is only sent while the spend is below the budget, so the last one may overshoot it.
+func (b *Budget) Check(game common.Address) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.perGame != nil {
+		if spent := b.spentLocked(game); spent.Cmp(b.perGame) >= 0 {
+			return fmt.Errorf("%w: spent %v of %v wei on game %v", ErrBudgetExceeded, spent, b.perGame, game)
+		}
+	}
+	if b.total != nil && b.totalSpent.Cmp(b.total) >= 0 {
+		return fmt.Errorf("%w: spent %v of %v wei on all games", ErrBudgetExceeded, b.totalSpent, b.total)
+	}
+	return nil
+}
+
+// Record adds cost wei to the spend on game.
+// The spend is recorded even if it fails to be persisted, so it still bounds the games until a restart.
+func (b *Budget) Record(game common.Address, cost *big.Int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spent[game] = new(big.Int).Add(b.spentLocked(game), cost)
+	b.totalSpent.Add(b.totalSpent, cost)
+	return b.save()
+}
+
+// Spent returns the spend on game and on all games, in wei.
+func (b *Budget) Spent(game common.Address) (*big.Int, *big.Int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return new(big.Int).Set(b.spentLocked(game)), new(big.Int).Set(b.totalSpent)
+}
+
+func (b *Budget) spentLocked(game common.Address) *big.Int {
+	if spent, ok := b.spent[game]; ok {
+		return spent
+	}
+	return new(big.Int)
+}
+
+// save writes the spend to a temporary file first, so an interrupted write can't corrupt the spend.
+// The caller must hold b.mu.
+func (b *Budget) save() error {
+	if b.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(b.spent)
+	if err != nil {
+		return err
+	}
+	tmp := b.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write budget spend: %w", err)
+	}
+	if err := os.Rename(tmp, b.path); err != nil {
+		return fmt.Errorf("failed to replace budget spend: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package fault
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget(t *testing.T) {
+	gameA := common.Address{0xaa}
+	gameB := common.Address{0xbb}
+
+	t.Run("Unlimited", func(t *testing.T) {
+		budget := NewBudget(nil, big.NewInt(0))
+		require.NoError(t, budget.Record(gameA, big.NewInt(1_000_000)))
+		require.NoError(t, budget.Check(gameA))
+	})
+
+	t.Run("PerGame", func(t *testing.T) {
+		budget := NewBudget(big.NewInt(100), nil)
+		require.NoError(t, budget.Record(gameA, big.NewInt(60)))
+		require.NoError(t, budget.Check(gameA))
+		require.NoError(t, budget.Record(gameA, big.NewInt(60)))
+		require.ErrorIs(t, budget.Check(gameA), ErrBudgetExceeded)
+		require.NoError(t, budget.Check(gameB))
+
+		spent, total := budget.Spent(gameA)
+		require.Equal(t, big.NewInt(120), spent)
+		require.Equal(t, big.NewInt(120), total)
+	})
+
+	t.Run("Total", func(t *testing.T) {
+		budget := NewBudget(big.NewInt(100), big.NewInt(150))
+		require.NoError(t, budget.Record(gameA, big.NewInt(80)))
+		require.NoError(t, budget.Record(gameB, big.NewInt(70)))
+		require.ErrorIs(t, budget.Check(gameA), ErrBudgetExceeded)
+		require.ErrorIs(t, budget.Check(common.Address{0xcc}), ErrBudgetExceeded)
+	})
+
+	t.Run("PersistAcrossRestart", func(t *testing.T) {
+		dir := t.TempDir()
+		cfg := &config.Config{GameBudget: 100, TotalBudget: 150}
+		budget, err := OpenBudgetFromConfig(cfg, dir)
+		require.NoError(t, err)
+		require.NoError(t, budget.Record(gameA, gwei(80)))
+		require.NoError(t, budget.Record(gameB, gwei(30)))
+		require.NoError(t, budget.Record(gameA, gwei(20)))
+
+		reopened, err := OpenBudgetFromConfig(cfg, dir)
+		require.NoError(t, err)
+		spent, total := reopened.Spent(gameA)
+		require.Equal(t, gwei(100), spent)
+		require.Equal(t, gwei(130), total)
+		require.ErrorIs(t, reopened.Check(gameA), ErrBudgetExceeded)
+		require.NoError(t, reopened.Check(gameB))
+		require.NoError(t, reopened.Record(gameB, gwei(20)))
+		require.ErrorIs(t, reopened.Check(common.Address{0xcc}), ErrBudgetExceeded)
+	})
+
+	t.Run("OpenEmpty", func(t *testing.T) {
+		budget, err := OpenBudgetFromConfig(&config.Config{}, filepath.Join(t.TempDir(), "new"))
+		require.NoError(t, err)
+		spent, total := budget.Spent(gameA)
+		require.Zero(t, spent.Sign())
+		require.Zero(t, total.Sign())
+	})
+}
+
+func gwei(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.GWei))
+}
@@ -0,0 +1,215 @@
+package fault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// HeaderSource provides L1 block headers by number, nil for the latest block.
+type HeaderSource interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+}
+
+// cachedClaim is the claim data as stored in the contract.
+type cachedClaim struct {
+	ParentIndex uint32      `json:"parentIndex"`
+	Countered   bool        `json:"countered"`
+	Claim       common.Hash `json:"claim"`
+	Position    uint64      `json:"position"`
+	Clock       types.Clock `json:"clock"`
+}
+
+// claimCache is the claim data of a game as of an L1 block.
+type claimCache struct {
+	BlockNumber uint64        `json:"blockNumber"`
+	BlockHash   common.Hash   `json:"blockHash"`
+	Claims      []cachedClaim `json:"claims"`
+}
+
+// CachingLoader is a [Loader] that caches the claims of the game and only fetches the new claims on each load.
+// Claims are only appended to the game, but an existing claim is countered when a claim is added against it,
+// or by a step against it at the max game depth. The former is tracked from the new claims,
+// the latter by refetching the uncountered claims at the max game depth.
+// The cache is dropped if the L1 block it was loaded at is reorged out.
+type CachingLoader struct {
+	log          log.Logger
+	claimFetcher ClaimFetcher
+	headers      HeaderSource
+	maxDepth     int
+	// path is the file the cache is persisted to, or empty to only cache in memory
+	path  string
+	cache claimCache
+}
+
+// NewCachingLoader creates a new [CachingLoader], resuming from the cache persisted at path if it exists.
+func NewCachingLoader(logger log.Logger, claimFetcher ClaimFetcher, headers HeaderSource, maxDepth int, path string) *CachingLoader {
+	l := &CachingLoader{
+		log:          logger,
+		claimFetcher: claimFetcher,
+		headers:      headers,
+		maxDepth:     maxDepth,
+		path:         path,
+	}
+	if path != "" {
+		if err := l.loadCache(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Ignoring invalid claim cache", "path", path, "err", err)
+			l.cache = claimCache{}
+		}
+	}
+	return l
+}
+
+// FetchClaims fetches all claims from the fault dispute game, as of the latest L1 block.
+func (l *CachingLoader) FetchClaims(ctx context.Context) ([]types.Claim, error) {
+	head, err := l.headers.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch L1 head: %w", err)
+	}
+	if err := l.checkReorg(ctx, head); err != nil {
+		return nil, err
+	}
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: head.Number}
+	claimCount, err := l.claimFetcher.ClaimDataLen(opts)
+	if err != nil {
+		return nil, err
+	}
+	if claimCount.Uint64() < uint64(len(l.cache.Claims)) {
+		l.log.Warn("Game has fewer claims than cached, reloading all claims", "cached", len(l.cache.Claims), "count", claimCount)
+		l.cache = claimCache{}
+	}
+
+	claims := l.cache.Claims
+	for i, claim := range claims {
+		pos := types.NewPositionFromGIndex(claim.Position)
+		if claim.Countered || pos.Depth() != l.maxDepth {
+			continue
+		}
+		// Leaf claims may have been countered by a step since
+		refreshed, err := l.fetchClaim(opts, uint64(i))
+		if err != nil {
+			return nil, err
+		}
+		claims[i].Countered = refreshed.Countered
+	}
+	for i := uint64(len(claims)); i < claimCount.Uint64(); i++ {
+		claim, err := l.fetchClaim(opts, i)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, claim)
+		if i > 0 {
+			claims[claim.ParentIndex].Countered = true
+		}
+	}
+	l.cache = claimCache{
+		BlockNumber: head.Number.Uint64(),
+		BlockHash:   head.Hash(),
+		Claims:      claims,
+	}
+	if l.path != "" {
+		if err := l.saveCache(); err != nil {
+			l.log.Warn("Failed to persist claim cache", "path", l.path, "err", err)
+		}
+	}
+	return l.toClaims(), nil
+}
+
+// checkReorg drops the cache if the L1 block it was loaded at is no longer canonical.
+func (l *CachingLoader) checkReorg(ctx context.Context, head *ethtypes.Header) error {
+	if l.cache.BlockHash == (common.Hash{}) {
+		return nil
+	}
+	if head.Number.Uint64() >= l.cache.BlockNumber {
+		header, err := l.headers.HeaderByNumber(ctx, new(big.Int).SetUint64(l.cache.BlockNumber))
+		if err != nil {
+			return fmt.Errorf("fetch cached L1 block %v: %w", l.cache.BlockNumber, err)
+		}
+		if header.Hash() == l.cache.BlockHash {
+			return nil
+		}
+	}
+	l.log.Warn("L1 reorg detected, reloading all claims", "block", l.cache.BlockNumber, "hash", l.cache.BlockHash)
+	l.cache = claimCache{}
+	return nil
+}
+
+func (l *CachingLoader) fetchClaim(opts *bind.CallOpts, arrIndex uint64) (cachedClaim, error) {
+	fetchedClaim, err := l.claimFetcher.ClaimData(opts, new(big.Int).SetUint64(arrIndex))
+	if err != nil {
+		return cachedClaim{}, err
+	}
+	return cachedClaim{
+		ParentIndex: fetchedClaim.ParentIndex,
+		Countered:   fetchedClaim.Countered,
+		Claim:       fetchedClaim.Claim,
+		Position:    fetchedClaim.Position.Uint64(),
+		Clock:       types.NewClock(fetchedClaim.Clock),
+	}, nil
+}
+
+// toClaims builds the [types.Claim] list from the cache, hydrating the parents from the earlier claims.
+func (l *CachingLoader) toClaims() []types.Claim {
+	claims := make([]types.Claim, len(l.cache.Claims))
+	for i, cached := range l.cache.Claims {
+		claim := types.Claim{
+			ClaimData: types.ClaimData{
+				Value:    cached.Claim,
+				Position: types.NewPositionFromGIndex(cached.Position),
+			},
+			Countered:           cached.Countered,
+			Clock:               cached.Clock,
+			ContractIndex:       i,
+			ParentContractIndex: int(cached.ParentIndex),
+		}
+		if !claim.IsRootPosition() {
+			claim.Parent = claims[cached.ParentIndex].ClaimData
+		}
+		claims[i] = claim
+	}
+	return claims
+}
+
+func (l *CachingLoader) loadCache() error {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return err
+	}
+	var cache claimCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return fmt.Errorf("decode claim cache: %w", err)
+	}
+	for i, claim := range cache.Claims {
+		if i > 0 && int(claim.ParentIndex) >= i {
+			return fmt.Errorf("claim %v has invalid parent %v", i, claim.ParentIndex)
+		}
+	}
+	l.cache = cache
+	return nil
+}
+
+// saveCache writes the cache to a temporary file first, so an interrupted write does not corrupt it.
+func (l *CachingLoader) saveCache() error {
+	data, err := json.Marshal(l.cache)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
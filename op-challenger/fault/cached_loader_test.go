@@ -0,0 +1,186 @@
+package fault
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+const cachedLoaderMaxDepth = 2
+
+type contractClaim = struct {
+	ParentIndex uint32
+	Countered   bool
+	Claim       [32]byte
+	Position    *big.Int
+	Clock       *big.Int
+}
+
+// stubGameContract is a game contract counting the claim data calls made against it.
+type stubGameContract struct {
+	claims     []contractClaim
+	dataCalls  int
+	lastBlock  *big.Int
+	lenQueries int
+}
+
+func (s *stubGameContract) ClaimData(opts *bind.CallOpts, arg0 *big.Int) (contractClaim, error) {
+	s.dataCalls++
+	s.lastBlock = opts.BlockNumber
+	return s.claims[arg0.Uint64()], nil
+}
+
+func (s *stubGameContract) ClaimDataLen(opts *bind.CallOpts) (*big.Int, error) {
+	s.lenQueries++
+	s.lastBlock = opts.BlockNumber
+	return big.NewInt(int64(len(s.claims))), nil
+}
+
+// move adds a claim against the parent, countering it.
+func (s *stubGameContract) move(parent uint32, gindex int64) {
+	s.claims[parent].Countered = true
+	s.claims = append(s.claims, contractClaim{
+		ParentIndex: parent,
+		Claim:       common.Hash{byte(len(s.claims))},
+		Position:    big.NewInt(gindex),
+		Clock:       big.NewInt(int64(len(s.claims))),
+	})
+}
+
+// stubChain is an L1 chain whose blocks are distinguished by their extra data.
+type stubChain struct {
+	head  uint64
+	forks map[uint64]byte
+}
+
+func (s *stubChain) HeaderByNumber(_ context.Context, number *big.Int) (*ethtypes.Header, error) {
+	num := s.head
+	if number != nil {
+		num = number.Uint64()
+	}
+	return &ethtypes.Header{Number: new(big.Int).SetUint64(num), Extra: []byte{s.forks[num]}}, nil
+}
+
+func newStubGame() *stubGameContract {
+	return &stubGameContract{
+		claims: []contractClaim{{Claim: common.Hash{0xaa}, Position: big.NewInt(1), Clock: big.NewInt(0)}},
+	}
+}
+
+func newTestCachingLoader(t *testing.T, game *stubGameContract, chain *stubChain, path string) *CachingLoader {
+	return NewCachingLoader(testlog.Logger(t, log.LvlError), game, chain, cachedLoaderMaxDepth, path)
+}
+
+func TestCachingLoader_FetchesOnlyNewClaims(t *testing.T) {
+	game := newStubGame()
+	chain := &stubChain{head: 10, forks: map[uint64]byte{}}
+	loader := newTestCachingLoader(t, game, chain, "")
+
+	claims, err := loader.FetchClaims(context.Background())
+	require.NoError(t, err)
+	require.Len(t, claims, 1)
+	require.Equal(t, 1, game.dataCalls)
+	require.Equal(t, big.NewInt(10), game.lastBlock, "should load claims as of the head")
+
+	game.move(0, 2)
+	chain.head = 11
+	claims, err = loader.FetchClaims(context.Background())
+	require.NoError(t, err)
+	require.Len(t, claims, 2)
+	require.Equal(t, 2, game.dataCalls, "should only fetch the new claim")
+	require.True(t, claims[0].Countered, "should counter the parent of the new claim")
+	require.Equal(t, claims[0].ClaimData, claims[1].Parent)
+	require.Equal(t, 0, claims[1].ParentContractIndex)
+	require.Equal(t, 1, claims[1].ContractIndex)
+
+	claims, err = loader.FetchClaims(context.Background())
+	require.NoError(t, err)
+	require.Len(t, claims, 2)
+	require.Equal(t, 2, game.dataCalls, "should not fetch unchanged claims")
+}
+
+func TestCachingLoader_RefreshesUncounteredLeafClaims(t *testing.T) {
+	game := newStubGame()
+	game.move(0, 2)
+	game.move(1, 4)
+	chain := &stubChain{head: 10, forks: map[uint64]byte{}}
+	loader := newTestCachingLoader(t, game, chain, "")
+
+	claims, err := loader.FetchClaims(context.Background())
+	require.NoError(t, err)
+	require.False(t, claims[2].Countered)
+	require.Equal(t, 3, game.dataCalls)
+
+	// A step counters the leaf claim without adding a claim
+	game.claims[2].Countered = true
+	claims, err = loader.FetchClaims(context.Background())
+	require.NoError(t, err)
+	require.True(t, claims[2].Countered)
+	require.Equal(t, 4, game.dataCalls, "should only refresh the uncountered leaf claim")
+
+	_, err = loader.FetchClaims(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 4, game.dataCalls, "should not refresh countered leaf claims")
+}
+
+func TestCachingLoader_ReloadsOnReorg(t *testing.T) {
+	game := newStubGame()
+	game.move(0, 2)
+	chain := &stubChain{head: 10, forks: map[uint64]byte{}}
+	loader := newTestCachingLoader(t, game, chain, "")
+	_, err := loader.FetchClaims(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, game.dataCalls)
+
+	t.Run("BlockReplaced", func(t *testing.T) {
+		chain.forks[10] = 1
+		_, err := loader.FetchClaims(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 4, game.dataCalls, "should reload all claims")
+	})
+
+	t.Run("ChainShortened", func(t *testing.T) {
+		chain.head = 9
+		_, err := loader.FetchClaims(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 6, game.dataCalls, "should reload all claims")
+	})
+}
+
+func TestCachingLoader_PersistsCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claims", "game.json")
+	game := newStubGame()
+	game.move(0, 2)
+	chain := &stubChain{head: 10, forks: map[uint64]byte{}}
+	expected, err := newTestCachingLoader(t, game, chain, path).FetchClaims(context.Background())
+	require.NoError(t, err)
+
+	game.dataCalls = 0
+	claims, err := newTestCachingLoader(t, game, chain, path).FetchClaims(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, expected, claims)
+	require.Zero(t, game.dataCalls, "should resume from the persisted cache")
+}
+
+func TestCachingLoader_MatchesLoader(t *testing.T) {
+	game := newStubGame()
+	game.move(0, 2)
+	game.move(0, 3)
+	game.move(1, 5)
+	chain := &stubChain{head: 10, forks: map[uint64]byte{}}
+	claims, err := newTestCachingLoader(t, game, chain, "").FetchClaims(context.Background())
+	require.NoError(t, err)
+	expected, err := NewLoader(game).FetchClaims(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, expected, claims)
+	require.Equal(t, types.NewPositionFromGIndex(5), claims[3].Position)
+}
@@ -1,14 +1,21 @@
 package cannon
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -16,6 +23,8 @@ const (
 	proofsDir = "proofs"
 )
 
+var ErrPrestateMismatch = errors.New("absolute prestate does not match the game")
+
 type proofData struct {
 	ClaimValue hexutil.Bytes `json:"post"`
 	StateData  hexutil.Bytes `json:"state-data"`
@@ -24,18 +33,64 @@ type proofData struct {
 
 type Executor interface {
 	// GenerateProof executes cannon to generate a proof at the specified trace index in dataDir.
-	GenerateProof(dataDir string, proofAt uint64) error
+	GenerateProof(ctx context.Context, dataDir string, proofAt uint64) error
+}
+
+// L1Source provides the game contract data and L1 headers to derive the local inputs of the game from.
+type L1Source interface {
+	bind.ContractCaller
+	HeaderSource
+}
+
+// PrestateSource provides the absolute prestate claim of the game.
+type PrestateSource interface {
+	ABSOLUTEPRESTATE(opts *bind.CallOpts) ([32]byte, error)
 }
 
 type CannonTraceProvider struct {
 	dir      string
+	prestate []byte
 	executor Executor
 }
 
-func NewCannonTraceProvider(logger log.Logger, dataDir string) *CannonTraceProvider {
+// NewCannonTraceProvider creates a trace provider for the game of cfg,
+// with local inputs derived from the game contract and the L1 and L2 chains.
+func NewCannonTraceProvider(ctx context.Context, logger log.Logger, cfg *config.Config, l1Client L1Source) (*CannonTraceProvider, error) {
+	l2Client, err := ethclient.DialContext(ctx, cfg.CannonL2)
+	if err != nil {
+		return nil, fmt.Errorf("dial l2 client %v: %w", cfg.CannonL2, err)
+	}
+	defer l2Client.Close()
+	gameCaller, err := bindings.NewFaultDisputeGameCaller(cfg.GameAddress, l1Client)
+	if err != nil {
+		return nil, fmt.Errorf("create caller for game %v: %w", cfg.GameAddress, err)
+	}
+	prestate, err := LoadPrestate(ctx, cfg, gameCaller)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := FetchLocalInputs(ctx, gameCaller, l1Client, l2Client)
+	if err != nil {
+		return nil, fmt.Errorf("fetch local game inputs: %w", err)
+	}
+	logger.Info("Derived local game inputs", "l1Head", inputs.L1Head, "l2Head", inputs.L2Head,
+		"l2Claim", inputs.L2Claim, "l2BlockNumber", inputs.L2BlockNumber)
+	return &CannonTraceProvider{
+		dir:      cfg.CannonDatadir,
+		prestate: prestate,
+		executor: newExecutor(logger, cfg, inputs),
+	}, nil
+}
+
+// NewTraceProviderForInputs creates a trace provider running cannon with the given local inputs,
+// rather than the inputs of the game, from a prestate loaded with [LoadPrestate].
+// Data is stored in a subdirectory of the cannon datadir for the L2 block number of the inputs,
+// so the traces of different blocks of the same game do not conflict.
+func NewTraceProviderForInputs(logger log.Logger, cfg *config.Config, prestate []byte, inputs LocalGameInputs) *CannonTraceProvider {
 	return &CannonTraceProvider{
-		dir:      dataDir,
-		executor: newExecutor(logger),
+		dir:      filepath.Join(cfg.CannonDatadir, inputs.L2BlockNumber.Text(10)),
+		prestate: prestate,
+		executor: newExecutor(logger, cfg, inputs),
 	}
 }
 
@@ -69,14 +124,15 @@ func (p *CannonTraceProvider) GetPreimage(i uint64) ([]byte, []byte, error) {
 }
 
 func (p *CannonTraceProvider) AbsolutePreState() []byte {
-	panic("absolute prestate not yet supported")
+	return p.prestate
 }
 
 func (p *CannonTraceProvider) loadProof(i uint64) (*proofData, error) {
 	path := filepath.Join(p.dir, proofsDir, fmt.Sprintf("%d.json", i))
 	file, err := os.Open(path)
 	if errors.Is(err, os.ErrNotExist) {
-		if err := p.executor.GenerateProof(p.dir, i); err != nil {
+		// The trace provider has no context, the executor enforces the configured timeout.
+		if err := p.executor.GenerateProof(context.Background(), p.dir, i); err != nil {
 			return nil, fmt.Errorf("generate cannon trace with proof at %v: %w", i, err)
 		}
 		// Try opening the file again now and it should exist.
@@ -93,3 +149,39 @@ func (p *CannonTraceProvider) loadProof(i uint64) (*proofData, error) {
 	}
 	return &proof, nil
 }
+
+// LoadPrestate loads the absolute prestate of cfg and checks the game commits to it.
+func LoadPrestate(ctx context.Context, cfg *config.Config, caller PrestateSource) ([]byte, error) {
+	prestate, err := loadPrestate(cfg.CannonAbsolutePreState)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePrestate(ctx, caller, prestate); err != nil {
+		return nil, err
+	}
+	return prestate, nil
+}
+
+// loadPrestate loads the cannon VM state at path, in JSON or binary format,
+// and encodes it as the state data of the absolute prestate.
+func loadPrestate(path string) ([]byte, error) {
+	state, err := mipsevm.LoadStateFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read absolute prestate: %w", err)
+	}
+	return state.EncodeWitness(), nil
+}
+
+// validatePrestate checks the game commits to the absolute prestate,
+// otherwise any step from trace index 0 would be rejected by the game.
+func validatePrestate(ctx context.Context, caller PrestateSource, prestate []byte) error {
+	gamePrestate, err := caller.ABSOLUTEPRESTATE(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return fmt.Errorf("fetch game absolute prestate: %w", err)
+	}
+	localPrestate := crypto.Keccak256Hash(prestate)
+	if localPrestate != gamePrestate {
+		return fmt.Errorf("%w: local %v, game %v", ErrPrestateMismatch, localPrestate, common.Hash(gamePrestate))
+	}
+	return nil
+}
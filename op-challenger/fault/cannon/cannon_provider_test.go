@@ -1,13 +1,19 @@
 package cannon
 
 import (
+	"context"
 	"embed"
 	_ "embed"
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -87,6 +93,67 @@ func TestGetPreimage(t *testing.T) {
 	})
 }
 
+func TestAbsolutePreState(t *testing.T) {
+	state := &mipsevm.State{
+		Memory: mipsevm.NewMemory(),
+		PC:     4,
+		NextPC: 8,
+		Heap:   0x20000000,
+	}
+	state.Memory.SetMemory(4, 0x3c01000a)
+	path := filepath.Join(t.TempDir(), "prestate.json")
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	t.Run("Load", func(t *testing.T) {
+		prestate, err := loadPrestate(path)
+		require.NoError(t, err)
+		require.Equal(t, state.EncodeWitness(), prestate)
+
+		provider := &CannonTraceProvider{prestate: prestate}
+		require.Equal(t, state.EncodeWitness(), provider.AbsolutePreState())
+	})
+
+	t.Run("LoadBinary", func(t *testing.T) {
+		binPath := filepath.Join(t.TempDir(), "prestate.bin.zst")
+		require.NoError(t, mipsevm.WriteStateFile(binPath, state))
+		prestate, err := loadPrestate(binPath)
+		require.NoError(t, err)
+		require.Equal(t, state.EncodeWitness(), prestate)
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		_, err := loadPrestate(filepath.Join(t.TempDir(), "missing.json"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("InvalidFile", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "invalid.json")
+		require.NoError(t, os.WriteFile(invalid, []byte("not json"), 0o644))
+		_, err := loadPrestate(invalid)
+		require.ErrorContains(t, err, "failed to read absolute prestate")
+	})
+
+	t.Run("MatchesGame", func(t *testing.T) {
+		caller := &stubPrestateSource{prestate: crypto.Keccak256Hash(state.EncodeWitness())}
+		require.NoError(t, validatePrestate(context.Background(), caller, state.EncodeWitness()))
+	})
+
+	t.Run("MismatchesGame", func(t *testing.T) {
+		caller := &stubPrestateSource{prestate: common.Hash{0xaa}}
+		err := validatePrestate(context.Background(), caller, state.EncodeWitness())
+		require.ErrorIs(t, err, ErrPrestateMismatch)
+	})
+
+	t.Run("GameError", func(t *testing.T) {
+		fetchErr := errors.New("boom")
+		caller := &stubPrestateSource{err: fetchErr}
+		err := validatePrestate(context.Background(), caller, state.EncodeWitness())
+		require.ErrorIs(t, err, fetchErr)
+	})
+}
+
 func setupTestData(t *testing.T) string {
 	srcDir := filepath.Join("test_data", "proofs")
 	entries, err := testData.ReadDir(srcDir)
@@ -115,7 +182,16 @@ type stubExecutor struct {
 	generated []int // Using int makes assertions easier
 }
 
-func (e *stubExecutor) GenerateProof(dir string, i uint64) error {
+func (e *stubExecutor) GenerateProof(ctx context.Context, dir string, i uint64) error {
 	e.generated = append(e.generated, int(i))
 	return nil
 }
+
+type stubPrestateSource struct {
+	prestate common.Hash
+	err      error
+}
+
+func (s *stubPrestateSource) ABSOLUTEPRESTATE(_ *bind.CallOpts) ([32]byte, error) {
+	return s.prestate, s.err
+}
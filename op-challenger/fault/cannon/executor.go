@@ -1,21 +1,117 @@
 package cannon
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
 
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	oplog "github.com/ethereum-optimism/optimism/op-service/log"
 	"github.com/ethereum/go-ethereum/log"
 )
 
+const (
+	// preimagesDir is the op-program datadir, shared by all proofs of a game
+	preimagesDir = "preimages"
+	// finalState is the file cannon writes the state it stopped at to, in the compressed binary format
+	finalState = "final.bin.zst"
+)
+
+type cmdExecutor func(ctx context.Context, l log.Logger, binary string, args ...string) error
+
 type executor struct {
-	logger log.Logger
+	logger           log.Logger
+	l1               string
+	l2               string
+	inputs           LocalGameInputs
+	cannon           string
+	server           string
+	network          string
+	rollupConfig     string
+	l2Genesis        string
+	absolutePreState string
+	timeout          time.Duration
+	cmdExecutor      cmdExecutor
 }
 
-func newExecutor(logger log.Logger) Executor {
+func newExecutor(logger log.Logger, cfg *config.Config, inputs LocalGameInputs) *executor {
 	return &executor{
-		logger: logger,
+		logger:           logger,
+		l1:               cfg.L1EthRpc,
+		l2:               cfg.CannonL2,
+		inputs:           inputs,
+		cannon:           cfg.CannonBin,
+		server:           cfg.CannonServer,
+		network:          cfg.CannonNetwork,
+		rollupConfig:     cfg.CannonRollupConfigPath,
+		l2Genesis:        cfg.CannonL2GenesisPath,
+		absolutePreState: cfg.CannonAbsolutePreState,
+		timeout:          cfg.CannonTimeout,
+		cmdExecutor:      runCmd,
+	}
+}
+
+// GenerateProof runs cannon from the absolute pre-state up to trace index i,
+// writing the proof at i to the proofs directory of dir.
+// The op-program is run as pre-image server, with the local inputs of the game.
+func (e *executor) GenerateProof(ctx context.Context, dir string, i uint64) error {
+	proofDir := filepath.Join(dir, proofsDir)
+	if err := os.MkdirAll(proofDir, 0755); err != nil {
+		return fmt.Errorf("could not create proof directory %v: %w", proofDir, err)
+	}
+	args := []string{
+		"run",
+		"--input", e.absolutePreState,
+		"--output", filepath.Join(dir, finalState),
+		"--meta", "",
+		"--proof-at", "=" + strconv.FormatUint(i, 10),
+		"--stop-at", "=" + strconv.FormatUint(i+1, 10),
+		"--proof-fmt", filepath.Join(proofDir, "%d.json"),
+		"--",
+		e.server, "--server",
+		"--l1", e.l1,
+		"--l2", e.l2,
+		"--datadir", filepath.Join(dir, preimagesDir),
+		"--l1.head", e.inputs.L1Head.Hex(),
+		"--l2.head", e.inputs.L2Head.Hex(),
+		"--l2.claim", e.inputs.L2Claim.Hex(),
+		"--l2.blocknumber", e.inputs.L2BlockNumber.Text(10),
+	}
+	if e.network != "" {
+		args = append(args, "--network", e.network)
+	}
+	if e.rollupConfig != "" {
+		args = append(args, "--rollup.config", e.rollupConfig)
+	}
+	if e.l2Genesis != "" {
+		args = append(args, "--l2.genesis", e.l2Genesis)
+	}
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	e.logger.Info("Generating trace", "proof", i, "cmd", e.cannon, "args", args)
+	if err := e.cmdExecutor(ctx, e.logger.New("proof", i), e.cannon, args...); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("cannon did not complete: %w", ctx.Err())
+		}
+		return err
 	}
+	return nil
 }
 
-func (e *executor) GenerateProof(dir string, i uint64) error {
-	return fmt.Errorf("please execute cannon with --proof-at %v --proof-fmt %v/%v/%%d.json", i, dir, proofsDir)
+func runCmd(ctx context.Context, l log.Logger, binary string, args ...string) error {
+	cmd := exec.CommandContext(ctx, binary, args...)
+	stdOut := oplog.NewWriter(l, log.LvlInfo)
+	defer stdOut.Close()
+	stdErr := oplog.NewWriter(l, log.LvlError)
+	defer stdErr.Close()
+	cmd.Stdout = stdOut
+	cmd.Stderr = stdErr
+	return cmd.Run()
 }
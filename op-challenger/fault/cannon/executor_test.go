@@ -0,0 +1,165 @@
+package cannon
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+var testInputs = LocalGameInputs{
+	L1Head:        common.Hash{0x11},
+	L2Head:        common.Hash{0x22},
+	L2Claim:       common.Hash{0x33},
+	L2BlockNumber: big.NewInt(3333),
+}
+
+func testExecutorConfig() *config.Config {
+	cfg := config.NewConfig("http://localhost:8888", common.Address{0xaa}, config.TraceTypeCannon, true, 5)
+	cfg.CannonDatadir = "./test_data"
+	cfg.CannonAbsolutePreState = "pre.json"
+	cfg.CannonBin = "./bin/cannon"
+	cfg.CannonServer = "./bin/op-program"
+	cfg.CannonL2 = "http://localhost:9999"
+	return &cfg
+}
+
+func TestGenerateProof(t *testing.T) {
+	captureExec := func(t *testing.T, cfg *config.Config, proofAt uint64) (string, map[string]string) {
+		executor := newExecutor(testlog.Logger(t, log.LvlInfo), cfg, testInputs)
+		var binary string
+		var subcommand string
+		args := make(map[string]string)
+		executor.cmdExecutor = func(ctx context.Context, l log.Logger, b string, a ...string) error {
+			binary = b
+			subcommand = a[0]
+			for i := 1; i < len(a); {
+				if a[i] == "--" {
+					// Skip over the divider between cannon and server program
+					i++
+					args["server"] = a[i]
+					i++
+					continue
+				}
+				if a[i] == "--server" {
+					args[a[i]] = "true"
+					i++
+					continue
+				}
+				args[a[i]] = a[i+1]
+				i += 2
+			}
+			return nil
+		}
+		err := executor.GenerateProof(context.Background(), cfg.CannonDatadir, proofAt)
+		require.NoError(t, err)
+		require.Equal(t, "run", subcommand)
+		return binary, args
+	}
+
+	t.Run("Network", func(t *testing.T) {
+		cfg := testExecutorConfig()
+		cfg.CannonDatadir = t.TempDir()
+		cfg.CannonNetwork = "mainnet"
+		binary, args := captureExec(t, cfg, 150_000_000)
+		require.Equal(t, cfg.CannonBin, binary)
+		require.Equal(t, cfg.CannonAbsolutePreState, args["--input"])
+		require.Equal(t, filepath.Join(cfg.CannonDatadir, finalState), args["--output"])
+		require.Equal(t, "", args["--meta"])
+		require.Equal(t, "=150000000", args["--proof-at"])
+		require.Equal(t, "=150000001", args["--stop-at"])
+		require.Equal(t, filepath.Join(cfg.CannonDatadir, proofsDir, "%d.json"), args["--proof-fmt"])
+		require.Equal(t, cfg.CannonServer, args["server"])
+		require.Equal(t, "true", args["--server"])
+		require.Equal(t, cfg.L1EthRpc, args["--l1"])
+		require.Equal(t, cfg.CannonL2, args["--l2"])
+		require.Equal(t, filepath.Join(cfg.CannonDatadir, preimagesDir), args["--datadir"])
+		require.Equal(t, testInputs.L1Head.Hex(), args["--l1.head"])
+		require.Equal(t, testInputs.L2Head.Hex(), args["--l2.head"])
+		require.Equal(t, testInputs.L2Claim.Hex(), args["--l2.claim"])
+		require.Equal(t, "3333", args["--l2.blocknumber"])
+		require.Equal(t, "mainnet", args["--network"])
+		require.NotContains(t, args, "--rollup.config")
+		require.NotContains(t, args, "--l2.genesis")
+		require.DirExists(t, filepath.Join(cfg.CannonDatadir, proofsDir))
+	})
+
+	t.Run("RollupAndGenesis", func(t *testing.T) {
+		cfg := testExecutorConfig()
+		cfg.CannonDatadir = t.TempDir()
+		cfg.CannonRollupConfigPath = "rollup.json"
+		cfg.CannonL2GenesisPath = "genesis.json"
+		_, args := captureExec(t, cfg, 0)
+		require.NotContains(t, args, "--network")
+		require.Equal(t, cfg.CannonRollupConfigPath, args["--rollup.config"])
+		require.Equal(t, cfg.CannonL2GenesisPath, args["--l2.genesis"])
+	})
+}
+
+// writeStubCannon writes a script that behaves like cannon run,
+// writing a canned proof to the --proof-fmt path for the --proof-at step, after sleeping for the given duration.
+func writeStubCannon(t *testing.T, sleep time.Duration) string {
+	path := filepath.Join(t.TempDir(), "cannon")
+	script := fmt.Sprintf(`#!/bin/sh
+while [ "$#" -gt 0 ]; do
+  case "$1" in
+    --proof-at) step="${2#=}"; shift 2 ;;
+    --proof-fmt) fmt="$2"; shift 2 ;;
+    --) break ;;
+    *) shift ;;
+  esac
+done
+sleep %v >/dev/null 2>&1
+echo "running to step $step"
+printf '{"step":%%s,"pre":"0x01","post":"0x%%064x","state-data":"0x02","proof-data":"0x03"}' "$step" "$step" > "$(printf "$fmt" "$step")"
+`, sleep.Seconds())
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+func TestGenerateProofWithStubCannon(t *testing.T) {
+	cfg := testExecutorConfig()
+	cfg.CannonDatadir = t.TempDir()
+	cfg.CannonNetwork = "mainnet"
+	cfg.CannonBin = writeStubCannon(t, 0)
+	provider := &CannonTraceProvider{
+		dir:      cfg.CannonDatadir,
+		executor: newExecutor(testlog.Logger(t, log.LvlInfo), cfg, testInputs),
+	}
+
+	value, err := provider.Get(42)
+	require.NoError(t, err)
+	require.Equal(t, common.BigToHash(big.NewInt(42)), value)
+	require.FileExists(t, filepath.Join(cfg.CannonDatadir, proofsDir, "42.json"))
+
+	// Cached proofs are loaded without running cannon again
+	require.NoError(t, os.Remove(cfg.CannonBin))
+	preimage, proof, err := provider.GetPreimage(42)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x02}, preimage)
+	require.Equal(t, []byte{0x03}, proof)
+}
+
+func TestGenerateProofTimeout(t *testing.T) {
+	cfg := testExecutorConfig()
+	cfg.CannonDatadir = t.TempDir()
+	cfg.CannonNetwork = "mainnet"
+	cfg.CannonBin = writeStubCannon(t, 5*time.Second)
+	cfg.CannonTimeout = 100 * time.Millisecond
+	executor := newExecutor(testlog.Logger(t, log.LvlInfo), cfg, testInputs)
+
+	start := time.Now()
+	err := executor.GenerateProof(context.Background(), cfg.CannonDatadir, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.NoFileExists(t, filepath.Join(cfg.CannonDatadir, proofsDir, "1.json"))
+}
@@ -0,0 +1,116 @@
+package cannon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LocalGameInputs are the boot inputs of the op-program for the game, the local pre-images of the cannon trace.
+type LocalGameInputs struct {
+	L1Head        common.Hash
+	L2Head        common.Hash
+	L2Claim       common.Hash
+	L2BlockNumber *big.Int
+}
+
+// GameInputsSource provides the game data the local inputs are derived from.
+type GameInputsSource interface {
+	GameCreationSource
+	RootClaim(opts *bind.CallOpts) ([32]byte, error)
+	L2BlockNumber(opts *bind.CallOpts) (*big.Int, error)
+}
+
+// HeaderSource provides block headers by number, nil for the latest block.
+type HeaderSource interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// FetchLocalInputs derives the op-program boot inputs from the game:
+// the claim is the root claim of the game, at the L2 block number of the game,
+// the agreed L2 head is the L2 block before it,
+// and the L1 head is the L1 block the game was created in, the L1 data available to the proposer.
+func FetchLocalInputs(ctx context.Context, caller GameInputsSource, l1Client HeaderSource, l2Client HeaderSource) (LocalGameInputs, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	claim, err := caller.RootClaim(opts)
+	if err != nil {
+		return LocalGameInputs{}, fmt.Errorf("fetch root claim: %w", err)
+	}
+	l2BlockNumber, err := caller.L2BlockNumber(opts)
+	if err != nil {
+		return LocalGameInputs{}, fmt.Errorf("fetch L2 block number: %w", err)
+	}
+	if l2BlockNumber.Sign() <= 0 {
+		return LocalGameInputs{}, fmt.Errorf("invalid L2 block number %v", l2BlockNumber)
+	}
+	l2Head, err := l2Client.HeaderByNumber(ctx, new(big.Int).Sub(l2BlockNumber, big.NewInt(1)))
+	if err != nil {
+		return LocalGameInputs{}, fmt.Errorf("fetch L2 head: %w", err)
+	}
+	l1Head, err := FetchL1Head(ctx, caller, l1Client)
+	if err != nil {
+		return LocalGameInputs{}, err
+	}
+	return LocalGameInputs{
+		L1Head:        l1Head,
+		L2Head:        l2Head.Hash(),
+		L2Claim:       claim,
+		L2BlockNumber: l2BlockNumber,
+	}, nil
+}
+
+// GameCreationSource provides the time a game was created at.
+type GameCreationSource interface {
+	CreatedAt(opts *bind.CallOpts) (uint64, error)
+}
+
+// FetchL1Head finds the L1 head of the game, the L1 block the game was created in.
+func FetchL1Head(ctx context.Context, caller GameCreationSource, l1Client HeaderSource) (common.Hash, error) {
+	createdAt, err := caller.CreatedAt(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("fetch game creation time: %w", err)
+	}
+	l1Head, err := findHeaderAtTime(ctx, l1Client, createdAt)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("find L1 head at game creation: %w", err)
+	}
+	return l1Head.Hash(), nil
+}
+
+// findHeaderAtTime finds the last block with a timestamp at or before the given time, by binary search.
+func findHeaderAtTime(ctx context.Context, client HeaderSource, timestamp uint64) (*types.Header, error) {
+	latest, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if latest.Time <= timestamp {
+		return latest, nil
+	}
+	// Invariant: the block at lo is at or before the time, the block at hi is after it.
+	lo, hi := uint64(0), latest.Number.Uint64()
+	found, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(lo))
+	if err != nil {
+		return nil, err
+	}
+	if found.Time > timestamp {
+		return nil, errors.New("no block at or before game creation")
+	}
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return nil, err
+		}
+		if header.Time <= timestamp {
+			lo, found = mid, header
+		} else {
+			hi = mid
+		}
+	}
+	return found, nil
+}
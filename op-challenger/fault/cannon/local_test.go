@@ -0,0 +1,116 @@
+package cannon
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchLocalInputs(t *testing.T) {
+	caller := &stubGameCaller{
+		rootClaim:     common.Hash{0xcc},
+		l2BlockNumber: big.NewInt(50),
+		createdAt:     1055,
+	}
+	l1Client := newStubHeaderSource(100, 1000, 12)
+	l2Client := newStubHeaderSource(80, 500, 2)
+
+	inputs, err := FetchLocalInputs(context.Background(), caller, l1Client, l2Client)
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{0xcc}, inputs.L2Claim)
+	require.Equal(t, big.NewInt(50), inputs.L2BlockNumber)
+	require.Equal(t, l2Client.headers[49].Hash(), inputs.L2Head)
+	require.Equal(t, l1Client.headers[4].Hash(), inputs.L1Head)
+}
+
+func TestFetchLocalInputsErrors(t *testing.T) {
+	l1Client := newStubHeaderSource(100, 1000, 12)
+	l2Client := newStubHeaderSource(80, 500, 2)
+
+	t.Run("ZeroBlockNumber", func(t *testing.T) {
+		caller := &stubGameCaller{l2BlockNumber: big.NewInt(0), createdAt: 1055}
+		_, err := FetchLocalInputs(context.Background(), caller, l1Client, l2Client)
+		require.ErrorContains(t, err, "invalid L2 block number")
+	})
+
+	t.Run("MissingL2Head", func(t *testing.T) {
+		caller := &stubGameCaller{l2BlockNumber: big.NewInt(200), createdAt: 1055}
+		_, err := FetchLocalInputs(context.Background(), caller, l1Client, l2Client)
+		require.ErrorIs(t, err, errNotFound)
+	})
+}
+
+func TestFindHeaderAtTime(t *testing.T) {
+	client := newStubHeaderSource(100, 1000, 12)
+	find := func(timestamp uint64) uint64 {
+		header, err := findHeaderAtTime(context.Background(), client, timestamp)
+		require.NoError(t, err)
+		return header.Number.Uint64()
+	}
+
+	require.Equal(t, uint64(0), find(1000))
+	require.Equal(t, uint64(0), find(1011))
+	require.Equal(t, uint64(1), find(1012))
+	require.Equal(t, uint64(50), find(1000+50*12+5))
+	require.Equal(t, uint64(98), find(1000+99*12-1))
+	require.Equal(t, uint64(99), find(1000+99*12))
+	require.Equal(t, uint64(99), find(1000000))
+
+	_, err := findHeaderAtTime(context.Background(), client, 999)
+	require.ErrorContains(t, err, "no block at or before")
+}
+
+var errNotFound = errors.New("not found")
+
+type stubGameCaller struct {
+	rootClaim     common.Hash
+	l2BlockNumber *big.Int
+	createdAt     uint64
+}
+
+func (s *stubGameCaller) RootClaim(_ *bind.CallOpts) ([32]byte, error) {
+	return s.rootClaim, nil
+}
+
+func (s *stubGameCaller) L2BlockNumber(_ *bind.CallOpts) (*big.Int, error) {
+	return s.l2BlockNumber, nil
+}
+
+func (s *stubGameCaller) CreatedAt(_ *bind.CallOpts) (uint64, error) {
+	return s.createdAt, nil
+}
+
+type stubHeaderSource struct {
+	headers []*types.Header
+}
+
+// newStubHeaderSource creates a chain of count blocks, starting at genesisTime with blockTime seconds between blocks.
+func newStubHeaderSource(count int, genesisTime uint64, blockTime uint64) *stubHeaderSource {
+	headers := make([]*types.Header, count)
+	for i := range headers {
+		headers[i] = &types.Header{
+			Number: big.NewInt(int64(i)),
+			Time:   genesisTime + uint64(i)*blockTime,
+		}
+		if i > 0 {
+			headers[i].ParentHash = headers[i-1].Hash()
+		}
+	}
+	return &stubHeaderSource{headers: headers}
+}
+
+func (s *stubHeaderSource) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return s.headers[len(s.headers)-1], nil
+	}
+	if !number.IsUint64() || number.Uint64() >= uint64(len(s.headers)) {
+		return nil, errNotFound
+	}
+	return s.headers[number.Uint64()], nil
+}
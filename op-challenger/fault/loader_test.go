@@ -106,7 +106,7 @@ func TestLoader_FetchClaims_Succeeds(t *testing.T) {
 				Position: types.NewPositionFromGIndex(expectedClaims[0].Position.Uint64()),
 			},
 			Countered:     false,
-			Clock:         uint64(0),
+			Clock:         types.Clock{},
 			ContractIndex: 0,
 		},
 		{
@@ -119,7 +119,7 @@ func TestLoader_FetchClaims_Succeeds(t *testing.T) {
 				Position: types.NewPositionFromGIndex(expectedClaims[1].Position.Uint64()),
 			},
 			Countered:     false,
-			Clock:         uint64(0),
+			Clock:         types.Clock{},
 			ContractIndex: 1,
 		},
 		{
@@ -132,7 +132,7 @@ func TestLoader_FetchClaims_Succeeds(t *testing.T) {
 				Position: types.NewPositionFromGIndex(expectedClaims[2].Position.Uint64()),
 			},
 			Countered:     false,
-			Clock:         uint64(0),
+			Clock:         types.Clock{},
 			ContractIndex: 2,
 		},
 	}, claims)
@@ -0,0 +1,79 @@
+package outputs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// outputTimeout bounds each request for an output root, as trace providers have no context of their own.
+const outputTimeout = 30 * time.Second
+
+var (
+	ErrIndexTooLarge = errors.New("index is larger than the maximum index")
+	ErrNoStep        = errors.New("output root traces can not be stepped")
+)
+
+// OutputRollupClient provides the output roots of a trusted rollup node.
+type OutputRollupClient interface {
+	OutputAtBlock(ctx context.Context, blockNum uint64) (*eth.OutputResponse, error)
+}
+
+// OutputTraceProvider is a [TraceProvider] over the L2 output roots of the blocks disputed by a game.
+// Trace index i commits to the output root of block prestateBlock+i+1,
+// the absolute prestate is the output root of prestateBlock, which the game does not dispute.
+type OutputTraceProvider struct {
+	rollup        OutputRollupClient
+	prestateBlock uint64
+	maxLen        uint64
+	prestate      common.Hash
+}
+
+// NewTraceProvider creates an [OutputTraceProvider] over the 2^depth blocks after prestateBlock.
+func NewTraceProvider(ctx context.Context, rollup OutputRollupClient, prestateBlock uint64, depth uint64) (*OutputTraceProvider, error) {
+	prestate, err := outputRootAtBlock(ctx, rollup, prestateBlock)
+	if err != nil {
+		return nil, fmt.Errorf("fetch absolute prestate output root: %w", err)
+	}
+	return &OutputTraceProvider{
+		rollup:        rollup,
+		prestateBlock: prestateBlock,
+		maxLen:        uint64(1 << depth),
+		prestate:      prestate,
+	}, nil
+}
+
+// BlockNumber returns the L2 block number the output root at trace index i is from.
+func (p *OutputTraceProvider) BlockNumber(i uint64) uint64 {
+	return p.prestateBlock + i + 1
+}
+
+func (p *OutputTraceProvider) Get(i uint64) (common.Hash, error) {
+	if i >= p.maxLen {
+		return common.Hash{}, ErrIndexTooLarge
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), outputTimeout)
+	defer cancel()
+	return outputRootAtBlock(ctx, p.rollup, p.BlockNumber(i))
+}
+
+// GetPreimage is not supported, claims at the split depth are resolved by the cannon sub-game below them.
+func (p *OutputTraceProvider) GetPreimage(i uint64) ([]byte, []byte, error) {
+	return nil, nil, ErrNoStep
+}
+
+func (p *OutputTraceProvider) AbsolutePreState() []byte {
+	return p.prestate.Bytes()
+}
+
+func outputRootAtBlock(ctx context.Context, rollup OutputRollupClient, blockNum uint64) (common.Hash, error) {
+	output, err := rollup.OutputAtBlock(ctx, blockNum)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("fetch output root at L2 block %v: %w", blockNum, err)
+	}
+	return common.Hash(output.OutputRoot), nil
+}
@@ -0,0 +1,85 @@
+package outputs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var errNoOutput = errors.New("no output")
+
+type stubRollupClient struct {
+	// latest is the highest block with an output root available
+	latest uint64
+}
+
+func (s *stubRollupClient) OutputAtBlock(_ context.Context, blockNum uint64) (*eth.OutputResponse, error) {
+	if blockNum > s.latest {
+		return nil, fmt.Errorf("%w at %v", errNoOutput, blockNum)
+	}
+	return &eth.OutputResponse{
+		OutputRoot: eth.Bytes32(outputRoot(blockNum)),
+		BlockRef:   eth.L2BlockRef{Hash: blockHash(blockNum), Number: blockNum},
+	}, nil
+}
+
+func outputRoot(blockNum uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(0xaa00 + blockNum))
+}
+
+func blockHash(blockNum uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(0xbb00 + blockNum))
+}
+
+func TestGet(t *testing.T) {
+	provider, err := NewTraceProvider(context.Background(), &stubRollupClient{latest: 100}, 50, 3)
+	require.NoError(t, err)
+
+	t.Run("FirstIndex", func(t *testing.T) {
+		value, err := provider.Get(0)
+		require.NoError(t, err)
+		require.Equal(t, outputRoot(51), value)
+	})
+
+	t.Run("LastIndex", func(t *testing.T) {
+		value, err := provider.Get(7)
+		require.NoError(t, err)
+		require.Equal(t, outputRoot(58), value)
+	})
+
+	t.Run("IndexTooLarge", func(t *testing.T) {
+		_, err := provider.Get(8)
+		require.ErrorIs(t, err, ErrIndexTooLarge)
+	})
+
+	t.Run("OutputUnavailable", func(t *testing.T) {
+		provider, err := NewTraceProvider(context.Background(), &stubRollupClient{latest: 55}, 50, 3)
+		require.NoError(t, err)
+		_, err = provider.Get(7)
+		require.ErrorIs(t, err, errNoOutput)
+	})
+}
+
+func TestAbsolutePreState(t *testing.T) {
+	provider, err := NewTraceProvider(context.Background(), &stubRollupClient{latest: 100}, 50, 3)
+	require.NoError(t, err)
+	require.Equal(t, outputRoot(50).Bytes(), provider.AbsolutePreState())
+}
+
+func TestAbsolutePreStateUnavailable(t *testing.T) {
+	_, err := NewTraceProvider(context.Background(), &stubRollupClient{latest: 40}, 50, 3)
+	require.ErrorIs(t, err, errNoOutput)
+}
+
+func TestGetPreimage(t *testing.T) {
+	provider, err := NewTraceProvider(context.Background(), &stubRollupClient{latest: 100}, 50, 3)
+	require.NoError(t, err)
+	_, _, err = provider.GetPreimage(0)
+	require.ErrorIs(t, err, ErrNoStep)
+}
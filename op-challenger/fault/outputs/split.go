@@ -0,0 +1,192 @@
+package outputs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/cannon"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrUnknownPrestate is returned when the agreed output root a cannon sub-game starts from
+// is not the output root of the trusted rollup node, so the L2 block it commits to is unknown.
+var ErrUnknownPrestate = errors.New("agreed output root does not match the rollup node")
+
+// SplitTraces is a [types.TraceSelector] for games bisecting over L2 output roots down to the split depth.
+// Each claim at the split depth commits to the output root of a single block,
+// and the claims below it play the cannon trace of that block,
+// from the agreed output root claimed before it to the disputed output root claimed at it.
+type SplitTraces struct {
+	logger     log.Logger
+	outputs    *OutputTraceProvider
+	rollup     OutputRollupClient
+	l1Head     common.Hash
+	splitDepth int
+	gameDepth  int
+
+	// newCannonTrace creates the cannon trace for the local inputs of a single block.
+	newCannonTrace func(inputs cannon.LocalGameInputs) types.TraceProvider
+
+	lock         sync.Mutex
+	cannonTraces map[cannonTraceKey]types.TraceProvider
+}
+
+// cannonTraceKey identifies the cannon sub-game of a disputed block by the output roots claimed around it.
+type cannonTraceKey struct {
+	index uint64
+	pre   common.Hash
+	post  common.Hash
+}
+
+// NewSplitTraces creates the [SplitTraces] for the game at cfg.GameAddress.
+// The output roots disputed are those of the 2^cfg.SplitDepth blocks up to the L2 block number of the game.
+func NewSplitTraces(ctx context.Context, logger log.Logger, cfg *config.Config, l1Client cannon.L1Source, rollup OutputRollupClient) (*SplitTraces, error) {
+	gameCaller, err := bindings.NewFaultDisputeGameCaller(cfg.GameAddress, l1Client)
+	if err != nil {
+		return nil, fmt.Errorf("create caller for game %v: %w", cfg.GameAddress, err)
+	}
+	l2BlockNumber, err := gameCaller.L2BlockNumber(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("fetch L2 block number: %w", err)
+	}
+	prestateBlock, err := prestateBlockNumber(l2BlockNumber, cfg.SplitDepth)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := NewTraceProvider(ctx, rollup, prestateBlock, uint64(cfg.SplitDepth))
+	if err != nil {
+		return nil, err
+	}
+	prestate, err := cannon.LoadPrestate(ctx, cfg, gameCaller)
+	if err != nil {
+		return nil, err
+	}
+	l1Head, err := cannon.FetchL1Head(ctx, gameCaller, l1Client)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Playing output root bisection", "prestateBlock", prestateBlock, "l2BlockNumber", l2BlockNumber, "l1Head", l1Head)
+	newCannonTrace := func(inputs cannon.LocalGameInputs) types.TraceProvider {
+		return cannon.NewTraceProviderForInputs(logger, cfg, prestate, inputs)
+	}
+	return newSplitTraces(logger, outputs, rollup, l1Head, cfg.SplitDepth, cfg.GameDepth, newCannonTrace), nil
+}
+
+func newSplitTraces(logger log.Logger, outputs *OutputTraceProvider, rollup OutputRollupClient, l1Head common.Hash,
+	splitDepth int, gameDepth int, newCannonTrace func(inputs cannon.LocalGameInputs) types.TraceProvider) *SplitTraces {
+	return &SplitTraces{
+		logger:         logger,
+		outputs:        outputs,
+		rollup:         rollup,
+		l1Head:         l1Head,
+		splitDepth:     splitDepth,
+		gameDepth:      gameDepth,
+		newCannonTrace: newCannonTrace,
+		cannonTraces:   make(map[cannonTraceKey]types.TraceProvider),
+	}
+}
+
+// prestateBlockNumber returns the L2 block whose output root is agreed by both sides,
+// 2^splitDepth blocks before the L2 block number of the game.
+func prestateBlockNumber(l2BlockNumber *big.Int, splitDepth int) (uint64, error) {
+	disputed := new(big.Int).Lsh(big.NewInt(1), uint(splitDepth))
+	if !l2BlockNumber.IsUint64() || l2BlockNumber.Cmp(disputed) < 0 {
+		return 0, fmt.Errorf("L2 block number %v is too low to dispute %v blocks", l2BlockNumber, disputed)
+	}
+	return new(big.Int).Sub(l2BlockNumber, disputed).Uint64(), nil
+}
+
+func (s *SplitTraces) TraceAt(game types.Game, claim types.Claim) (types.TraceProvider, uint64, error) {
+	if claim.Depth() <= s.splitDepth {
+		return s.outputs, claim.TraceIndex(s.splitDepth), nil
+	}
+	ancestor := claim.AncestorAtDepth(s.splitDepth)
+	i := ancestor.TraceIndex(s.splitDepth)
+	pre, post, err := s.outputClaims(game, claim, i)
+	if err != nil {
+		return nil, 0, err
+	}
+	trace, err := s.cannonTrace(i, pre, post)
+	if err != nil {
+		return nil, 0, err
+	}
+	relative := claim.RelativeToAncestorAtDepth(s.splitDepth)
+	return trace, relative.TraceIndex(s.gameDepth - s.splitDepth), nil
+}
+
+// outputClaims returns the output roots claimed in the ancestry of the claim for the block at index i of the output trace
+// and the block before it, the absolute prestate of the output trace being the agreed output root before index 0.
+func (s *SplitTraces) outputClaims(game types.Game, claim types.Claim, i uint64) (common.Hash, common.Hash, error) {
+	var pre, post *common.Hash
+	if i == 0 {
+		prestate := common.BytesToHash(s.outputs.AbsolutePreState())
+		pre = &prestate
+	}
+	for ancestor, err := game.GetParent(claim); pre == nil || post == nil; ancestor, err = game.GetParent(ancestor) {
+		if err != nil {
+			// Reached the root claim
+			break
+		}
+		if ancestor.Depth() > s.splitDepth {
+			continue
+		}
+		value := ancestor.Value
+		switch ancestor.TraceIndex(s.splitDepth) {
+		case i:
+			if post == nil {
+				post = &value
+			}
+		case i - 1:
+			if pre == nil {
+				pre = &value
+			}
+		}
+	}
+	if pre == nil {
+		return common.Hash{}, common.Hash{}, fmt.Errorf("no agreed output root claimed before index %v at %v", i, claim.Position)
+	}
+	if post == nil {
+		return common.Hash{}, common.Hash{}, fmt.Errorf("no disputed output root claimed at index %v at %v", i, claim.Position)
+	}
+	return *pre, *post, nil
+}
+
+// cannonTrace returns the cannon trace of the block of the output root at index i of the output trace,
+// from the agreed output root pre claimed before it to the disputed output root post claimed at it.
+func (s *SplitTraces) cannonTrace(i uint64, pre common.Hash, post common.Hash) (types.TraceProvider, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	key := cannonTraceKey{index: i, pre: pre, post: post}
+	if trace, ok := s.cannonTraces[key]; ok {
+		return trace, nil
+	}
+	blockNum := s.outputs.BlockNumber(i)
+	ctx, cancel := context.WithTimeout(context.Background(), outputTimeout)
+	defer cancel()
+	agreed, err := s.rollup.OutputAtBlock(ctx, blockNum-1)
+	if err != nil {
+		return nil, fmt.Errorf("fetch agreed output at L2 block %v: %w", blockNum-1, err)
+	}
+	if common.Hash(agreed.OutputRoot) != pre {
+		return nil, fmt.Errorf("%w: claimed %v at L2 block %v", ErrUnknownPrestate, pre, blockNum-1)
+	}
+	inputs := cannon.LocalGameInputs{
+		L1Head:        s.l1Head,
+		L2Head:        agreed.BlockRef.Hash,
+		L2Claim:       post,
+		L2BlockNumber: new(big.Int).SetUint64(blockNum),
+	}
+	s.logger.Info("Playing cannon trace of disputed block", "l2Head", inputs.L2Head, "l2Claim", inputs.L2Claim,
+		"l2BlockNumber", inputs.L2BlockNumber)
+	trace := s.newCannonTrace(inputs)
+	s.cannonTraces[key] = trace
+	return trace, nil
+}
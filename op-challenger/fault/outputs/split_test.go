@@ -0,0 +1,166 @@
+package outputs
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/cannon"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	splitDepth = 2
+	gameDepth  = 5
+)
+
+var l1Head = common.HexToHash("0x1111")
+
+type stubCannonTrace struct {
+	types.TraceProvider
+	inputs cannon.LocalGameInputs
+}
+
+func setupSplitTraces(t *testing.T, rollup *stubRollupClient) (*SplitTraces, *[]cannon.LocalGameInputs) {
+	outputs, err := NewTraceProvider(context.Background(), rollup, 50, splitDepth)
+	require.NoError(t, err)
+	var created []cannon.LocalGameInputs
+	newCannonTrace := func(inputs cannon.LocalGameInputs) types.TraceProvider {
+		created = append(created, inputs)
+		return &stubCannonTrace{inputs: inputs}
+	}
+	logger := testlog.Logger(t, log.LvlInfo)
+	return newSplitTraces(logger, outputs, rollup, l1Head, splitDepth, gameDepth, newCannonTrace), &created
+}
+
+// newClaim creates a claim at pos with the given value, responding to parent.
+func newClaim(pos types.Position, value common.Hash, parent types.Claim) types.Claim {
+	return types.Claim{
+		ClaimData: types.ClaimData{Value: value, Position: pos},
+		Parent:    parent.ClaimData,
+	}
+}
+
+// setupGame creates a game over the output roots of blocks 51 to 54, with the claims made by an honest actor
+// attacking the root at the output root of block 52 and defending that to dispute the output root of block 53.
+func setupGame(t *testing.T) (types.Game, types.Claim, types.Claim) {
+	root := types.Claim{ClaimData: types.ClaimData{Value: common.Hash{0xba, 0xd5}, Position: types.NewPosition(0, 0)}}
+	game := types.NewGameState(false, root, gameDepth)
+	attack := newClaim(types.NewPosition(1, 0), outputRoot(52), root)
+	defend := newClaim(types.NewPosition(2, 2), outputRoot(53), attack)
+	require.NoError(t, game.PutAll([]types.Claim{attack, defend}))
+	return game, attack, defend
+}
+
+func TestTraceAtOutputs(t *testing.T) {
+	traces, created := setupSplitTraces(t, &stubRollupClient{latest: 100})
+	tests := []struct {
+		pos   types.Position
+		index uint64
+	}{
+		{types.NewPosition(0, 0), 3},
+		{types.NewPosition(1, 0), 1},
+		{types.NewPosition(1, 1), 3},
+		{types.NewPosition(2, 0), 0},
+		{types.NewPosition(2, 2), 2},
+	}
+	for _, test := range tests {
+		claim := types.Claim{ClaimData: types.ClaimData{Position: test.pos}}
+		trace, index, err := traces.TraceAt(nil, claim)
+		require.NoError(t, err)
+		require.Same(t, traces.outputs, trace)
+		require.Equalf(t, test.index, index, "Trace index of %v", test.pos)
+	}
+	require.Empty(t, *created, "should not create cannon traces above the split depth")
+}
+
+func TestTraceAtCannon(t *testing.T) {
+	traces, created := setupSplitTraces(t, &stubRollupClient{latest: 100})
+	game, attack, defend := setupGame(t)
+
+	// Below the claim at index 2 of the outputs, the output root of block 53 from block 52
+	move := newClaim(types.NewPosition(3, 4), common.Hash{}, defend)
+	trace, index, err := traces.TraceAt(game, move)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), index)
+	require.Equal(t, cannon.LocalGameInputs{
+		L1Head:        l1Head,
+		L2Head:        blockHash(52),
+		L2Claim:       outputRoot(53),
+		L2BlockNumber: big.NewInt(53),
+	}, trace.(*stubCannonTrace).inputs)
+
+	t.Run("ReuseTraceOfBlock", func(t *testing.T) {
+		move.Value = common.Hash{0xcc}
+		require.NoError(t, game.Put(move))
+		again, index, err := traces.TraceAt(game, newClaim(types.NewPosition(4, 9), common.Hash{}, move))
+		require.NoError(t, err)
+		require.Same(t, trace, again)
+		require.Equal(t, uint64(3), index)
+		require.Len(t, *created, 1)
+	})
+
+	t.Run("OtherBlock", func(t *testing.T) {
+		attackAgain := newClaim(types.NewPosition(2, 0), outputRoot(51), attack)
+		require.NoError(t, game.Put(attackAgain))
+		other, index, err := traces.TraceAt(game, newClaim(types.NewPosition(3, 0), common.Hash{}, attackAgain))
+		require.NoError(t, err)
+		require.NotSame(t, trace, other)
+		require.Equal(t, uint64(3), index)
+		require.Equal(t, cannon.LocalGameInputs{
+			L1Head:        l1Head,
+			L2Head:        blockHash(50),
+			L2Claim:       outputRoot(51),
+			L2BlockNumber: big.NewInt(51),
+		}, other.(*stubCannonTrace).inputs)
+	})
+
+	t.Run("DisputedClaimIncorrect", func(t *testing.T) {
+		incorrect := newClaim(types.NewPosition(2, 2), common.Hash{0xdd}, attack)
+		require.NoError(t, game.Put(incorrect))
+		other, index, err := traces.TraceAt(game, newClaim(types.NewPosition(3, 4), common.Hash{}, incorrect))
+		require.NoError(t, err)
+		require.NotSame(t, trace, other)
+		require.Equal(t, uint64(3), index)
+		require.Equal(t, cannon.LocalGameInputs{
+			L1Head:        l1Head,
+			L2Head:        blockHash(52),
+			L2Claim:       incorrect.Value,
+			L2BlockNumber: big.NewInt(53),
+		}, other.(*stubCannonTrace).inputs)
+	})
+
+	t.Run("AgreedClaimIncorrect", func(t *testing.T) {
+		incorrect := newClaim(types.NewPosition(1, 0), common.Hash{0xee}, game.Claims()[0])
+		require.NoError(t, game.Put(incorrect))
+		disputed := newClaim(types.NewPosition(2, 2), common.Hash{0xff}, incorrect)
+		require.NoError(t, game.Put(disputed))
+		_, _, err := traces.TraceAt(game, newClaim(types.NewPosition(3, 4), common.Hash{}, disputed))
+		require.ErrorIs(t, err, ErrUnknownPrestate)
+	})
+}
+
+func TestTraceAtCannonOutputUnavailable(t *testing.T) {
+	traces, created := setupSplitTraces(t, &stubRollupClient{latest: 51})
+	game, _, defend := setupGame(t)
+	_, _, err := traces.TraceAt(game, newClaim(types.NewPosition(3, 4), common.Hash{}, defend))
+	require.ErrorIs(t, err, errNoOutput)
+	require.Empty(t, *created)
+}
+
+func TestPrestateBlockNumber(t *testing.T) {
+	block, err := prestateBlockNumber(big.NewInt(100), 3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(92), block)
+
+	block, err = prestateBlockNumber(big.NewInt(8), 3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), block)
+
+	_, err = prestateBlockNumber(big.NewInt(7), 3)
+	require.Error(t, err)
+}
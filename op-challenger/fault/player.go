@@ -0,0 +1,167 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/dryrun"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/alphabet"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/cannon"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/outputs"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/zkwasm"
+	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
+	opclient "github.com/ethereum-optimism/optimism/op-service/client"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// GamePlayer plays the fault dispute game at cfg.GameAddress.
+type GamePlayer struct {
+	agent                   Actor
+	agreeWithProposedOutput bool
+	caller                  GameInfo
+	logger                  log.Logger
+}
+
+// NewGamePlayer creates a [GamePlayer] for the game at cfg.GameAddress.
+// Transactions are sent with txMgr, which may be shared between players,
+// or only simulated and recorded to actions in dry-run mode, if actions is not nil.
+// The spend on the game is tracked and bounded by budget, which may be shared between players.
+// If rollup is not nil, the side to play is decided by checking the root claim against its output root,
+// otherwise cfg.AgreeWithProposedOutput is used.
+func NewGamePlayer(ctx context.Context, logger log.Logger, cfg *config.Config, m metrics.Metricer, txMgr txmgr.TxManager, client *ethclient.Client, rollup OutputSource, budget *Budget, actions *dryrun.Log) (*GamePlayer, error) {
+	contract, err := bindings.NewFaultDisputeGameCaller(cfg.GameAddress, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind the fault dispute game contract: %w", err)
+	}
+
+	agree := cfg.AgreeWithProposedOutput
+	if rollup != nil {
+		agree, err = agreeWithProposedOutput(ctx, logger, contract, rollup, m)
+		if err != nil {
+			return nil, fmt.Errorf("skipping game, unable to check root claim: %w", err)
+		}
+	}
+
+	loader := NewCachingLoader(logger, contract, client, cfg.GameDepth, claimCachePath(cfg))
+	var agent Actor
+	if cfg.TraceType == config.TraceTypeZkWasm {
+		agent, err = newZkWasmAgent(ctx, logger, cfg, txMgr, client, loader, agree, budget, actions)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		agent, err = newBisectionAgent(ctx, logger, cfg, txMgr, client, rollup, loader, agree, budget, actions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	caller, err := NewFaultCallerFromBindings(cfg.GameAddress, client, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind the fault contract: %w", err)
+	}
+
+	return &GamePlayer{
+		agent:                   agent,
+		agreeWithProposedOutput: agree,
+		caller:                  caller,
+		logger:                  logger,
+	}, nil
+}
+
+// newBisectionAgent creates the [Agent] playing the game by bisection and steps over the traces of cfg.TraceType.
+func newBisectionAgent(ctx context.Context, logger log.Logger, cfg *config.Config, txMgr txmgr.TxManager, client *ethclient.Client,
+	rollup OutputSource, loader Loader, agree bool, budget *Budget, actions *dryrun.Log) (*Agent, error) {
+	responder, err := NewFaultResponder(logger, txMgr, cfg.GameAddress, budget, actions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the responder: %w", err)
+	}
+
+	contract, err := bindings.NewFaultDisputeGameCaller(cfg.GameAddress, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind the fault dispute game contract: %w", err)
+	}
+	gameDuration, err := contract.GAMEDURATION(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the game duration: %w", err)
+	}
+
+	var traces types.TraceSelector
+	switch cfg.TraceType {
+	case config.TraceTypeCannon:
+		trace, err := cannon.NewCannonTraceProvider(ctx, logger, cfg, client)
+		if err != nil {
+			return nil, fmt.Errorf("create cannon trace provider: %w", err)
+		}
+		traces = types.NewSingleTrace(trace, cfg.GameDepth)
+	case config.TraceTypeOutputCannon:
+		if rollup == nil {
+			return nil, errors.New("output cannon trace type requires a rollup node")
+		}
+		traces, err = outputs.NewSplitTraces(ctx, logger, cfg, client, rollup)
+		if err != nil {
+			return nil, fmt.Errorf("create output cannon traces: %w", err)
+		}
+	case config.TraceTypeAlphabet:
+		trace := alphabet.NewAlphabetProvider(cfg.AlphabetTrace, uint64(cfg.GameDepth))
+		traces = types.NewSingleTrace(trace, cfg.GameDepth)
+	default:
+		return nil, fmt.Errorf("unsupported trace type: %v", cfg.TraceType)
+	}
+
+	return NewAgent(loader, cfg.GameDepth, time.Duration(gameDuration)*time.Second, traces, responder, agree, logger), nil
+}
+
+// newZkWasmAgent creates the [ZkAgent] resolving the game with a zkWasm proof of the op-program run.
+func newZkWasmAgent(ctx context.Context, logger log.Logger, cfg *config.Config, txMgr txmgr.TxManager, client *ethclient.Client,
+	loader Loader, agree bool, budget *Budget, actions *dryrun.Log) (*ZkAgent, error) {
+	responder, err := NewZkWasmResponder(logger, txMgr, cfg.GameAddress, cfg.ZkWasmVerifier, budget, actions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the responder: %w", err)
+	}
+	prover, err := zkwasm.NewZkWasmProofProvider(ctx, logger, cfg, client)
+	if err != nil {
+		return nil, fmt.Errorf("create zkwasm proof provider: %w", err)
+	}
+	return NewZkAgent(loader, prover, responder, agree, logger), nil
+}
+
+// claimCachePath returns the file to persist the claims of the game to, or empty if cfg.Datadir is not set.
+func claimCachePath(cfg *config.Config) string {
+	if cfg.Datadir == "" {
+		return ""
+	}
+	return filepath.Join(cfg.Datadir, "claims", cfg.GameAddress.Hex()+".json")
+}
+
+// DialRollupClient dials the rollup node to check root claims against, or returns nil if cfg.RollupRpc is not set.
+func DialRollupClient(ctx context.Context, cfg *config.Config) (OutputSource, error) {
+	if cfg.RollupRpc == "" {
+		return nil, nil
+	}
+	rollupClient, err := opclient.DialRollupClientWithTimeout(ctx, cfg.RollupRpc, opclient.DefaultDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial rollup node: %w", err)
+	}
+	return rollupClient, nil
+}
+
+// ProgressGame attempts to progress the game by performing moves, steps or resolving.
+// Returns true if the game is complete or false if it needs to be monitored further.
+func (g *GamePlayer) ProgressGame(ctx context.Context) bool {
+	return progressGame(ctx, g.logger, g.agreeWithProposedOutput, g.agent, g.caller)
+}
+
+// MonitorGame progresses the game until it is complete.
+func (g *GamePlayer) MonitorGame(ctx context.Context) error {
+	return MonitorGame(ctx, g.logger, g.agreeWithProposedOutput, g.agent, g.caller)
+}
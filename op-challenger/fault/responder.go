@@ -3,8 +3,10 @@ package fault
 import (
 	"context"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-challenger/dryrun"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 
@@ -15,6 +17,10 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// zkWasmVerifierABI is the interface expected of the zkWasm verifier contract.
+// It verifies the proof of the op-program run for the local inputs of the game, and resolves the game by its result.
+const zkWasmVerifierABI = `[{"type":"function","name":"prove","inputs":[{"name":"_game","type":"address"},{"name":"_publicInputs","type":"bytes"},{"name":"_proof","type":"bytes"}],"outputs":[],"stateMutability":"nonpayable"}]`
+
 // faultResponder implements the [Responder] interface to send onchain transactions.
 type faultResponder struct {
 	log log.Logger
@@ -23,10 +29,19 @@ type faultResponder struct {
 
 	fdgAddr common.Address
 	fdgAbi  *abi.ABI
+
+	// budget tracks the spend on the game, and bounds the moves, steps and proofs sent for it.
+	budget *Budget
+
+	// actions, if not nil, enables dry-run mode: transactions are simulated and recorded instead of sent.
+	actions *dryrun.Log
+	// abis are the contract ABIs of the transactions sent, to describe the actions recorded in dry-run mode.
+	abis []*abi.ABI
 }
 
-// NewFaultResponder returns a new [faultResponder].
-func NewFaultResponder(logger log.Logger, txManagr txmgr.TxManager, fdgAddr common.Address) (*faultResponder, error) {
+// NewFaultResponder returns a new [faultResponder], spending within budget.
+// If actions is not nil, transactions are only simulated and recorded to it.
+func NewFaultResponder(logger log.Logger, txManagr txmgr.TxManager, fdgAddr common.Address, budget *Budget, actions *dryrun.Log) (*faultResponder, error) {
 	fdgAbi, err := bindings.FaultDisputeGameMetaData.GetAbi()
 	if err != nil {
 		return nil, err
@@ -36,6 +51,9 @@ func NewFaultResponder(logger log.Logger, txManagr txmgr.TxManager, fdgAddr comm
 		txMgr:   txManagr,
 		fdgAddr: fdgAddr,
 		fdgAbi:  fdgAbi,
+		budget:  budget,
+		actions: actions,
+		abis:    []*abi.ABI{fdgAbi},
 	}, nil
 }
 
@@ -104,7 +122,11 @@ func (r *faultResponder) Resolve(ctx context.Context) error {
 }
 
 // Respond takes a [Claim] and executes the response action.
+// Returns [ErrBudgetExceeded] if the budget of the game has been spent.
 func (r *faultResponder) Respond(ctx context.Context, response types.Claim) error {
+	if err := r.budget.Check(r.fdgAddr); err != nil {
+		return err
+	}
 	txData, err := r.BuildTx(ctx, response)
 	if err != nil {
 		return err
@@ -112,17 +134,27 @@ func (r *faultResponder) Respond(ctx context.Context, response types.Claim) erro
 	return r.sendTxAndWait(ctx, txData)
 }
 
-// sendTxAndWait sends a transaction through the [txmgr] and waits for a receipt.
-// This sets the tx GasLimit to 0, performing gas estimation online through the [txmgr].
+// sendTxAndWait sends a transaction to the game through the [txmgr] and waits for a receipt.
 func (r *faultResponder) sendTxAndWait(ctx context.Context, txData []byte) error {
+	return r.sendTxToAndWait(ctx, r.fdgAddr, txData)
+}
+
+// sendTxToAndWait sends a transaction through the [txmgr] and waits for a receipt.
+// This sets the tx GasLimit to 0, performing gas estimation online through the [txmgr].
+func (r *faultResponder) sendTxToAndWait(ctx context.Context, to common.Address, txData []byte) error {
+	if r.actions != nil {
+		r.simulate(ctx, to, txData)
+		return nil
+	}
 	receipt, err := r.txMgr.Send(ctx, txmgr.TxCandidate{
-		To:       &r.fdgAddr,
+		To:       &to,
 		TxData:   txData,
 		GasLimit: 0,
 	})
 	if err != nil {
 		return err
 	}
+	r.recordSpend(receipt)
 	if receipt.Status == ethtypes.ReceiptStatusFailed {
 		r.log.Error("Responder tx successfully published but reverted", "tx_hash", receipt.TxHash)
 	} else {
@@ -131,6 +163,58 @@ func (r *faultResponder) sendTxAndWait(ctx context.Context, txData []byte) error
 	return nil
 }
 
+// recordSpend records the gas fee paid by the transaction of receipt to the budget of the game.
+// The game contract takes no bond, the txmgr sends no value with moves.
+func (r *faultResponder) recordSpend(receipt *ethtypes.Receipt) {
+	if receipt.EffectiveGasPrice == nil {
+		return
+	}
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	if err := r.budget.Record(r.fdgAddr, cost); err != nil {
+		r.log.Error("Failed to persist transaction spend", "tx_hash", receipt.TxHash, "err", err)
+	}
+	spent, total := r.budget.Spent(r.fdgAddr)
+	r.log.Info("Recorded transaction spend", "tx_hash", receipt.TxHash, "cost", cost, "game_spent", spent, "total_spent", total)
+}
+
+// simulate executes the transaction with eth_call instead of sending it,
+// and records it to the planned actions with whether it would revert.
+func (r *faultResponder) simulate(ctx context.Context, to common.Address, txData []byte) {
+	_, err := r.txMgr.Call(ctx, ethereum.CallMsg{
+		From: r.txMgr.From(),
+		To:   &to,
+		Data: txData,
+	}, nil)
+	action := dryrun.Action{
+		Game:    r.fdgAddr,
+		To:      to,
+		Method:  r.methodName(txData),
+		Data:    txData,
+		Reverts: err != nil,
+	}
+	if err != nil {
+		action.Error = err.Error()
+	}
+	log := r.log.New("to", to, "method", action.Method, "reverts", action.Reverts)
+	if r.actions.Record(action) {
+		log.Info("Dry run, planned transaction not sent", "err", err)
+	} else {
+		log.Debug("Dry run, transaction already planned", "err", err)
+	}
+}
+
+// methodName returns the name of the contract method called by txData.
+func (r *faultResponder) methodName(txData []byte) string {
+	if len(txData) >= 4 {
+		for _, contractAbi := range r.abis {
+			if method, err := contractAbi.MethodById(txData[:4]); err == nil {
+				return method.Name
+			}
+		}
+	}
+	return "unknown"
+}
+
 // buildStepTxData creates the transaction data for the step function.
 func (r *faultResponder) buildStepTxData(stepData types.StepCallData) ([]byte, error) {
 	return r.fdgAbi.Pack(
@@ -144,9 +228,61 @@ func (r *faultResponder) buildStepTxData(stepData types.StepCallData) ([]byte, e
 
 // Step accepts step data and executes the step on the fault dispute game contract.
 func (r *faultResponder) Step(ctx context.Context, stepData types.StepCallData) error {
+	if err := r.budget.Check(r.fdgAddr); err != nil {
+		return err
+	}
 	txData, err := r.buildStepTxData(stepData)
 	if err != nil {
 		return err
 	}
 	return r.sendTxAndWait(ctx, txData)
 }
+
+// zkWasmResponder extends the [faultResponder] to submit zkWasm proofs of the game to the verifier contract.
+type zkWasmResponder struct {
+	*faultResponder
+
+	verifierAddr common.Address
+	verifierAbi  *abi.ABI
+}
+
+// NewZkWasmResponder returns a new [zkWasmResponder].
+// If actions is not nil, transactions are only simulated and recorded to it.
+func NewZkWasmResponder(logger log.Logger, txManagr txmgr.TxManager, fdgAddr common.Address, verifierAddr common.Address, budget *Budget, actions *dryrun.Log) (*zkWasmResponder, error) {
+	responder, err := NewFaultResponder(logger, txManagr, fdgAddr, budget, actions)
+	if err != nil {
+		return nil, err
+	}
+	verifierAbi, err := abi.JSON(strings.NewReader(zkWasmVerifierABI))
+	if err != nil {
+		return nil, err
+	}
+	responder.abis = append(responder.abis, &verifierAbi)
+	return &zkWasmResponder{
+		faultResponder: responder,
+		verifierAddr:   verifierAddr,
+		verifierAbi:    &verifierAbi,
+	}, nil
+}
+
+// buildProveTxData creates the transaction data for the prove function of the verifier.
+func (r *zkWasmResponder) buildProveTxData(proof types.ZkProofCallData) ([]byte, error) {
+	return r.verifierAbi.Pack(
+		"prove",
+		r.fdgAddr,
+		proof.PublicInputs,
+		proof.Proof,
+	)
+}
+
+// Prove submits the zkWasm proof of the game to the verifier contract.
+func (r *zkWasmResponder) Prove(ctx context.Context, proof types.ZkProofCallData) error {
+	if err := r.budget.Check(r.fdgAddr); err != nil {
+		return err
+	}
+	txData, err := r.buildProveTxData(proof)
+	if err != nil {
+		return err
+	}
+	return r.sendTxToAndWait(ctx, r.verifierAddr, txData)
+}
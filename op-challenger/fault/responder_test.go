@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-challenger/dryrun"
 	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
@@ -20,13 +21,20 @@ import (
 )
 
 var (
-	mockFdgAddress = common.HexToAddress("0x1234")
-	mockSendError  = errors.New("mock send error")
+	mockFdgAddress      = common.HexToAddress("0x1234")
+	mockVerifierAddress = common.HexToAddress("0x5678")
+	mockSendError       = errors.New("mock send error")
+)
+
+const (
+	mockGasUsed  = 50_000
+	mockGasPrice = 10
 )
 
 type mockTxManager struct {
 	from      common.Address
 	sends     int
+	sentTo    *common.Address
 	calls     int
 	sendFails bool
 }
@@ -36,11 +44,15 @@ func (m *mockTxManager) Send(ctx context.Context, candidate txmgr.TxCandidate) (
 		return nil, mockSendError
 	}
 	m.sends++
-	return ethtypes.NewReceipt(
+	m.sentTo = candidate.To
+	receipt := ethtypes.NewReceipt(
 		[]byte{},
 		false,
 		0,
-	), nil
+	)
+	receipt.GasUsed = mockGasUsed
+	receipt.EffectiveGasPrice = big.NewInt(mockGasPrice)
+	return receipt, nil
 }
 
 func (m *mockTxManager) Call(_ context.Context, _ ethereum.CallMsg, _ *big.Int) ([]byte, error) {
@@ -63,7 +75,7 @@ func newTestFaultResponder(t *testing.T, sendFails bool) (*faultResponder, *mock
 	log := testlog.Logger(t, log.LvlError)
 	mockTxMgr := &mockTxManager{}
 	mockTxMgr.sendFails = sendFails
-	responder, err := NewFaultResponder(log, mockTxMgr, mockFdgAddress)
+	responder, err := NewFaultResponder(log, mockTxMgr, mockFdgAddress, NewBudget(nil, nil), nil)
 	require.NoError(t, err)
 	return responder, mockTxMgr
 }
@@ -205,3 +217,111 @@ func TestResponder_BuildTx_Defend(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expected, tx)
 }
+
+// TestZkWasmResponder_Prove tests the [zkWasmResponder.Prove] method
+// sends the proof of the game to the verifier contract.
+func TestZkWasmResponder_Prove(t *testing.T) {
+	mockTxMgr := &mockTxManager{}
+	responder, err := NewZkWasmResponder(testlog.Logger(t, log.LvlError), mockTxMgr, mockFdgAddress, mockVerifierAddress, NewBudget(nil, nil), nil)
+	require.NoError(t, err)
+	proof := types.ZkProofCallData{PublicInputs: []byte{0x01, 0x02}, Proof: []byte{0x03}}
+
+	tx, err := responder.buildProveTxData(proof)
+	require.NoError(t, err)
+	args, err := responder.verifierAbi.Methods["prove"].Inputs.Unpack(tx[4:])
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{mockFdgAddress, proof.PublicInputs, proof.Proof}, args)
+
+	require.NoError(t, responder.Prove(context.Background(), proof))
+	require.Equal(t, 1, mockTxMgr.sends)
+	require.Equal(t, mockVerifierAddress, *mockTxMgr.sentTo)
+
+	// The responder still sends the game moves to the game itself
+	require.NoError(t, responder.Resolve(context.Background()))
+	require.Equal(t, mockFdgAddress, *mockTxMgr.sentTo)
+}
+
+// TestResponder_DryRun tests a dry-run responder records the transactions
+// simulated with the [txmgr.Call] method instead of sending them.
+func TestResponder_DryRun(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		actions := dryrun.NewLog()
+		mockTxMgr := &mockTxManager{}
+		responder, err := NewFaultResponder(testlog.Logger(t, log.LvlError), mockTxMgr, mockFdgAddress, NewBudget(nil, nil), actions)
+		require.NoError(t, err)
+
+		require.NoError(t, responder.Resolve(context.Background()))
+		require.Equal(t, 0, mockTxMgr.sends)
+		require.Equal(t, 1, mockTxMgr.calls)
+		recorded := actions.Actions(mockFdgAddress)
+		require.Len(t, recorded, 1)
+		require.Equal(t, mockFdgAddress, recorded[0].To)
+		require.Equal(t, "resolve", recorded[0].Method)
+		require.False(t, recorded[0].Reverts)
+
+		// Repeating the transaction does not record a new action
+		require.NoError(t, responder.Resolve(context.Background()))
+		require.Len(t, actions.Actions(mockFdgAddress), 1)
+	})
+
+	t.Run("Reverts", func(t *testing.T) {
+		actions := dryrun.NewLog()
+		mockTxMgr := &mockTxManager{sendFails: true}
+		responder, err := NewFaultResponder(testlog.Logger(t, log.LvlError), mockTxMgr, mockFdgAddress, NewBudget(nil, nil), actions)
+		require.NoError(t, err)
+
+		require.NoError(t, responder.Resolve(context.Background()))
+		recorded := actions.Actions(mockFdgAddress)
+		require.Len(t, recorded, 1)
+		require.True(t, recorded[0].Reverts)
+		require.Equal(t, mockSendError.Error(), recorded[0].Error)
+	})
+
+	t.Run("Prove", func(t *testing.T) {
+		actions := dryrun.NewLog()
+		mockTxMgr := &mockTxManager{}
+		responder, err := NewZkWasmResponder(testlog.Logger(t, log.LvlError), mockTxMgr, mockFdgAddress, mockVerifierAddress, NewBudget(nil, nil), actions)
+		require.NoError(t, err)
+
+		require.NoError(t, responder.Prove(context.Background(), types.ZkProofCallData{PublicInputs: []byte{0x01}, Proof: []byte{0x02}}))
+		require.Equal(t, 0, mockTxMgr.sends)
+		recorded := actions.Actions(mockFdgAddress)
+		require.Len(t, recorded, 1)
+		require.Equal(t, mockVerifierAddress, recorded[0].To)
+		require.Equal(t, "prove", recorded[0].Method)
+	})
+}
+
+// TestResponder_Budget tests the responder records the gas spent on the game,
+// and stops sending moves and steps once the budget of the game is spent.
+func TestResponder_Budget(t *testing.T) {
+	mockTxMgr := &mockTxManager{}
+	budget := NewBudget(big.NewInt(mockGasUsed*mockGasPrice), nil)
+	responder, err := NewFaultResponder(testlog.Logger(t, log.LvlError), mockTxMgr, mockFdgAddress, budget, nil)
+	require.NoError(t, err)
+	move := types.Claim{
+		ClaimData: types.ClaimData{
+			Value:    common.Hash{0x01},
+			Position: types.NewPositionFromGIndex(2),
+		},
+		Parent: types.ClaimData{
+			Value:    common.Hash{0x02},
+			Position: types.NewPositionFromGIndex(1),
+		},
+		ContractIndex:       0,
+		ParentContractIndex: 0,
+	}
+
+	require.NoError(t, responder.Respond(context.Background(), move))
+	spent, total := budget.Spent(mockFdgAddress)
+	require.Equal(t, big.NewInt(mockGasUsed*mockGasPrice), spent)
+	require.Equal(t, spent, total)
+
+	require.ErrorIs(t, responder.Respond(context.Background(), move), ErrBudgetExceeded)
+	require.ErrorIs(t, responder.Step(context.Background(), types.StepCallData{}), ErrBudgetExceeded)
+	require.Equal(t, 1, mockTxMgr.sends)
+
+	// The game can still be resolved
+	require.NoError(t, responder.Resolve(context.Background()))
+	require.Equal(t, 2, mockTxMgr.sends)
+}
@@ -0,0 +1,57 @@
+package fault
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// RootClaimSource provides the root claim of a game and the L2 block it claims the output root of.
+type RootClaimSource interface {
+	RootClaim(opts *bind.CallOpts) ([32]byte, error)
+	L2BlockNumber(opts *bind.CallOpts) (*big.Int, error)
+}
+
+// OutputSource provides the output roots of a trusted rollup node.
+type OutputSource interface {
+	OutputAtBlock(ctx context.Context, blockNum uint64) (*eth.OutputResponse, error)
+}
+
+// agreeWithProposedOutput checks the root claim of the game against the output root of the rollup node,
+// and returns whether the challenger agrees with the proposed output, i.e. disagrees with the root claim.
+// An error is returned if the output root is unavailable, e.g. the rollup node has not reached the block yet,
+// in which case the game should be skipped for now.
+func agreeWithProposedOutput(ctx context.Context, logger log.Logger, game RootClaimSource, rollup OutputSource, m metrics.Metricer) (bool, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	rootClaim, err := game.RootClaim(opts)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch root claim: %w", err)
+	}
+	l2BlockNumber, err := game.L2BlockNumber(opts)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch L2 block number: %w", err)
+	}
+	if !l2BlockNumber.IsUint64() {
+		return false, fmt.Errorf("invalid L2 block number %v", l2BlockNumber)
+	}
+	output, err := rollup.OutputAtBlock(ctx, l2BlockNumber.Uint64())
+	if err != nil {
+		m.RecordGameSkipped()
+		return false, fmt.Errorf("failed to fetch output root at L2 block %v: %w", l2BlockNumber, err)
+	}
+	rootClaimCorrect := common.Hash(output.OutputRoot) == rootClaim
+	m.RecordGameAgreement(rootClaimCorrect)
+	if rootClaimCorrect {
+		logger.Info("Root claim matches the output root", "l2BlockNumber", l2BlockNumber, "outputRoot", output.OutputRoot)
+	} else {
+		logger.Warn("Root claim does not match the output root", "l2BlockNumber", l2BlockNumber,
+			"rootClaim", common.Hash(rootClaim), "outputRoot", output.OutputRoot)
+	}
+	return !rootClaimCorrect, nil
+}
@@ -0,0 +1,103 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgreeWithProposedOutput(t *testing.T) {
+	rootClaim := common.Hash{0xaa}
+	game := &stubRootClaimSource{rootClaim: rootClaim, l2BlockNumber: big.NewInt(42)}
+
+	t.Run("RootClaimCorrect", func(t *testing.T) {
+		m := &stubAgreementMetrics{}
+		rollup := &stubOutputSource{outputs: map[uint64]common.Hash{42: rootClaim}}
+		agree, err := agreeWithProposedOutput(context.Background(), testlog.Logger(t, log.LvlInfo), game, rollup, m)
+		require.NoError(t, err)
+		require.False(t, agree, "should not agree with the proposed output when the root claim is correct")
+		require.Equal(t, 1, m.agreed)
+	})
+
+	t.Run("RootClaimIncorrect", func(t *testing.T) {
+		m := &stubAgreementMetrics{}
+		rollup := &stubOutputSource{outputs: map[uint64]common.Hash{42: {0xbb}}}
+		agree, err := agreeWithProposedOutput(context.Background(), testlog.Logger(t, log.LvlInfo), game, rollup, m)
+		require.NoError(t, err)
+		require.True(t, agree, "should agree with the proposed output when the root claim is incorrect")
+		require.Equal(t, 1, m.disagreed)
+	})
+
+	t.Run("OutputUnavailable", func(t *testing.T) {
+		m := &stubAgreementMetrics{}
+		rollup := &stubOutputSource{}
+		_, err := agreeWithProposedOutput(context.Background(), testlog.Logger(t, log.LvlInfo), game, rollup, m)
+		require.ErrorIs(t, err, errNoOutput)
+		require.Equal(t, 1, m.skipped)
+		require.Zero(t, m.agreed+m.disagreed)
+	})
+
+	t.Run("GameError", func(t *testing.T) {
+		m := &stubAgreementMetrics{}
+		gameErr := errors.New("boom")
+		rollup := &stubOutputSource{outputs: map[uint64]common.Hash{42: rootClaim}}
+		_, err := agreeWithProposedOutput(context.Background(), testlog.Logger(t, log.LvlInfo), &stubRootClaimSource{err: gameErr}, rollup, m)
+		require.ErrorIs(t, err, gameErr)
+	})
+}
+
+var errNoOutput = errors.New("no output")
+
+type stubRootClaimSource struct {
+	rootClaim     common.Hash
+	l2BlockNumber *big.Int
+	err           error
+}
+
+func (s *stubRootClaimSource) RootClaim(_ *bind.CallOpts) ([32]byte, error) {
+	return s.rootClaim, s.err
+}
+
+func (s *stubRootClaimSource) L2BlockNumber(_ *bind.CallOpts) (*big.Int, error) {
+	return s.l2BlockNumber, s.err
+}
+
+type stubOutputSource struct {
+	outputs map[uint64]common.Hash
+}
+
+func (s *stubOutputSource) OutputAtBlock(_ context.Context, blockNum uint64) (*eth.OutputResponse, error) {
+	output, ok := s.outputs[blockNum]
+	if !ok {
+		return nil, errNoOutput
+	}
+	return &eth.OutputResponse{OutputRoot: eth.Bytes32(output)}, nil
+}
+
+type stubAgreementMetrics struct {
+	metrics.Metricer
+	agreed    int
+	disagreed int
+	skipped   int
+}
+
+func (m *stubAgreementMetrics) RecordGameAgreement(agree bool) {
+	if agree {
+		m.agreed++
+	} else {
+		m.disagreed++
+	}
+}
+
+func (m *stubAgreementMetrics) RecordGameSkipped() {
+	m.skipped++
+}
@@ -15,33 +15,38 @@ var (
 
 // Solver uses a [TraceProvider] to determine the moves to make in a dispute game.
 type Solver struct {
-	trace     types.TraceProvider
+	traces    types.TraceSelector
 	gameDepth int
 }
 
-// NewSolver creates a new [Solver] using the provided [TraceProvider].
+// NewSolver creates a new [Solver] using the provided [TraceProvider] for the full depth of the game.
 func NewSolver(gameDepth int, traceProvider types.TraceProvider) *Solver {
+	return NewSolverForTraces(gameDepth, types.NewSingleTrace(traceProvider, gameDepth))
+}
+
+// NewSolverForTraces creates a new [Solver] using the trace selected by the provided [TraceSelector] for each claim.
+func NewSolverForTraces(gameDepth int, traces types.TraceSelector) *Solver {
 	return &Solver{
-		traceProvider,
+		traces,
 		gameDepth,
 	}
 }
 
 // NextMove returns the next move to make given the current state of the game.
-func (s *Solver) NextMove(claim types.Claim, agreeWithClaimLevel bool) (*types.Claim, error) {
+func (s *Solver) NextMove(game types.Game, claim types.Claim, agreeWithClaimLevel bool) (*types.Claim, error) {
 	if agreeWithClaimLevel {
 		return nil, nil
 	}
 
 	// Special case of the root claim
 	if claim.IsRoot() {
-		return s.handleRoot(claim)
+		return s.handleRoot(game, claim)
 	}
-	return s.handleMiddle(claim)
+	return s.handleMiddle(game, claim)
 }
 
-func (s *Solver) handleRoot(claim types.Claim) (*types.Claim, error) {
-	agree, err := s.agreeWithClaim(claim.ClaimData)
+func (s *Solver) handleRoot(game types.Game, claim types.Claim) (*types.Claim, error) {
+	agree, err := s.agreeWithClaim(game, claim)
 	if err != nil {
 		return nil, err
 	}
@@ -49,14 +54,14 @@ func (s *Solver) handleRoot(claim types.Claim) (*types.Claim, error) {
 	// Note: We always disagree with the claim level at this point,
 	// so if we agree with claim maybe we should also attack?
 	if !agree {
-		return s.attack(claim)
+		return s.attack(game, claim)
 	} else {
 		return nil, nil
 	}
 }
 
-func (s *Solver) handleMiddle(claim types.Claim) (*types.Claim, error) {
-	claimCorrect, err := s.agreeWithClaim(claim.ClaimData)
+func (s *Solver) handleMiddle(game types.Game, claim types.Claim) (*types.Claim, error) {
+	claimCorrect, err := s.agreeWithClaim(game, claim)
 	if err != nil {
 		return nil, err
 	}
@@ -64,9 +69,9 @@ func (s *Solver) handleMiddle(claim types.Claim) (*types.Claim, error) {
 		return nil, types.ErrGameDepthReached
 	}
 	if claimCorrect {
-		return s.defend(claim)
+		return s.defend(game, claim)
 	} else {
-		return s.attack(claim)
+		return s.attack(game, claim)
 	}
 }
 
@@ -79,29 +84,32 @@ type StepData struct {
 
 // AttemptStep determines what step should occur for a given leaf claim.
 // An error will be returned if the claim is not at the max depth.
-func (s *Solver) AttemptStep(claim types.Claim, agreeWithClaimLevel bool) (StepData, error) {
+func (s *Solver) AttemptStep(game types.Game, claim types.Claim, agreeWithClaimLevel bool) (StepData, error) {
 	if claim.Depth() != s.gameDepth {
 		return StepData{}, ErrStepNonLeafNode
 	}
 	if agreeWithClaimLevel {
 		return StepData{}, ErrStepAgreedClaim
 	}
-	claimCorrect, err := s.agreeWithClaim(claim.ClaimData)
+	claimCorrect, err := s.agreeWithClaim(game, claim)
+	if err != nil {
+		return StepData{}, err
+	}
+	trace, index, err := s.traces.TraceAt(game, claim)
 	if err != nil {
 		return StepData{}, err
 	}
-	index := claim.TraceIndex(s.gameDepth)
 	var preState []byte
 	var proofData []byte
 	// If we are attacking index 0, we provide the absolute pre-state, not an intermediate state
 	if index == 0 && !claimCorrect {
-		preState = s.trace.AbsolutePreState()
+		preState = trace.AbsolutePreState()
 	} else {
 		// If attacking, get the state just before, other get the state after
 		if !claimCorrect {
 			index = index - 1
 		}
-		preState, proofData, err = s.trace.GetPreimage(index)
+		preState, proofData, err = trace.GetPreimage(index)
 		if err != nil {
 			return StepData{}, err
 		}
@@ -116,42 +124,46 @@ func (s *Solver) AttemptStep(claim types.Claim, agreeWithClaimLevel bool) (StepD
 }
 
 // attack returns a response that attacks the claim.
-func (s *Solver) attack(claim types.Claim) (*types.Claim, error) {
-	position := claim.Attack()
-	value, err := s.traceAtPosition(position)
+func (s *Solver) attack(game types.Game, claim types.Claim) (*types.Claim, error) {
+	move := &types.Claim{
+		ClaimData:           types.ClaimData{Position: claim.Attack()},
+		Parent:              claim.ClaimData,
+		ParentContractIndex: claim.ContractIndex,
+	}
+	value, err := s.traceAtClaim(game, *move)
 	if err != nil {
 		return nil, fmt.Errorf("attack claim: %w", err)
 	}
-	return &types.Claim{
-		ClaimData:           types.ClaimData{Value: value, Position: position},
-		Parent:              claim.ClaimData,
-		ParentContractIndex: claim.ContractIndex,
-	}, nil
+	move.Value = value
+	return move, nil
 }
 
 // defend returns a response that defends the claim.
-func (s *Solver) defend(claim types.Claim) (*types.Claim, error) {
-	position := claim.Defend()
-	value, err := s.traceAtPosition(position)
+func (s *Solver) defend(game types.Game, claim types.Claim) (*types.Claim, error) {
+	move := &types.Claim{
+		ClaimData:           types.ClaimData{Position: claim.Defend()},
+		Parent:              claim.ClaimData,
+		ParentContractIndex: claim.ContractIndex,
+	}
+	value, err := s.traceAtClaim(game, *move)
 	if err != nil {
 		return nil, fmt.Errorf("defend claim: %w", err)
 	}
-	return &types.Claim{
-		ClaimData:           types.ClaimData{Value: value, Position: position},
-		Parent:              claim.ClaimData,
-		ParentContractIndex: claim.ContractIndex,
-	}, nil
+	move.Value = value
+	return move, nil
 }
 
 // agreeWithClaim returns true if the claim is correct according to the internal [TraceProvider].
-func (s *Solver) agreeWithClaim(claim types.ClaimData) (bool, error) {
-	ourValue, err := s.traceAtPosition(claim.Position)
+func (s *Solver) agreeWithClaim(game types.Game, claim types.Claim) (bool, error) {
+	ourValue, err := s.traceAtClaim(game, claim)
 	return ourValue == claim.Value, err
 }
 
-// traceAtPosition returns the [common.Hash] from internal [TraceProvider] at the given [Position].
-func (s *Solver) traceAtPosition(p types.Position) (common.Hash, error) {
-	index := p.TraceIndex(s.gameDepth)
-	hash, err := s.trace.Get(index)
-	return hash, err
+// traceAtClaim returns the [common.Hash] from the [TraceProvider] selected for the position of the given claim.
+func (s *Solver) traceAtClaim(game types.Game, claim types.Claim) (common.Hash, error) {
+	trace, index, err := s.traces.TraceAt(game, claim)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return trace.Get(index)
 }
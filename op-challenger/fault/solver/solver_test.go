@@ -84,7 +84,7 @@ func TestNextMove(t *testing.T) {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
 			solver := solver.NewSolver(maxDepth, builder.CorrectTraceProvider())
-			move, err := solver.NextMove(test.claim, test.agreeWithLevel)
+			move, err := solver.NextMove(nil, test.claim, test.agreeWithLevel)
 			if test.expectedErr == nil {
 				require.NoError(t, err)
 			} else {
@@ -177,7 +177,7 @@ func TestAttemptStep(t *testing.T) {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
 			fmt.Printf("%v\n", test.claim.Position.TraceIndex(maxDepth))
-			step, err := alphabetSolver.AttemptStep(test.claim, test.agreeWithLevel)
+			step, err := alphabetSolver.AttemptStep(nil, test.claim, test.agreeWithLevel)
 			if test.expectedErr == nil {
 				require.NoError(t, err)
 				require.Equal(t, test.claim, step.LeafClaim)
@@ -191,3 +191,59 @@ func TestAttemptStep(t *testing.T) {
 		})
 	}
 }
+
+type subTrace struct {
+	types.TraceProvider
+	prestate []byte
+}
+
+func (s *subTrace) AbsolutePreState() []byte {
+	return s.prestate
+}
+
+// splitSelector plays the trace of the claim's ancestor at depth 1 below it, restarting the trace index from 0.
+type splitSelector struct {
+	top      types.TraceProvider
+	subs     []types.TraceProvider
+	maxDepth int
+}
+
+func (s *splitSelector) TraceAt(_ types.Game, claim types.Claim) (types.TraceProvider, uint64, error) {
+	pos := claim.Position
+	if pos.Depth() <= 1 {
+		return s.top, pos.TraceIndex(1), nil
+	}
+	ancestor := pos.AncestorAtDepth(1)
+	relative := pos.RelativeToAncestorAtDepth(1)
+	return s.subs[ancestor.IndexAtDepth()], relative.TraceIndex(s.maxDepth - 1), nil
+}
+
+func TestAttemptStepWithTraceSelector(t *testing.T) {
+	maxDepth := 3
+	builder := test.NewAlphabetClaimBuilder(t, maxDepth)
+	selector := &splitSelector{
+		top: builder.CorrectTraceProvider(),
+		subs: []types.TraceProvider{
+			&subTrace{builder.CorrectTraceProvider(), []byte{0x00}},
+			&subTrace{builder.CorrectTraceProvider(), []byte{0x01}},
+		},
+		maxDepth: maxDepth,
+	}
+	s := solver.NewSolverForTraces(maxDepth, selector)
+
+	// Trace index 4 is the first leaf below the right claim at depth 1, index 0 of its sub trace.
+	step, err := s.AttemptStep(nil, builder.CreateLeafClaim(4, false), false)
+	require.NoError(t, err)
+	require.True(t, step.IsAttack)
+	require.Equal(t, []byte{0x01}, step.PreState)
+	require.Nil(t, step.ProofData)
+
+	// Trace index 5 is index 1 of the sub trace, so the claim is only correct with the value of index 1.
+	claim := builder.CreateLeafClaim(5, true)
+	claim.Value = builder.CorrectClaim(1)
+	step, err = s.AttemptStep(nil, claim, false)
+	require.NoError(t, err)
+	require.False(t, step.IsAttack)
+	require.Equal(t, builder.CorrectPreState(1), step.PreState)
+	require.Equal(t, builder.CorrectProofData(1), step.ProofData)
+}
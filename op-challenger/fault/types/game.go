@@ -28,6 +28,9 @@ type Game interface {
 
 	// AgreeWithClaimLevel returns if the game state agrees with the provided claim level.
 	AgreeWithClaimLevel(claim Claim) bool
+
+	// GetParent returns the parent of the provided [Claim], or [ErrClaimNotFound] for the root claim.
+	GetParent(claim Claim) (Claim, error)
 }
 
 type extendedClaim struct {
@@ -122,7 +125,7 @@ func (g *gameState) getChildren(c ClaimData) []ClaimData {
 	return g.claims[c].children
 }
 
-func (g *gameState) getParent(claim Claim) (Claim, error) {
+func (g *gameState) GetParent(claim Claim) (Claim, error) {
 	if claim.IsRoot() {
 		return Claim{}, ErrClaimNotFound
 	}
@@ -104,20 +104,20 @@ func TestGame_PutAll_ParentsAndChildren(t *testing.T) {
 	g := NewGameState(false, root, testMaxDepth)
 
 	// We should not be able to get the parent of the root claim.
-	parent, err := g.getParent(root)
+	parent, err := g.GetParent(root)
 	require.ErrorIs(t, err, ErrClaimNotFound)
 	require.Equal(t, parent, Claim{})
 
 	// Put the rest of the claims in the state.
 	err = g.PutAll([]Claim{top, middle, bottom})
 	require.NoError(t, err)
-	parent, err = g.getParent(top)
+	parent, err = g.GetParent(top)
 	require.NoError(t, err)
 	require.Equal(t, parent, root)
-	parent, err = g.getParent(middle)
+	parent, err = g.GetParent(middle)
 	require.NoError(t, err)
 	require.Equal(t, parent, top)
-	parent, err = g.getParent(bottom)
+	parent, err = g.GetParent(bottom)
 	require.NoError(t, err)
 	require.Equal(t, parent, middle)
 }
@@ -145,28 +145,28 @@ func TestGame_Put_ParentsAndChildren(t *testing.T) {
 	g := NewGameState(false, root, testMaxDepth)
 
 	// We should not be able to get the parent of the root claim.
-	parent, err := g.getParent(root)
+	parent, err := g.GetParent(root)
 	require.ErrorIs(t, err, ErrClaimNotFound)
 	require.Equal(t, parent, Claim{})
 
 	// Put + Check Top
 	err = g.Put(top)
 	require.NoError(t, err)
-	parent, err = g.getParent(top)
+	parent, err = g.GetParent(top)
 	require.NoError(t, err)
 	require.Equal(t, parent, root)
 
 	// Put + Check Top Middle
 	err = g.Put(middle)
 	require.NoError(t, err)
-	parent, err = g.getParent(middle)
+	parent, err = g.GetParent(middle)
 	require.NoError(t, err)
 	require.Equal(t, parent, top)
 
 	// Put + Check Top Bottom
 	err = g.Put(bottom)
 	require.NoError(t, err)
-	parent, err = g.getParent(bottom)
+	parent, err = g.GetParent(bottom)
 	require.NoError(t, err)
 	require.Equal(t, parent, middle)
 }
@@ -39,6 +39,19 @@ func (p *Position) TraceIndex(maxDepth int) uint64 {
 	return uint64(p.indexAtDepth<<rd | ((1 << rd) - 1))
 }
 
+// AncestorAtDepth returns the position of the ancestor at the given depth,
+// which must not be deeper than the position itself.
+func (p *Position) AncestorAtDepth(depth int) Position {
+	return NewPosition(depth, p.indexAtDepth>>(p.depth-depth))
+}
+
+// RelativeToAncestorAtDepth returns the position within the subtree rooted at the ancestor at the given depth.
+// This is used for games split by depth, where the claims below the split depth play a separate trace.
+func (p *Position) RelativeToAncestorAtDepth(depth int) Position {
+	relativeDepth := p.depth - depth
+	return NewPosition(relativeDepth, p.indexAtDepth&((1<<relativeDepth)-1))
+}
+
 // move goes to the left or right child.
 func (p *Position) move(right bool) {
 	p.depth++
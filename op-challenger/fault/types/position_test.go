@@ -119,3 +119,37 @@ func TestDefend(t *testing.T) {
 		require.Equalf(t, test.DefendGIndex, result.ToGIndex(), "Defend from GIndex %v", pos.ToGIndex())
 	}
 }
+
+func TestAncestorAtDepth(t *testing.T) {
+	tests := []struct {
+		pos      Position
+		depth    int
+		expected Position
+	}{
+		{NewPosition(4, 13), 4, NewPosition(4, 13)},
+		{NewPosition(4, 13), 2, NewPosition(2, 3)},
+		{NewPosition(4, 13), 0, NewPosition(0, 0)},
+		{NewPosition(3, 2), 1, NewPosition(1, 0)},
+	}
+	for _, test := range tests {
+		result := test.pos.AncestorAtDepth(test.depth)
+		require.Equalf(t, test.expected, result, "Ancestor of %v at depth %v", test.pos, test.depth)
+	}
+}
+
+func TestRelativeToAncestorAtDepth(t *testing.T) {
+	tests := []struct {
+		pos      Position
+		depth    int
+		expected Position
+	}{
+		{NewPosition(4, 13), 4, NewPosition(0, 0)},
+		{NewPosition(4, 13), 2, NewPosition(2, 1)},
+		{NewPosition(4, 13), 0, NewPosition(4, 13)},
+		{NewPosition(3, 2), 1, NewPosition(2, 2)},
+	}
+	for _, test := range tests {
+		result := test.pos.RelativeToAncestorAtDepth(test.depth)
+		require.Equalf(t, test.expected, result, "Position %v relative to depth %v", test.pos, test.depth)
+	}
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"errors"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -26,6 +27,12 @@ type StepCallData struct {
 	Proof      []byte
 }
 
+// ZkProofCallData encapsulates the data needed to submit a zk proof of the execution of the game.
+type ZkProofCallData struct {
+	PublicInputs []byte
+	Proof        []byte
+}
+
 // TraceProvider is a generic way to get a claim value at a specific step in the trace.
 type TraceProvider interface {
 	// Get returns the claim value at the requested index.
@@ -40,6 +47,28 @@ type TraceProvider interface {
 	AbsolutePreState() []byte
 }
 
+// TraceSelector selects the trace a claim commits to by its position and the claims it descends from,
+// allowing games that play different traces at different depths.
+type TraceSelector interface {
+	// TraceAt returns the trace the claim commits to, and the index of the claim in that trace.
+	// The claim may be a move not yet in the game, only its position and parent are used.
+	TraceAt(game Game, claim Claim) (TraceProvider, uint64, error)
+}
+
+// SingleTrace is a [TraceSelector] playing a single trace over the full depth of the game.
+type SingleTrace struct {
+	trace    TraceProvider
+	maxDepth int
+}
+
+func NewSingleTrace(trace TraceProvider, maxDepth int) *SingleTrace {
+	return &SingleTrace{trace: trace, maxDepth: maxDepth}
+}
+
+func (s *SingleTrace) TraceAt(_ Game, claim Claim) (TraceProvider, uint64, error) {
+	return s.trace, claim.TraceIndex(s.maxDepth), nil
+}
+
 // ClaimData is the core of a claim. It must be unique inside a specific game.
 type ClaimData struct {
 	Value common.Hash
@@ -53,6 +82,23 @@ func (c *ClaimData) ValueBytes() [32]byte {
 	return responseArr
 }
 
+// Clock is the chess clock of a claim, packed in the FaultDisputeGame contract
+// as the duration in the high-order and the timestamp in the low-order 64 bits.
+type Clock struct {
+	// Duration is the time in seconds spent by the side of the claimant, up to the claim.
+	Duration uint64
+	// Timestamp is the time in seconds the claim was made at.
+	Timestamp uint64
+}
+
+// NewClock unpacks a [Clock] as returned by the contract.
+func NewClock(packed *big.Int) Clock {
+	return Clock{
+		Duration:  new(big.Int).Rsh(packed, 64).Uint64(),
+		Timestamp: packed.Uint64(),
+	}
+}
+
 // Claim extends ClaimData with information about the relationship between two claims.
 // It uses ClaimData to break cyclicity without using pointers.
 // If the position of the game is Depth 0, IndexAtDepth 0 it is the root claim
@@ -61,10 +107,9 @@ type Claim struct {
 	ClaimData
 	// WARN: Countered is a mutable field in the FaultDisputeGame contract
 	//       and rely on it for determining whether to step on leaf claims.
-	//       When caching is implemented for the Challenger, this will need
-	//       to be changed/removed to avoid invalid/stale contract state.
+	//       Loaders caching claims must refresh it, see [fault.CachingLoader].
 	Countered bool
-	Clock     uint64
+	Clock     Clock
 	Parent    ClaimData
 	// Location of the claim & it's parent inside the contract. Does not exist
 	// for claims that have not made it to the contract.
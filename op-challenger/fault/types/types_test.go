@@ -0,0 +1,15 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClock(t *testing.T) {
+	packed := new(big.Int).Lsh(big.NewInt(300), 64)
+	packed.Or(packed, big.NewInt(1_690_000_000))
+	require.Equal(t, Clock{Duration: 300, Timestamp: 1_690_000_000}, NewClock(packed))
+	require.Equal(t, Clock{}, NewClock(big.NewInt(0)))
+}
@@ -0,0 +1,84 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ZkResponder extends the [Responder] to submit zk proofs of the game.
+type ZkResponder interface {
+	Responder
+	Prove(ctx context.Context, proof types.ZkProofCallData) error
+}
+
+// ZkProofProvider provides the zk proof of the execution of the game.
+type ZkProofProvider interface {
+	GetProof(ctx context.Context) (types.ZkProofCallData, error)
+}
+
+// ZkAgent resolves a game with a single zk proof of its execution, instead of bisection and steps.
+type ZkAgent struct {
+	prover                  ZkProofProvider
+	loader                  Loader
+	responder               ZkResponder
+	agreeWithProposedOutput bool
+	proved                  bool
+	log                     log.Logger
+}
+
+func NewZkAgent(loader Loader, prover ZkProofProvider, responder ZkResponder, agreeWithProposedOutput bool, log log.Logger) *ZkAgent {
+	return &ZkAgent{
+		prover:                  prover,
+		loader:                  loader,
+		responder:               responder,
+		agreeWithProposedOutput: agreeWithProposedOutput,
+		log:                     log,
+	}
+}
+
+// Act resolves the game if it is in a terminal state, otherwise submits the proof once it is required:
+// when the challenger disputes the root claim, or the root claim it agrees with has been challenged.
+func (a *ZkAgent) Act(ctx context.Context) error {
+	if a.tryResolve(ctx) || a.proved {
+		return nil
+	}
+	claims, err := a.loader.FetchClaims(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch claims: %w", err)
+	}
+	if len(claims) == 0 {
+		return errors.New("no claims")
+	}
+	if !a.agreeWithProposedOutput && len(claims) == 1 {
+		a.log.Debug("Root claim is unchallenged, no proof required")
+		return nil
+	}
+	proof, err := a.prover.GetProof(ctx)
+	if err != nil {
+		return fmt.Errorf("get proof: %w", err)
+	}
+	a.log.Info("Submitting zk proof", "publicInputs", len(proof.PublicInputs), "proof", len(proof.Proof))
+	if err := a.responder.Prove(ctx, proof); err != nil {
+		return fmt.Errorf("submit proof: %w", err)
+	}
+	a.proved = true
+	return nil
+}
+
+// tryResolve resolves the game if it is in a terminal state
+// and returns true if the game resolves successfully.
+func (a *ZkAgent) tryResolve(ctx context.Context) bool {
+	if !a.responder.CanResolve(ctx) {
+		return false
+	}
+	a.log.Info("Resolving game")
+	if err := a.responder.Resolve(ctx); err != nil {
+		a.log.Error("Failed to resolve the game", "err", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+var testZkProof = types.ZkProofCallData{PublicInputs: []byte{0x01}, Proof: []byte{0x02}}
+
+type stubClaimLoader struct {
+	claims []types.Claim
+}
+
+func (s *stubClaimLoader) FetchClaims(_ context.Context) ([]types.Claim, error) {
+	return s.claims, nil
+}
+
+type stubZkProver struct {
+	calls int
+	err   error
+}
+
+func (s *stubZkProver) GetProof(_ context.Context) (types.ZkProofCallData, error) {
+	s.calls++
+	return testZkProof, s.err
+}
+
+type stubZkResponder struct {
+	canResolve bool
+	resolved   int
+	proofs     []types.ZkProofCallData
+}
+
+func (s *stubZkResponder) CanResolve(_ context.Context) bool {
+	return s.canResolve
+}
+
+func (s *stubZkResponder) Resolve(_ context.Context) error {
+	s.resolved++
+	return nil
+}
+
+func (s *stubZkResponder) Respond(_ context.Context, _ types.Claim) error {
+	panic("zk agent should not move")
+}
+
+func (s *stubZkResponder) Step(_ context.Context, _ types.StepCallData) error {
+	panic("zk agent should not step")
+}
+
+func (s *stubZkResponder) Prove(_ context.Context, proof types.ZkProofCallData) error {
+	s.proofs = append(s.proofs, proof)
+	return nil
+}
+
+func setupZkAgent(t *testing.T, agreeWithProposedOutput bool, claimCount int) (*ZkAgent, *stubZkProver, *stubZkResponder) {
+	loader := &stubClaimLoader{}
+	for i := 0; i < claimCount; i++ {
+		loader.claims = append(loader.claims, types.Claim{ClaimData: types.ClaimData{Value: common.Hash{byte(i)}}})
+	}
+	prover := &stubZkProver{}
+	responder := &stubZkResponder{}
+	agent := NewZkAgent(loader, prover, responder, agreeWithProposedOutput, testlog.Logger(t, log.LvlError))
+	return agent, prover, responder
+}
+
+func TestZkAgent_ProvesDisputedRootClaim(t *testing.T) {
+	agent, prover, responder := setupZkAgent(t, true, 1)
+	require.NoError(t, agent.Act(context.Background()))
+	require.Equal(t, []types.ZkProofCallData{testZkProof}, responder.proofs)
+
+	// Only proves once
+	require.NoError(t, agent.Act(context.Background()))
+	require.Len(t, responder.proofs, 1)
+	require.Equal(t, 1, prover.calls)
+}
+
+func TestZkAgent_DoesNotProveUnchallengedRootClaim(t *testing.T) {
+	agent, prover, responder := setupZkAgent(t, false, 1)
+	require.NoError(t, agent.Act(context.Background()))
+	require.Empty(t, responder.proofs)
+	require.Zero(t, prover.calls)
+}
+
+func TestZkAgent_ProvesChallengedRootClaim(t *testing.T) {
+	agent, _, responder := setupZkAgent(t, false, 2)
+	require.NoError(t, agent.Act(context.Background()))
+	require.Equal(t, []types.ZkProofCallData{testZkProof}, responder.proofs)
+}
+
+func TestZkAgent_ProofUnavailable(t *testing.T) {
+	agent, prover, responder := setupZkAgent(t, true, 1)
+	prover.err = errors.New("boom")
+	require.ErrorIs(t, agent.Act(context.Background()), prover.err)
+	require.Empty(t, responder.proofs)
+
+	// Retries on the next action
+	prover.err = nil
+	require.NoError(t, agent.Act(context.Background()))
+	require.Len(t, responder.proofs, 1)
+}
+
+func TestZkAgent_Resolves(t *testing.T) {
+	agent, prover, responder := setupZkAgent(t, true, 1)
+	responder.canResolve = true
+	require.NoError(t, agent.Act(context.Background()))
+	require.Equal(t, 1, responder.resolved)
+	require.Zero(t, prover.calls)
+}
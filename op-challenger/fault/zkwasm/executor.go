@@ -0,0 +1,119 @@
+package zkwasm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/cannon"
+	oplog "github.com/ethereum-optimism/optimism/op-service/log"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// preimagesDir is the op-program datadir
+	preimagesDir = "preimages"
+	// traceFile is the pre-image trace recorded by the op-program
+	traceFile = "trace.bin"
+	// privateInput is the zkWasm private input derived from the pre-image trace
+	privateInput = "preimages.bin"
+	// publicInput is the zkWasm public input, the boot values of the op-program run
+	publicInput = "preimages.bin.public"
+	// proofFile is the file the prover writes the proof to
+	proofFile = "proof.bin"
+)
+
+type cmdExecutor func(ctx context.Context, l log.Logger, binary string, args ...string) error
+
+type executor struct {
+	logger       log.Logger
+	l1           string
+	l2           string
+	inputs       cannon.LocalGameInputs
+	server       string
+	network      string
+	rollupConfig string
+	l2Genesis    string
+	prover       string
+	image        string
+	cmdExecutor  cmdExecutor
+}
+
+func newExecutor(logger log.Logger, cfg *config.Config, inputs cannon.LocalGameInputs) *executor {
+	return &executor{
+		logger:       logger,
+		l1:           cfg.L1EthRpc,
+		l2:           cfg.CannonL2,
+		inputs:       inputs,
+		server:       cfg.CannonServer,
+		network:      cfg.CannonNetwork,
+		rollupConfig: cfg.CannonRollupConfigPath,
+		l2Genesis:    cfg.CannonL2GenesisPath,
+		prover:       cfg.ZkWasmProver,
+		image:        cfg.ZkWasmImage,
+		cmdExecutor:  runCmd,
+	}
+}
+
+// GenerateProof runs the op-program with the local inputs of the game to record its pre-image trace
+// and derive the zkWasm inputs from it, then runs the prover to write the proof of the run to dir.
+func (e *executor) GenerateProof(ctx context.Context, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create proof directory %v: %w", dir, err)
+	}
+	args := []string{
+		"--l1", e.l1,
+		"--l2", e.l2,
+		"--datadir", filepath.Join(dir, preimagesDir),
+		"--l1.head", e.inputs.L1Head.Hex(),
+		"--l2.head", e.inputs.L2Head.Hex(),
+		"--l2.claim", e.inputs.L2Claim.Hex(),
+		"--l2.blocknumber", e.inputs.L2BlockNumber.Text(10),
+		"--preimage", filepath.Join(dir, privateInput),
+		"--preimage.public", filepath.Join(dir, publicInput),
+		"--preimage.trace", filepath.Join(dir, traceFile),
+	}
+	if e.network != "" {
+		args = append(args, "--network", e.network)
+	}
+	if e.rollupConfig != "" {
+		args = append(args, "--rollup.config", e.rollupConfig)
+	}
+	if e.l2Genesis != "" {
+		args = append(args, "--l2.genesis", e.l2Genesis)
+	}
+	e.logger.Info("Recording pre-image trace", "cmd", e.server, "args", args)
+	if err := e.cmdExecutor(ctx, e.logger.New("cmd", "op-program"), e.server, args...); err != nil {
+		// The op-program fails if the claim is invalid, but still writes the inputs to prove the failing run.
+		if _, statErr := os.Stat(filepath.Join(dir, publicInput)); statErr != nil {
+			return fmt.Errorf("op-program did not write the zkWasm input: %w", err)
+		}
+		e.logger.Warn("Claim is invalid, proving the failing run", "err", err)
+	}
+
+	args = []string{
+		"--wasm", e.image,
+		"--private", filepath.Join(dir, privateInput),
+		"--public", filepath.Join(dir, publicInput),
+		"--output", filepath.Join(dir, proofFile),
+	}
+	e.logger.Info("Generating zkWasm proof", "cmd", e.prover, "args", args)
+	if err := e.cmdExecutor(ctx, e.logger.New("cmd", "prover"), e.prover, args...); err != nil {
+		return fmt.Errorf("prover failed: %w", err)
+	}
+	return nil
+}
+
+func runCmd(ctx context.Context, l log.Logger, binary string, args ...string) error {
+	cmd := exec.CommandContext(ctx, binary, args...)
+	stdOut := oplog.NewWriter(l, log.LvlInfo)
+	defer stdOut.Close()
+	stdErr := oplog.NewWriter(l, log.LvlError)
+	defer stdErr.Close()
+	cmd.Stdout = stdOut
+	cmd.Stderr = stdErr
+	return cmd.Run()
+}
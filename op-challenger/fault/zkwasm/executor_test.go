@@ -0,0 +1,105 @@
+package zkwasm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/cannon"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+var testInputs = cannon.LocalGameInputs{
+	L1Head:        common.Hash{0x11},
+	L2Head:        common.Hash{0x22},
+	L2Claim:       common.Hash{0x33},
+	L2BlockNumber: big.NewInt(3333),
+}
+
+func testExecutorConfig() *config.Config {
+	cfg := config.NewConfig("http://localhost:8888", common.Address{0xaa}, config.TraceTypeZkWasm, true, 5)
+	cfg.CannonServer = "./bin/op-program"
+	cfg.CannonL2 = "http://localhost:9999"
+	cfg.CannonNetwork = "mainnet"
+	cfg.ZkWasmProver = "./bin/prover"
+	cfg.ZkWasmImage = "./bin/op-program-client.wasm"
+	return &cfg
+}
+
+type execution struct {
+	binary string
+	args   map[string]string
+}
+
+// captureExec records the executed commands. The op-program writes the public input before failing with hostErr.
+func captureExec(t *testing.T, hostErr error) (*executor, *[]execution) {
+	executor := newExecutor(testlog.Logger(t, log.LvlInfo), testExecutorConfig(), testInputs)
+	var executions []execution
+	executor.cmdExecutor = func(ctx context.Context, l log.Logger, b string, a ...string) error {
+		args := make(map[string]string)
+		for i := 0; i < len(a); i += 2 {
+			args[a[i]] = a[i+1]
+		}
+		executions = append(executions, execution{binary: b, args: args})
+		if public, ok := args["--preimage.public"]; ok {
+			require.NoError(t, os.WriteFile(public, []byte{0x01}, 0644))
+			return hostErr
+		}
+		return nil
+	}
+	return executor, &executions
+}
+
+func TestGenerateProof(t *testing.T) {
+	dir := t.TempDir()
+	executor, executions := captureExec(t, nil)
+	require.NoError(t, executor.GenerateProof(context.Background(), dir))
+	require.Len(t, *executions, 2)
+
+	host := (*executions)[0]
+	require.Equal(t, executor.server, host.binary)
+	require.Equal(t, executor.l1, host.args["--l1"])
+	require.Equal(t, executor.l2, host.args["--l2"])
+	require.Equal(t, filepath.Join(dir, preimagesDir), host.args["--datadir"])
+	require.Equal(t, testInputs.L1Head.Hex(), host.args["--l1.head"])
+	require.Equal(t, testInputs.L2Head.Hex(), host.args["--l2.head"])
+	require.Equal(t, testInputs.L2Claim.Hex(), host.args["--l2.claim"])
+	require.Equal(t, "3333", host.args["--l2.blocknumber"])
+	require.Equal(t, "mainnet", host.args["--network"])
+	require.Equal(t, filepath.Join(dir, privateInput), host.args["--preimage"])
+	require.Equal(t, filepath.Join(dir, publicInput), host.args["--preimage.public"])
+	require.Equal(t, filepath.Join(dir, traceFile), host.args["--preimage.trace"])
+	require.NotContains(t, host.args, "--server")
+
+	prover := (*executions)[1]
+	require.Equal(t, executor.prover, prover.binary)
+	require.Equal(t, executor.image, prover.args["--wasm"])
+	require.Equal(t, filepath.Join(dir, privateInput), prover.args["--private"])
+	require.Equal(t, filepath.Join(dir, publicInput), prover.args["--public"])
+	require.Equal(t, filepath.Join(dir, proofFile), prover.args["--output"])
+}
+
+func TestGenerateProofOfInvalidClaim(t *testing.T) {
+	executor, executions := captureExec(t, errors.New("exit status 1"))
+	require.NoError(t, executor.GenerateProof(context.Background(), t.TempDir()))
+	require.Len(t, *executions, 2, "should prove the failing run")
+}
+
+func TestGenerateProofHostFailed(t *testing.T) {
+	hostErr := errors.New("no L1 node")
+	executor, executions := captureExec(t, nil)
+	executor.cmdExecutor = func(ctx context.Context, l log.Logger, b string, a ...string) error {
+		*executions = append(*executions, execution{binary: b})
+		return hostErr
+	}
+	err := executor.GenerateProof(context.Background(), t.TempDir())
+	require.ErrorIs(t, err, hostErr)
+	require.Len(t, *executions, 1, "should not run the prover without input")
+}
@@ -0,0 +1,80 @@
+package zkwasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/cannon"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+type Executor interface {
+	// GenerateProof records the op-program run of the game and proves it, writing the proof and its inputs to dir.
+	GenerateProof(ctx context.Context, dir string) error
+}
+
+// ZkWasmProofProvider provides the zkWasm proof of the op-program run for the local inputs of a game,
+// which resolves the game without bisection.
+type ZkWasmProofProvider struct {
+	dir      string
+	executor Executor
+}
+
+// NewZkWasmProofProvider creates a proof provider for the game of cfg,
+// with local inputs derived from the game contract and the L1 and L2 chains.
+func NewZkWasmProofProvider(ctx context.Context, logger log.Logger, cfg *config.Config, l1Client cannon.L1Source) (*ZkWasmProofProvider, error) {
+	l2Client, err := ethclient.DialContext(ctx, cfg.CannonL2)
+	if err != nil {
+		return nil, fmt.Errorf("dial l2 client %v: %w", cfg.CannonL2, err)
+	}
+	defer l2Client.Close()
+	gameCaller, err := bindings.NewFaultDisputeGameCaller(cfg.GameAddress, l1Client)
+	if err != nil {
+		return nil, fmt.Errorf("create caller for game %v: %w", cfg.GameAddress, err)
+	}
+	inputs, err := cannon.FetchLocalInputs(ctx, gameCaller, l1Client, l2Client)
+	if err != nil {
+		return nil, fmt.Errorf("fetch local game inputs: %w", err)
+	}
+	logger.Info("Derived local game inputs", "l1Head", inputs.L1Head, "l2Head", inputs.L2Head,
+		"l2Claim", inputs.L2Claim, "l2BlockNumber", inputs.L2BlockNumber)
+	return &ZkWasmProofProvider{
+		dir:      cfg.ZkWasmDatadir,
+		executor: newExecutor(logger, cfg, inputs),
+	}, nil
+}
+
+// GetProof returns the proof of the op-program run, generating it if it does not exist yet.
+func (p *ZkWasmProofProvider) GetProof(ctx context.Context) (types.ZkProofCallData, error) {
+	proof, err := p.loadProof()
+	if errors.Is(err, os.ErrNotExist) {
+		if err := p.executor.GenerateProof(ctx, p.dir); err != nil {
+			return types.ZkProofCallData{}, fmt.Errorf("generate zkwasm proof: %w", err)
+		}
+		// Try loading the proof again now and it should exist.
+		proof, err = p.loadProof()
+	}
+	return proof, err
+}
+
+func (p *ZkWasmProofProvider) loadProof() (types.ZkProofCallData, error) {
+	proof, err := os.ReadFile(filepath.Join(p.dir, proofFile))
+	if err != nil {
+		return types.ZkProofCallData{}, fmt.Errorf("cannot read proof: %w", err)
+	}
+	if len(proof) == 0 {
+		return types.ZkProofCallData{}, errors.New("proof is empty")
+	}
+	public, err := os.ReadFile(filepath.Join(p.dir, publicInput))
+	if err != nil {
+		return types.ZkProofCallData{}, fmt.Errorf("cannot read public input: %w", err)
+	}
+	return types.ZkProofCallData{PublicInputs: public, Proof: proof}, nil
+}
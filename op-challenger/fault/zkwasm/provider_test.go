@@ -0,0 +1,67 @@
+package zkwasm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/fault/types"
+	"github.com/stretchr/testify/require"
+)
+
+type stubExecutor struct {
+	generated int
+	err       error
+}
+
+func (e *stubExecutor) GenerateProof(_ context.Context, dir string) error {
+	e.generated++
+	if e.err != nil {
+		return e.err
+	}
+	if err := os.WriteFile(filepath.Join(dir, publicInput), []byte{0xaa}, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, proofFile), []byte{0xbb}, 0644)
+}
+
+func TestGetProof(t *testing.T) {
+	t.Run("ExistingProof", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, publicInput), []byte{0x01}, 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, proofFile), []byte{0x02}, 0644))
+		executor := &stubExecutor{}
+		provider := &ZkWasmProofProvider{dir: dir, executor: executor}
+		proof, err := provider.GetProof(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, types.ZkProofCallData{PublicInputs: []byte{0x01}, Proof: []byte{0x02}}, proof)
+		require.Zero(t, executor.generated)
+	})
+
+	t.Run("GenerateProof", func(t *testing.T) {
+		executor := &stubExecutor{}
+		provider := &ZkWasmProofProvider{dir: t.TempDir(), executor: executor}
+		proof, err := provider.GetProof(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, types.ZkProofCallData{PublicInputs: []byte{0xaa}, Proof: []byte{0xbb}}, proof)
+		require.Equal(t, 1, executor.generated)
+	})
+
+	t.Run("GenerateFailed", func(t *testing.T) {
+		executor := &stubExecutor{err: errors.New("prover failed")}
+		provider := &ZkWasmProofProvider{dir: t.TempDir(), executor: executor}
+		_, err := provider.GetProof(context.Background())
+		require.ErrorIs(t, err, executor.err)
+	})
+
+	t.Run("EmptyProof", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, publicInput), []byte{0x01}, 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, proofFile), nil, 0644))
+		provider := &ZkWasmProofProvider{dir: dir, executor: &stubExecutor{}}
+		_, err := provider.GetProof(context.Background())
+		require.ErrorContains(t, err, "proof is empty")
+	})
+}
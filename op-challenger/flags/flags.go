@@ -5,11 +5,14 @@ import (
 	"strings"
 
 	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
 	openum "github.com/ethereum-optimism/optimism/op-service/enum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/urfave/cli/v2"
 
 	opservice "github.com/ethereum-optimism/optimism/op-service"
 	oplog "github.com/ethereum-optimism/optimism/op-service/log"
+	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
 	txmgr "github.com/ethereum-optimism/optimism/op-service/txmgr"
 )
 
@@ -30,9 +33,14 @@ var (
 	}
 	DGFAddressFlag = &cli.StringFlag{
 		Name:    "game-address",
-		Usage:   "Address of the Fault Game contract.",
+		Usage:   "Address of the Fault Game contract. Either this or the game factory address is required.",
 		EnvVars: prefixEnvVars("GAME_ADDRESS"),
 	}
+	GameFactoryAddressFlag = &cli.StringFlag{
+		Name:    "game-factory-address",
+		Usage:   "Address of the Dispute Game Factory contract to discover and play all fault games from, instead of a single game.",
+		EnvVars: prefixEnvVars("GAME_FACTORY_ADDRESS"),
+	}
 	TraceTypeFlag = &cli.GenericFlag{
 		Name:    "trace-type",
 		Usage:   "The trace type. Valid options: " + openum.EnumString(config.TraceTypes),
@@ -44,15 +52,62 @@ var (
 	}
 	AgreeWithProposedOutputFlag = &cli.BoolFlag{
 		Name:    "agree-with-proposed-output",
-		Usage:   "Temporary hardcoded flag if we agree or disagree with the proposed output.",
+		Usage:   "Temporary hardcoded flag if we agree or disagree with the proposed output. Either this or the rollup rpc is required.",
 		EnvVars: prefixEnvVars("AGREE_WITH_PROPOSED_OUTPUT"),
 	}
+	RollupRpcFlag = &cli.StringFlag{
+		Name:    "rollup-rpc",
+		Usage:   "HTTP provider URL of a trusted rollup node. The root claim of each game is checked against its output root to decide which side to play.",
+		EnvVars: prefixEnvVars("ROLLUP_RPC"),
+	}
 	GameDepthFlag = &cli.IntFlag{
 		Name:    "game-depth",
 		Usage:   "Depth of the game tree.",
 		EnvVars: prefixEnvVars("GAME_DEPTH"),
 	}
 	// Optional Flags
+	MaxConcurrencyFlag = &cli.UintFlag{
+		Name:    "max-concurrency",
+		Usage:   "Maximum number of games to act on at once, each game in progress is acted on in turn (game factory only)",
+		EnvVars: prefixEnvVars("MAX_CONCURRENCY"),
+		Value:   config.DefaultMaxConcurrency,
+	}
+	GameFactoryStartBlockFlag = &cli.Uint64Flag{
+		Name:    "game-factory-start-block",
+		Usage:   "First L1 block to search for created games when the datadir has no games handled yet, 0 for the L1 head at startup (game factory only)",
+		EnvVars: prefixEnvVars("GAME_FACTORY_START_BLOCK"),
+	}
+	DatadirFlag = &cli.StringFlag{
+		Name:    "datadir",
+		Usage:   "Directory to store the games already handled and the loaded claims of each game in, to resume from on restart. Required with a game factory",
+		EnvVars: prefixEnvVars("DATADIR"),
+	}
+	GameBudgetFlag = &cli.Uint64Flag{
+		Name:    "game-budget",
+		Usage:   "Maximum gwei to spend on gas for the moves, steps and proofs of each game. 0 for no limit",
+		EnvVars: prefixEnvVars("GAME_BUDGET"),
+	}
+	TotalBudgetFlag = &cli.Uint64Flag{
+		Name:    "total-budget",
+		Usage:   "Maximum gwei to spend on gas for the moves, steps and proofs of all games, since the challenger started. 0 for no limit",
+		EnvVars: prefixEnvVars("TOTAL_BUDGET"),
+	}
+	DryRunFlag = &cli.BoolFlag{
+		Name:    "dry-run",
+		Usage:   "Simulate moves, steps and resolutions with eth_call and log them as planned actions, instead of sending transactions",
+		EnvVars: prefixEnvVars("DRY_RUN"),
+	}
+	DryRunHttpAddrFlag = &cli.StringFlag{
+		Name:    "dry-run-http-addr",
+		Usage:   "Address to serve the planned actions of a dry run on (dry run only)",
+		EnvVars: prefixEnvVars("DRY_RUN_HTTP_ADDR"),
+		Value:   config.DefaultDryRunHttpAddr,
+	}
+	DryRunHttpPortFlag = &cli.IntFlag{
+		Name:    "dry-run-http-port",
+		Usage:   "Port to serve the planned actions of a dry run on at /actions, 0 to not serve them (dry run only)",
+		EnvVars: prefixEnvVars("DRY_RUN_HTTP_PORT"),
+	}
 	AlphabetFlag = &cli.StringFlag{
 		Name:    "alphabet",
 		Usage:   "Correct Alphabet Trace (alphabet trace type only)",
@@ -63,6 +118,32 @@ var (
 		Usage:   "Path to cannon executable to use when generating trace data (cannon trace type only)",
 		EnvVars: prefixEnvVars("CANNON_BIN"),
 	}
+	CannonServerFlag = &cli.StringFlag{
+		Name:    "cannon-server",
+		Usage:   "Path to executable to use as pre-image oracle server when generating trace data (cannon and zkwasm trace types only)",
+		EnvVars: prefixEnvVars("CANNON_SERVER"),
+	}
+	CannonNetworkFlag = &cli.StringFlag{
+		Name: "cannon-network",
+		Usage: fmt.Sprintf("Predefined network selection of the pre-image oracle server. Available networks: %s (cannon and zkwasm trace types only)",
+			strings.Join(chaincfg.AvailableNetworks(), ", ")),
+		EnvVars: prefixEnvVars("CANNON_NETWORK"),
+	}
+	CannonRollupConfigFlag = &cli.StringFlag{
+		Name:    "cannon-rollup-config",
+		Usage:   "Rollup chain parameters of the pre-image oracle server, if not using a predefined network (cannon and zkwasm trace types only)",
+		EnvVars: prefixEnvVars("CANNON_ROLLUP_CONFIG"),
+	}
+	CannonL2GenesisFlag = &cli.StringFlag{
+		Name:    "cannon-l2-genesis",
+		Usage:   "Path to the op-geth genesis file of the pre-image oracle server, if not using a predefined network (cannon and zkwasm trace types only)",
+		EnvVars: prefixEnvVars("CANNON_L2_GENESIS"),
+	}
+	CannonTimeoutFlag = &cli.DurationFlag{
+		Name:    "cannon-timeout",
+		Usage:   "Maximum time to run cannon for to generate a single proof, 0 for no limit (cannon trace type only)",
+		EnvVars: prefixEnvVars("CANNON_TIMEOUT"),
+	}
 	CannonPreStateFlag = &cli.StringFlag{
 		Name:    "cannon-prestate",
 		Usage:   "Path to absolute prestate to use when generating trace data (cannon trace type only)",
@@ -75,32 +156,78 @@ var (
 	}
 	CannonL2Flag = &cli.StringFlag{
 		Name:    "cannon-l2",
-		Usage:   "L2 Address of L2 JSON-RPC endpoint to use (eth and debug namespace required)  (cannon trace type only)",
+		Usage:   "L2 Address of L2 JSON-RPC endpoint to use (eth and debug namespace required)  (cannon and zkwasm trace types only)",
 		EnvVars: prefixEnvVars("CANNON_L2"),
 	}
+	ZkWasmProverFlag = &cli.StringFlag{
+		Name:    "zkwasm-prover",
+		Usage:   "Path to the prover executable, run with --wasm, --private, --public and --output to write the proof to (zkwasm trace type only)",
+		EnvVars: prefixEnvVars("ZKWASM_PROVER"),
+	}
+	ZkWasmImageFlag = &cli.StringFlag{
+		Name:    "zkwasm-image",
+		Usage:   "Path to the op-program client wasm image to prove (zkwasm trace type only)",
+		EnvVars: prefixEnvVars("ZKWASM_IMAGE"),
+	}
+	ZkWasmDatadirFlag = &cli.StringFlag{
+		Name:    "zkwasm-datadir",
+		Usage:   "Directory to store the pre-image trace, prover inputs and proofs in (zkwasm trace type only)",
+		EnvVars: prefixEnvVars("ZKWASM_DATADIR"),
+	}
+	ZkWasmVerifierFlag = &cli.StringFlag{
+		Name:    "zkwasm-verifier",
+		Usage:   "Address of the contract verifying zkWasm proofs of the game (zkwasm trace type only)",
+		EnvVars: prefixEnvVars("ZKWASM_VERIFIER"),
+	}
+	SplitDepthFlag = &cli.IntFlag{
+		Name:    "split-depth",
+		Usage:   "Depth of the game tree the claims switch from L2 output roots to cannon states at (output_cannon trace type only)",
+		EnvVars: prefixEnvVars("SPLIT_DEPTH"),
+	}
 )
 
 // requiredFlags are checked by [CheckRequired]
 var requiredFlags = []cli.Flag{
 	L1EthRpcFlag,
-	DGFAddressFlag,
 	TraceTypeFlag,
-	AgreeWithProposedOutputFlag,
 	GameDepthFlag,
 }
 
 // optionalFlags is a list of unchecked cli flags
 var optionalFlags = []cli.Flag{
+	AgreeWithProposedOutputFlag,
+	RollupRpcFlag,
+	DGFAddressFlag,
+	GameFactoryAddressFlag,
+	MaxConcurrencyFlag,
+	GameFactoryStartBlockFlag,
+	DatadirFlag,
+	GameBudgetFlag,
+	TotalBudgetFlag,
+	DryRunFlag,
+	DryRunHttpAddrFlag,
+	DryRunHttpPortFlag,
 	AlphabetFlag,
 	CannonBinFlag,
+	CannonServerFlag,
+	CannonNetworkFlag,
+	CannonRollupConfigFlag,
+	CannonL2GenesisFlag,
+	CannonTimeoutFlag,
 	CannonPreStateFlag,
 	CannonDatadirFlag,
 	CannonL2Flag,
+	SplitDepthFlag,
+	ZkWasmProverFlag,
+	ZkWasmImageFlag,
+	ZkWasmDatadirFlag,
+	ZkWasmVerifierFlag,
 }
 
 func init() {
 	optionalFlags = append(optionalFlags, oplog.CLIFlags(envVarPrefix)...)
 	optionalFlags = append(optionalFlags, txmgr.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, opmetrics.CLIFlags(envVarPrefix)...)
 
 	Flags = append(requiredFlags, optionalFlags...)
 }
@@ -114,20 +241,47 @@ func CheckRequired(ctx *cli.Context) error {
 			return fmt.Errorf("flag %s is required", f.Names()[0])
 		}
 	}
+	gameAddressSet, factorySet := ctx.IsSet(DGFAddressFlag.Name), ctx.IsSet(GameFactoryAddressFlag.Name)
+	if !gameAddressSet && !factorySet {
+		return fmt.Errorf("flag %s or %s is required", DGFAddressFlag.Name, GameFactoryAddressFlag.Name)
+	}
+	if gameAddressSet && factorySet {
+		return fmt.Errorf("flag %s and %s must not both be set", DGFAddressFlag.Name, GameFactoryAddressFlag.Name)
+	}
+	agreeSet, rollupRpcSet := ctx.IsSet(AgreeWithProposedOutputFlag.Name), ctx.IsSet(RollupRpcFlag.Name)
+	if !agreeSet && !rollupRpcSet {
+		return fmt.Errorf("flag %s or %s is required", AgreeWithProposedOutputFlag.Name, RollupRpcFlag.Name)
+	}
+	if agreeSet && rollupRpcSet {
+		return fmt.Errorf("flag %s and %s must not both be set", AgreeWithProposedOutputFlag.Name, RollupRpcFlag.Name)
+	}
+	if factorySet && !ctx.IsSet(DatadirFlag.Name) {
+		return fmt.Errorf("flag %s is required", DatadirFlag.Name)
+	}
 	gameType := config.TraceType(strings.ToLower(ctx.String(TraceTypeFlag.Name)))
 	switch gameType {
 	case config.TraceTypeCannon:
-		if !ctx.IsSet(CannonBinFlag.Name) {
-			return fmt.Errorf("flag %s is required", CannonBinFlag.Name)
+		if err := checkCannonFlags(ctx); err != nil {
+			return err
 		}
-		if !ctx.IsSet(CannonPreStateFlag.Name) {
-			return fmt.Errorf("flag %s is required", CannonPreStateFlag.Name)
+	case config.TraceTypeOutputCannon:
+		if err := checkCannonFlags(ctx); err != nil {
+			return err
 		}
-		if !ctx.IsSet(CannonDatadirFlag.Name) {
-			return fmt.Errorf("flag %s is required", CannonDatadirFlag.Name)
+		if !rollupRpcSet {
+			return fmt.Errorf("flag %s is required", RollupRpcFlag.Name)
 		}
-		if !ctx.IsSet(CannonL2Flag.Name) {
-			return fmt.Errorf("flag %s is required", CannonL2Flag.Name)
+		if !ctx.IsSet(SplitDepthFlag.Name) {
+			return fmt.Errorf("flag %s is required", SplitDepthFlag.Name)
+		}
+	case config.TraceTypeZkWasm:
+		if err := checkServerFlags(ctx); err != nil {
+			return err
+		}
+		for _, f := range []cli.Flag{ZkWasmProverFlag, ZkWasmImageFlag, ZkWasmDatadirFlag, ZkWasmVerifierFlag} {
+			if !ctx.IsSet(f.Names()[0]) {
+				return fmt.Errorf("flag %s is required", f.Names()[0])
+			}
 		}
 	case config.TraceTypeAlphabet:
 		if !ctx.IsSet(AlphabetFlag.Name) {
@@ -139,14 +293,63 @@ func CheckRequired(ctx *cli.Context) error {
 	return nil
 }
 
+func checkCannonFlags(ctx *cli.Context) error {
+	if !ctx.IsSet(CannonBinFlag.Name) {
+		return fmt.Errorf("flag %s is required", CannonBinFlag.Name)
+	}
+	if err := checkServerFlags(ctx); err != nil {
+		return err
+	}
+	if !ctx.IsSet(CannonPreStateFlag.Name) {
+		return fmt.Errorf("flag %s is required", CannonPreStateFlag.Name)
+	}
+	if !ctx.IsSet(CannonDatadirFlag.Name) {
+		return fmt.Errorf("flag %s is required", CannonDatadirFlag.Name)
+	}
+	return nil
+}
+
+// checkServerFlags checks the flags of the op-program run by the cannon and zkwasm trace types.
+func checkServerFlags(ctx *cli.Context) error {
+	if !ctx.IsSet(CannonServerFlag.Name) {
+		return fmt.Errorf("flag %s is required", CannonServerFlag.Name)
+	}
+	if !ctx.IsSet(CannonNetworkFlag.Name) &&
+		!(ctx.IsSet(CannonRollupConfigFlag.Name) && ctx.IsSet(CannonL2GenesisFlag.Name)) {
+		return fmt.Errorf("flag %v or %v and %v is required",
+			CannonNetworkFlag.Name, CannonRollupConfigFlag.Name, CannonL2GenesisFlag.Name)
+	}
+	if !ctx.IsSet(CannonL2Flag.Name) {
+		return fmt.Errorf("flag %s is required", CannonL2Flag.Name)
+	}
+	return nil
+}
+
 // NewConfigFromCLI parses the Config from the provided flags or environment variables.
 func NewConfigFromCLI(ctx *cli.Context) (*config.Config, error) {
 	if err := CheckRequired(ctx); err != nil {
 		return nil, err
 	}
-	dgfAddress, err := opservice.ParseAddress(ctx.String(DGFAddressFlag.Name))
-	if err != nil {
-		return nil, err
+	var dgfAddress, factoryAddress common.Address
+	var err error
+	if ctx.IsSet(DGFAddressFlag.Name) {
+		dgfAddress, err = opservice.ParseAddress(ctx.String(DGFAddressFlag.Name))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if ctx.IsSet(GameFactoryAddressFlag.Name) {
+		factoryAddress, err = opservice.ParseAddress(ctx.String(GameFactoryAddressFlag.Name))
+		if err != nil {
+			return nil, err
+		}
+	}
+	var zkWasmVerifier common.Address
+	if ctx.IsSet(ZkWasmVerifierFlag.Name) {
+		zkWasmVerifier, err = opservice.ParseAddress(ctx.String(ZkWasmVerifierFlag.Name))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	txMgrConfig := txmgr.ReadCLIConfig(ctx)
@@ -158,13 +361,34 @@ func NewConfigFromCLI(ctx *cli.Context) (*config.Config, error) {
 		L1EthRpc:                ctx.String(L1EthRpcFlag.Name),
 		TraceType:               traceTypeFlag,
 		GameAddress:             dgfAddress,
+		GameFactoryAddress:      factoryAddress,
+		MaxConcurrency:          ctx.Uint(MaxConcurrencyFlag.Name),
+		GameFactoryStartBlock:   ctx.Uint64(GameFactoryStartBlockFlag.Name),
+		Datadir:                 ctx.String(DatadirFlag.Name),
+		GameBudget:              ctx.Uint64(GameBudgetFlag.Name),
+		TotalBudget:             ctx.Uint64(TotalBudgetFlag.Name),
+		DryRun:                  ctx.Bool(DryRunFlag.Name),
+		DryRunHttpAddr:          ctx.String(DryRunHttpAddrFlag.Name),
+		DryRunHttpPort:          ctx.Int(DryRunHttpPortFlag.Name),
 		AlphabetTrace:           ctx.String(AlphabetFlag.Name),
 		CannonBin:               ctx.String(CannonBinFlag.Name),
+		CannonServer:            ctx.String(CannonServerFlag.Name),
+		CannonNetwork:           ctx.String(CannonNetworkFlag.Name),
+		CannonRollupConfigPath:  ctx.String(CannonRollupConfigFlag.Name),
+		CannonL2GenesisPath:     ctx.String(CannonL2GenesisFlag.Name),
+		CannonTimeout:           ctx.Duration(CannonTimeoutFlag.Name),
 		CannonAbsolutePreState:  ctx.String(CannonPreStateFlag.Name),
 		CannonDatadir:           ctx.String(CannonDatadirFlag.Name),
 		CannonL2:                ctx.String(CannonL2Flag.Name),
+		SplitDepth:              ctx.Int(SplitDepthFlag.Name),
+		ZkWasmProver:            ctx.String(ZkWasmProverFlag.Name),
+		ZkWasmImage:             ctx.String(ZkWasmImageFlag.Name),
+		ZkWasmDatadir:           ctx.String(ZkWasmDatadirFlag.Name),
+		ZkWasmVerifier:          zkWasmVerifier,
 		AgreeWithProposedOutput: ctx.Bool(AgreeWithProposedOutputFlag.Name),
+		RollupRpc:               ctx.String(RollupRpcFlag.Name),
 		GameDepth:               ctx.Int(GameDepthFlag.Name),
 		TxMgrConfig:             txMgrConfig,
+		MetricsConfig:           opmetrics.ReadCLIConfig(ctx),
 	}, nil
 }
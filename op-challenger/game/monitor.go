@@ -0,0 +1,100 @@
+package game
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// faultGameType is the factory game type of fault dispute games.
+	faultGameType uint8 = 0
+	// maxBlockRange is the maximum number of L1 blocks to search for created games in a single request.
+	maxBlockRange = 2000
+)
+
+// GameSource finds the fault dispute games created by the factory.
+type GameSource interface {
+	GamesCreated(ctx context.Context, from uint64, to uint64) ([]common.Address, error)
+}
+
+// L1HeadSource provides the current L1 head block number.
+type L1HeadSource interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// factorySource finds created games from the DisputeGameCreated events of the factory.
+type factorySource struct {
+	filterer *bindings.DisputeGameFactoryFilterer
+}
+
+func (f *factorySource) GamesCreated(ctx context.Context, from uint64, to uint64) ([]common.Address, error) {
+	iter, err := f.filterer.FilterDisputeGameCreated(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil, []uint8{faultGameType}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var games []common.Address
+	for iter.Next() {
+		games = append(games, iter.Event.DisputeProxy)
+	}
+	return games, iter.Error()
+}
+
+// gameMonitor schedules the games created by the factory that have not been handled before.
+type gameMonitor struct {
+	logger log.Logger
+	source GameSource
+	l1     L1HeadSource
+	store  *store
+	// startBlock is the first block to search if the store has not been searched from yet, 0 for the current head.
+	startBlock uint64
+	scheduler  *scheduler
+}
+
+// discover searches the L1 blocks since the last search for created games up to the current head.
+// The first search starts from the start block, so a fresh datadir doesn't schedule all games of the L1 history.
+func (m *gameMonitor) discover(ctx context.Context) error {
+	head, err := m.l1.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch L1 head: %w", err)
+	}
+	from := m.store.NextBlock()
+	if from == 0 {
+		from = m.startBlock
+		if from == 0 {
+			from = head
+		}
+		m.logger.Info("Searching for created games from start block", "block", from)
+	}
+	for from <= head {
+		to := from + maxBlockRange - 1
+		if to > head {
+			to = head
+		}
+		games, err := m.source.GamesCreated(ctx, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to find games created in blocks %v to %v: %w", from, to, err)
+		}
+		for _, addr := range games {
+			if status, ok := m.store.Status(addr); ok {
+				m.logger.Debug("Skipping game already handled", "game", addr, "status", status)
+				continue
+			}
+			if err := m.store.SetStatus(addr, GameStatusInProgress); err != nil {
+				return err
+			}
+			m.logger.Info("Discovered game", "game", addr)
+			m.scheduler.Schedule(addr)
+		}
+		if err := m.store.SetNextBlock(to + 1); err != nil {
+			return err
+		}
+		from = to + 1
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// trackedGame is a game in progress, along with its player once loaded.
+// Only a single progressFn call at a time accesses the game.
+type trackedGame struct {
+	addr   common.Address
+	player GamePlayer
+	// expiry is the time the clock of the game expires, zero while unknown.
+	expiry time.Time
+	// loadFailures is the number of failed attempts to load the player, retried from retryAt.
+	loadFailures int
+	retryAt      time.Time
+
+	// busy is set while the game is being progressed, guarded by the scheduler.
+	busy bool
+}
+
+// progressFn makes a single round of progress on a game, returning true once the game is done with.
+type progressFn func(ctx context.Context, game *trackedGame) bool
+
+// scheduler progresses all scheduled games, with at most maxConcurrency games progressing at once.
+// Each tick starts a round of progress for the games that are not still busy with the previous one,
+// in order of their last round, so every game is serviced in turn however many games are scheduled.
+type scheduler struct {
+	maxConcurrency int
+	progress       progressFn
+
+	mu      sync.Mutex
+	games   []*trackedGame
+	running int
+	wg      sync.WaitGroup
+}
+
+func newScheduler(maxConcurrency uint, progress progressFn) *scheduler {
+	return &scheduler{
+		maxConcurrency: int(maxConcurrency),
+		progress:       progress,
+	}
+}
+
+// Schedule adds the game to the games progressed each tick, unless it is already scheduled.
+func (s *scheduler) Schedule(addr common.Address) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, game := range s.games {
+		if game.addr == addr {
+			return
+		}
+	}
+	s.games = append(s.games, &trackedGame{addr: addr})
+}
+
+// Scheduled returns the number of games not yet done with.
+func (s *scheduler) Scheduled() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.games)
+}
+
+// Tick starts a round of progress for the games not busy, while there are free slots.
+// Games started are moved to the back of the queue, games left waiting are started first on the next tick.
+func (s *scheduler) Tick(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var waiting, started []*trackedGame
+	for _, game := range s.games {
+		if game.busy || s.running >= s.maxConcurrency || ctx.Err() != nil {
+			waiting = append(waiting, game)
+			continue
+		}
+		game.busy = true
+		s.running++
+		s.wg.Add(1)
+		go s.run(ctx, game)
+		started = append(started, game)
+	}
+	s.games = append(waiting, started...)
+}
+
+// Wait waits for all rounds in progress to return.
+func (s *scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *scheduler) run(ctx context.Context, game *trackedGame) {
+	defer s.wg.Done()
+	done := s.progress(ctx, game)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	game.busy = false
+	s.running--
+	if !done {
+		return
+	}
+	for i, g := range s.games {
+		if g == game {
+			s.games = append(s.games[:i], s.games[i+1:]...)
+			return
+		}
+	}
+}
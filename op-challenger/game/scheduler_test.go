@@ -0,0 +1,81 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerBoundsConcurrency(t *testing.T) {
+	started := make(chan common.Address, 10)
+	release := make(chan struct{})
+	s := newScheduler(2, func(ctx context.Context, game *trackedGame) bool {
+		started <- game.addr
+		<-release
+		return false
+	})
+	for i := byte(1); i <= 5; i++ {
+		s.Schedule(common.Address{i})
+	}
+
+	// Only the first two games are progressed, in order of scheduling
+	s.Tick(context.Background())
+	require.ElementsMatch(t, []common.Address{{1}, {2}}, []common.Address{<-started, <-started})
+
+	// No slot is free while both are busy
+	s.Tick(context.Background())
+	require.Empty(t, started)
+
+	// A finished round frees a slot for the games that waited longest
+	release <- struct{}{}
+	release <- struct{}{}
+	s.Wait()
+	s.Tick(context.Background())
+	require.ElementsMatch(t, []common.Address{{3}, {4}}, []common.Address{<-started, <-started})
+
+	close(release)
+	s.Wait()
+	s.Tick(context.Background())
+	s.Wait()
+	require.ElementsMatch(t, []common.Address{{5}, {1}}, []common.Address{<-started, <-started})
+	require.Equal(t, 5, s.Scheduled(), "games are kept until done with")
+}
+
+func TestSchedulerServicesEveryGame(t *testing.T) {
+	var mu sync.Mutex
+	rounds := make(map[common.Address]int)
+	s := newScheduler(2, func(ctx context.Context, game *trackedGame) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		rounds[game.addr]++
+		return rounds[game.addr] == 3
+	})
+	for i := byte(1); i <= 5; i++ {
+		s.Schedule(common.Address{i})
+	}
+	s.Schedule(common.Address{1})
+	for i := 0; i < 8; i++ {
+		s.Tick(context.Background())
+		s.Wait()
+	}
+	require.Zero(t, s.Scheduled(), "games should be removed once done with")
+	for i := byte(1); i <= 5; i++ {
+		require.Equalf(t, 3, rounds[common.Address{i}], "rounds of game %v", i)
+	}
+}
+
+func TestSchedulerStopsStartingGamesWhenDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := newScheduler(1, func(ctx context.Context, game *trackedGame) bool {
+		t.Fatal("should not progress games once done")
+		return false
+	})
+	s.Schedule(common.Address{1})
+	s.Tick(ctx)
+	s.Wait()
+	require.Equal(t, 1, s.Scheduled())
+}
@@ -0,0 +1,227 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-challenger/config"
+	"github.com/ethereum-optimism/optimism/op-challenger/dryrun"
+	"github.com/ethereum-optimism/optimism/op-challenger/fault"
+	"github.com/ethereum-optimism/optimism/op-challenger/metrics"
+	"github.com/ethereum-optimism/optimism/op-service/clock"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	discoveryInterval = 12 * time.Second
+	playInterval      = 300 * time.Millisecond
+	// loadRetryInterval is the time to wait before loading a game again after a failed attempt.
+	loadRetryInterval = 12 * time.Second
+	// maxLoadAttempts is the number of failed attempts to load a game after which it is dropped,
+	// if its clock expiry is still unknown. Games with a known expiry are retried until it passes.
+	maxLoadAttempts = 50
+)
+
+// GamePlayer progresses a single game.
+type GamePlayer interface {
+	// ProgressGame returns true if the game is complete.
+	ProgressGame(ctx context.Context) bool
+}
+
+// createPlayerFn creates the player for a game, along with the time the clock of the game expires.
+// The expiry is returned whenever it is known, also if creating the player fails.
+type createPlayerFn func(ctx context.Context, addr common.Address) (GamePlayer, time.Time, error)
+
+// Service discovers the fault dispute games created by a factory and plays them.
+type Service struct {
+	logger  log.Logger
+	monitor *gameMonitor
+	store   *store
+	clock   clock.Clock
+
+	createPlayer      createPlayerFn
+	maxConcurrency    uint
+	discoveryInterval time.Duration
+	playInterval      time.Duration
+	loadRetryInterval time.Duration
+}
+
+// NewService creates a Service for the game factory at cfg.GameFactoryAddress.
+// Every game is played with the settings of cfg, cannon data is stored in a subdirectory per game.
+// In dry-run mode, actions records the transactions of all games instead of sending them.
+func NewService(ctx context.Context, logger log.Logger, cfg *config.Config, m metrics.Metricer, actions *dryrun.Log) (*Service, error) {
+	client, err := ethclient.Dial(cfg.L1EthRpc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial L1: %w", err)
+	}
+
+	txMgr, err := txmgr.NewSimpleTxManager("challenger", logger, m, cfg.TxMgrConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the transaction manager: %w", err)
+	}
+
+	rollupClient, err := fault.DialRollupClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	filterer, err := bindings.NewDisputeGameFactoryFilterer(cfg.GameFactoryAddress, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind the dispute game factory contract: %w", err)
+	}
+
+	store, err := openStore(cfg.Datadir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The budget is shared by all games, to bound the total spend, and persisted next to the games across restarts
+	budget, err := fault.OpenBudgetFromConfig(cfg, cfg.Datadir)
+	if err != nil {
+		return nil, err
+	}
+	createPlayer := func(ctx context.Context, addr common.Address) (GamePlayer, time.Time, error) {
+		gameCfg := *cfg
+		gameCfg.GameAddress = addr
+		if gameCfg.CannonDatadir != "" {
+			gameCfg.CannonDatadir = filepath.Join(cfg.CannonDatadir, addr.Hex())
+		}
+		if gameCfg.ZkWasmDatadir != "" {
+			gameCfg.ZkWasmDatadir = filepath.Join(cfg.ZkWasmDatadir, addr.Hex())
+		}
+		expiry, err := fetchExpiry(ctx, addr, client)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		player, err := fault.NewGamePlayer(ctx, logger.New("game", addr), &gameCfg, m, txMgr, client, rollupClient, budget, actions)
+		if err != nil {
+			return nil, expiry, err
+		}
+		return player, expiry, nil
+	}
+
+	return newService(logger, &factorySource{filterer}, client, store, cfg.GameFactoryStartBlock, clock.SystemClock, createPlayer, cfg.MaxConcurrency), nil
+}
+
+func newService(logger log.Logger, source GameSource, l1 L1HeadSource, store *store, startBlock uint64, cl clock.Clock, createPlayer createPlayerFn, maxConcurrency uint) *Service {
+	return &Service{
+		logger:            logger,
+		monitor:           &gameMonitor{logger: logger, source: source, l1: l1, store: store, startBlock: startBlock},
+		store:             store,
+		clock:             cl,
+		createPlayer:      createPlayer,
+		maxConcurrency:    maxConcurrency,
+		discoveryInterval: discoveryInterval,
+		playInterval:      playInterval,
+		loadRetryInterval: loadRetryInterval,
+	}
+}
+
+// fetchExpiry returns the time the game clock expires, after which no more moves can be made.
+func fetchExpiry(ctx context.Context, addr common.Address, client bind.ContractCaller) (time.Time, error) {
+	caller, err := bindings.NewFaultDisputeGameCaller(addr, client)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to bind the fault dispute game contract: %w", err)
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	start, err := caller.GameStart(opts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch game start: %w", err)
+	}
+	duration, err := caller.GAMEDURATION(opts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch game duration: %w", err)
+	}
+	return time.Unix(int64(start+duration), 0), nil
+}
+
+// Run plays the games still in progress from a previous run and discovers new games until ctx is done.
+// Every play interval, each game in progress gets a round of progress, with at most maxConcurrency at once.
+func (s *Service) Run(ctx context.Context) error {
+	scheduler := newScheduler(s.maxConcurrency, s.progressGame)
+	defer scheduler.Wait()
+	s.monitor.scheduler = scheduler
+
+	inProgress := s.store.InProgress()
+	s.logger.Info("Monitoring dispute game factory", "resumedGames", len(inProgress), "maxConcurrency", s.maxConcurrency)
+	for _, addr := range inProgress {
+		scheduler.Schedule(addr)
+	}
+	var nextDiscovery time.Time
+	for {
+		if now := s.clock.Now(); !now.Before(nextDiscovery) {
+			if err := s.monitor.discover(ctx); err != nil {
+				s.logger.Error("Failed to discover games", "err", err)
+			}
+			nextDiscovery = now.Add(s.discoveryInterval)
+		}
+		scheduler.Tick(ctx)
+		select {
+		case <-s.clock.After(s.playInterval):
+		// Continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// progressGame makes a single round of progress on the game, returning true once it is complete or dropped.
+// Once expired, the game gets a final chance to resolve, then is dropped.
+// A game that fails to load is retried every load retry interval until its clock expires,
+// or until maxLoadAttempts attempts failed if the expiry is unknown.
+func (s *Service) progressGame(ctx context.Context, game *trackedGame) bool {
+	logger := s.logger.New("game", game.addr)
+	if game.player == nil {
+		if s.clock.Now().Before(game.retryAt) {
+			return false
+		}
+		player, expiry, err := s.createPlayer(ctx, game.addr)
+		if !expiry.IsZero() {
+			game.expiry = expiry
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			game.loadFailures++
+			logger.Error("Failed to create game player", "attempts", game.loadFailures, "err", err)
+			if game.expiry.IsZero() && game.loadFailures >= maxLoadAttempts {
+				logger.Error("Dropping game that repeatedly failed to load with unknown clock expiry")
+				s.setStatus(logger, game.addr, GameStatusFailed)
+				return true
+			}
+			if !game.expiry.IsZero() && !s.clock.Now().Before(game.expiry) {
+				logger.Warn("Dropping game that failed to load before its clock expiry", "expiry", game.expiry)
+				s.setStatus(logger, game.addr, GameStatusExpired)
+				return true
+			}
+			game.retryAt = s.clock.Now().Add(s.loadRetryInterval)
+			return false
+		}
+		game.player = player
+	}
+	expired := !s.clock.Now().Before(game.expiry)
+	if game.player.ProgressGame(ctx) {
+		s.setStatus(logger, game.addr, GameStatusComplete)
+		return true
+	}
+	if expired {
+		logger.Warn("Dropping game past its clock expiry", "expiry", game.expiry)
+		s.setStatus(logger, game.addr, GameStatusExpired)
+		return true
+	}
+	return false
+}
+
+func (s *Service) setStatus(logger log.Logger, addr common.Address, status GameStatus) {
+	if err := s.store.SetStatus(addr, status); err != nil {
+		logger.Error("Failed to record game status", "status", status, "err", err)
+	}
+}
@@ -0,0 +1,283 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-service/clock"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscover(t *testing.T) {
+	setup := func(t *testing.T, head uint64, startBlock uint64) (*gameMonitor, *stubGameSource, *[]common.Address) {
+		store, err := openStore(t.TempDir())
+		require.NoError(t, err)
+		source := &stubGameSource{games: make(map[uint64][]common.Address)}
+		var mu sync.Mutex
+		var played []common.Address
+		s := newScheduler(10, func(ctx context.Context, game *trackedGame) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			played = append(played, game.addr)
+			return true
+		})
+		t.Cleanup(s.Wait)
+		return &gameMonitor{
+			logger:     testlog.Logger(t, log.LvlInfo),
+			source:     source,
+			l1:         stubL1Head(head),
+			store:      store,
+			startBlock: startBlock,
+			scheduler:  s,
+		}, source, &played
+	}
+
+	t.Run("SchedulesNewGames", func(t *testing.T) {
+		monitor, source, _ := setup(t, 100, 10)
+		source.games[10] = []common.Address{{0xaa}}
+		source.games[100] = []common.Address{{0xbb}}
+		require.NoError(t, monitor.discover(context.Background()))
+		require.Equal(t, 2, monitor.scheduler.Scheduled())
+		require.Equal(t, uint64(101), monitor.store.NextBlock())
+		require.ElementsMatch(t, []common.Address{{0xaa}, {0xbb}}, monitor.store.InProgress())
+		require.Equal(t, [][2]uint64{{10, 100}}, source.requests)
+	})
+
+	t.Run("StartsFromHead", func(t *testing.T) {
+		monitor, source, _ := setup(t, 100, 0)
+		source.games[10] = []common.Address{{0xaa}}
+		source.games[100] = []common.Address{{0xbb}}
+		require.NoError(t, monitor.discover(context.Background()))
+		require.Equal(t, []common.Address{{0xbb}}, monitor.store.InProgress())
+		require.Equal(t, [][2]uint64{{100, 100}}, source.requests)
+	})
+
+	t.Run("SkipsHandledGames", func(t *testing.T) {
+		monitor, source, played := setup(t, 100, 1)
+		source.games[10] = []common.Address{{0xaa}, {0xbb}}
+		require.NoError(t, monitor.store.SetStatus(common.Address{0xaa}, GameStatusComplete))
+		require.NoError(t, monitor.discover(context.Background()))
+		monitor.scheduler.Tick(context.Background())
+		monitor.scheduler.Wait()
+		require.Equal(t, []common.Address{{0xbb}}, *played)
+	})
+
+	t.Run("ResumesFromNextBlock", func(t *testing.T) {
+		monitor, source, _ := setup(t, 100, 10)
+		require.NoError(t, monitor.store.SetNextBlock(50))
+		require.NoError(t, monitor.discover(context.Background()))
+		require.Equal(t, [][2]uint64{{50, 100}}, source.requests)
+	})
+
+	t.Run("LimitsBlockRange", func(t *testing.T) {
+		monitor, source, _ := setup(t, 2*maxBlockRange+5, 1)
+		require.NoError(t, monitor.discover(context.Background()))
+		require.Equal(t, [][2]uint64{
+			{1, maxBlockRange},
+			{maxBlockRange + 1, 2 * maxBlockRange},
+			{2*maxBlockRange + 1, 2*maxBlockRange + 5},
+		}, source.requests)
+	})
+
+	t.Run("DoesNotAdvanceOnError", func(t *testing.T) {
+		monitor, source, _ := setup(t, 100, 1)
+		source.err = errors.New("boom")
+		require.ErrorIs(t, monitor.discover(context.Background()), source.err)
+		require.Zero(t, monitor.store.NextBlock())
+	})
+}
+
+func TestProgressGame(t *testing.T) {
+	addr := common.Address{0xaa}
+	now := time.Unix(1000, 0)
+	setup := func(t *testing.T, createPlayer createPlayerFn) *Service {
+		store, err := openStore(t.TempDir())
+		require.NoError(t, err)
+		require.NoError(t, store.SetStatus(addr, GameStatusInProgress))
+		s := newService(testlog.Logger(t, log.LvlInfo), &stubGameSource{}, stubL1Head(0), store, 0, &fastClock{now: now}, createPlayer, 1)
+		s.loadRetryInterval = 0
+		return s
+	}
+	// play progresses the game until done with, returning the number of rounds
+	play := func(ctx context.Context, s *Service) int {
+		game := &trackedGame{addr: addr}
+		rounds := 1
+		for ; !s.progressGame(ctx, game) && ctx.Err() == nil; rounds++ {
+			require.Less(t, rounds, 1000, "game not done with")
+		}
+		return rounds
+	}
+	requireStatus := func(t *testing.T, s *Service, expected GameStatus) {
+		status, ok := s.store.Status(addr)
+		require.True(t, ok)
+		require.Equal(t, expected, status)
+	}
+
+	t.Run("Complete", func(t *testing.T) {
+		player := &stubPlayer{completeAfter: 3}
+		s := setup(t, func(ctx context.Context, addr common.Address) (GamePlayer, time.Time, error) {
+			return player, now.Add(time.Hour), nil
+		})
+		require.Equal(t, 3, play(context.Background(), s))
+		require.Equal(t, 3, player.calls)
+		requireStatus(t, s, GameStatusComplete)
+	})
+
+	t.Run("DropExpired", func(t *testing.T) {
+		player := &stubPlayer{completeAfter: 3}
+		s := setup(t, func(ctx context.Context, addr common.Address) (GamePlayer, time.Time, error) {
+			return player, now, nil
+		})
+		play(context.Background(), s)
+		require.Equal(t, 1, player.calls, "should attempt to resolve the game once")
+		requireStatus(t, s, GameStatusExpired)
+	})
+
+	t.Run("ResolvedAfterExpiry", func(t *testing.T) {
+		player := &stubPlayer{completeAfter: 1}
+		s := setup(t, func(ctx context.Context, addr common.Address) (GamePlayer, time.Time, error) {
+			return player, now.Add(-time.Hour), nil
+		})
+		play(context.Background(), s)
+		requireStatus(t, s, GameStatusComplete)
+	})
+
+	t.Run("RetryCreatePlayer", func(t *testing.T) {
+		player := &stubPlayer{completeAfter: 1}
+		attempts := 0
+		s := setup(t, func(ctx context.Context, addr common.Address) (GamePlayer, time.Time, error) {
+			attempts++
+			if attempts < 3 {
+				return nil, time.Time{}, errors.New("boom")
+			}
+			return player, now.Add(time.Hour), nil
+		})
+		play(context.Background(), s)
+		require.Equal(t, 3, attempts)
+		requireStatus(t, s, GameStatusComplete)
+	})
+
+	t.Run("WaitBeforeRetry", func(t *testing.T) {
+		clk := &fastClock{now: now}
+		attempts := 0
+		s := setup(t, func(ctx context.Context, addr common.Address) (GamePlayer, time.Time, error) {
+			attempts++
+			return nil, now.Add(time.Hour), errors.New("boom")
+		})
+		s.clock = clk
+		s.loadRetryInterval = time.Minute
+		game := &trackedGame{addr: addr}
+		require.False(t, s.progressGame(context.Background(), game))
+		require.False(t, s.progressGame(context.Background(), game))
+		require.Equal(t, 1, attempts)
+		clk.now = clk.now.Add(time.Minute)
+		require.False(t, s.progressGame(context.Background(), game))
+		require.Equal(t, 2, attempts)
+	})
+
+	t.Run("DropExpiredFailingToLoad", func(t *testing.T) {
+		attempts := 0
+		s := setup(t, func(ctx context.Context, addr common.Address) (GamePlayer, time.Time, error) {
+			attempts++
+			return nil, now, errors.New("boom")
+		})
+		play(context.Background(), s)
+		require.Equal(t, 1, attempts)
+		requireStatus(t, s, GameStatusExpired)
+	})
+
+	t.Run("RetryUntilExpiry", func(t *testing.T) {
+		clk := &fastClock{now: now}
+		attempts := 0
+		s := setup(t, func(ctx context.Context, addr common.Address) (GamePlayer, time.Time, error) {
+			attempts++
+			if attempts == 1 {
+				// expiry unknown
+				return nil, time.Time{}, errors.New("boom")
+			}
+			clk.now = clk.now.Add(time.Minute)
+			return nil, now.Add(3 * time.Minute), errors.New("boom")
+		})
+		s.clock = clk
+		play(context.Background(), s)
+		require.Equal(t, 4, attempts)
+		requireStatus(t, s, GameStatusExpired)
+	})
+
+	t.Run("DropFailingToLoadWithUnknownExpiry", func(t *testing.T) {
+		attempts := 0
+		s := setup(t, func(ctx context.Context, addr common.Address) (GamePlayer, time.Time, error) {
+			attempts++
+			return nil, time.Time{}, errors.New("boom")
+		})
+		play(context.Background(), s)
+		require.Equal(t, maxLoadAttempts, attempts)
+		requireStatus(t, s, GameStatusFailed)
+	})
+
+	t.Run("StopWhenDone", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		s := setup(t, func(ctx context.Context, addr common.Address) (GamePlayer, time.Time, error) {
+			cancel()
+			return nil, time.Time{}, errors.New("boom")
+		})
+		play(ctx, s)
+		requireStatus(t, s, GameStatusInProgress)
+	})
+}
+
+type stubGameSource struct {
+	games    map[uint64][]common.Address
+	requests [][2]uint64
+	err      error
+}
+
+func (s *stubGameSource) GamesCreated(_ context.Context, from uint64, to uint64) ([]common.Address, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.requests = append(s.requests, [2]uint64{from, to})
+	var games []common.Address
+	for block := from; block <= to; block++ {
+		games = append(games, s.games[block]...)
+	}
+	return games, nil
+}
+
+type stubL1Head uint64
+
+func (s stubL1Head) BlockNumber(_ context.Context) (uint64, error) {
+	return uint64(s), nil
+}
+
+type stubPlayer struct {
+	completeAfter int
+	calls         int
+}
+
+func (p *stubPlayer) ProgressGame(_ context.Context) bool {
+	p.calls++
+	return p.calls >= p.completeAfter
+}
+
+// fastClock is a clock stopped at now, with timers that fire immediately.
+type fastClock struct {
+	clock.Clock
+	now time.Time
+}
+
+func (c *fastClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fastClock) After(_ time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
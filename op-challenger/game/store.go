@@ -0,0 +1,123 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const stateFile = "games.json"
+
+// GameStatus is the progress of the challenger on a game, persisted across restarts.
+type GameStatus string
+
+const (
+	// GameStatusInProgress is a game that is still being played.
+	GameStatusInProgress GameStatus = "in_progress"
+	// GameStatusComplete is a game that has been resolved.
+	GameStatusComplete GameStatus = "complete"
+	// GameStatusExpired is a game that was dropped after its clock expired, without being resolved.
+	GameStatusExpired GameStatus = "expired"
+	// GameStatusFailed is a game that was dropped after repeatedly failing to load, with its clock expiry unknown.
+	GameStatusFailed GameStatus = "failed"
+)
+
+type state struct {
+	// NextBlock is the first L1 block not yet searched for created games, 0 before the first search.
+	NextBlock uint64                        `json:"nextBlock"`
+	Games     map[common.Address]GameStatus `json:"games"`
+}
+
+// store persists the games the challenger has handled in a JSON file in the datadir.
+type store struct {
+	mu    sync.Mutex
+	path  string
+	state state
+}
+
+// openStore loads the games handled in dir, or starts with no games handled if dir has no state yet.
+func openStore(dir string) (*store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create datadir %v: %w", dir, err)
+	}
+	s := &store{
+		path:  filepath.Join(dir, stateFile),
+		state: state{Games: make(map[common.Address]GameStatus)},
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read game state %v: %w", s.path, err)
+	}
+	if err := json.Unmarshal(data, &s.state); err != nil {
+		return nil, fmt.Errorf("failed to parse game state %v: %w", s.path, err)
+	}
+	if s.state.Games == nil {
+		s.state.Games = make(map[common.Address]GameStatus)
+	}
+	return s, nil
+}
+
+func (s *store) NextBlock() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.NextBlock
+}
+
+func (s *store) SetNextBlock(block uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.NextBlock = block
+	return s.save()
+}
+
+// Status returns the status of the game, and false if the game has not been seen before.
+func (s *store) Status(addr common.Address) (GameStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status, ok := s.state.Games[addr]
+	return status, ok
+}
+
+func (s *store) SetStatus(addr common.Address, status GameStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Games[addr] = status
+	return s.save()
+}
+
+// InProgress returns the games that are still being played.
+func (s *store) InProgress() []common.Address {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var games []common.Address
+	for addr, status := range s.state.Games {
+		if status == GameStatusInProgress {
+			games = append(games, addr)
+		}
+	}
+	return games
+}
+
+// save writes the state to a temporary file first, so an interrupted write can't corrupt the state.
+// The caller must hold s.mu.
+func (s *store) save() error {
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write game state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace game state: %w", err)
+	}
+	return nil
+}
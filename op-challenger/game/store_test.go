@@ -0,0 +1,49 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		s, err := openStore(filepath.Join(t.TempDir(), "new"))
+		require.NoError(t, err)
+		require.Zero(t, s.NextBlock())
+		require.Empty(t, s.InProgress())
+		_, ok := s.Status(common.Address{0xaa})
+		require.False(t, ok)
+	})
+
+	t.Run("PersistAcrossRestart", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := openStore(dir)
+		require.NoError(t, err)
+		require.NoError(t, s.SetNextBlock(42))
+		require.NoError(t, s.SetStatus(common.Address{0xaa}, GameStatusInProgress))
+		require.NoError(t, s.SetStatus(common.Address{0xbb}, GameStatusComplete))
+		require.NoError(t, s.SetStatus(common.Address{0xcc}, GameStatusExpired))
+
+		reopened, err := openStore(dir)
+		require.NoError(t, err)
+		require.Equal(t, uint64(42), reopened.NextBlock())
+		require.Equal(t, []common.Address{{0xaa}}, reopened.InProgress())
+		status, ok := reopened.Status(common.Address{0xbb})
+		require.True(t, ok)
+		require.Equal(t, GameStatusComplete, status)
+		status, ok = reopened.Status(common.Address{0xcc})
+		require.True(t, ok)
+		require.Equal(t, GameStatusExpired, status)
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, stateFile), []byte("{"), 0644))
+		_, err := openStore(dir)
+		require.ErrorContains(t, err, "failed to parse game state")
+	})
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	opmetrics "github.com/ethereum-optimism/optimism/op-service/metrics"
+	txmetrics "github.com/ethereum-optimism/optimism/op-service/txmgr/metrics"
+)
+
+const Namespace = "op_challenger"
+
+type Metricer interface {
+	RecordInfo(version string)
+	RecordUp()
+
+	// Record Tx metrics
+	txmetrics.TxMetricer
+
+	RecordGameAgreement(agree bool)
+	RecordGameSkipped()
+}
+
+type Metrics struct {
+	ns       string
+	registry *prometheus.Registry
+	factory  opmetrics.Factory
+
+	txmetrics.TxMetrics
+
+	info prometheus.GaugeVec
+	up   prometheus.Gauge
+
+	gamesAgreed    prometheus.Counter
+	gamesDisagreed prometheus.Counter
+	gamesSkipped   prometheus.Counter
+}
+
+var _ Metricer = (*Metrics)(nil)
+
+func NewMetrics() *Metrics {
+	registry := opmetrics.NewRegistry()
+	factory := opmetrics.With(registry)
+
+	return &Metrics{
+		ns:       Namespace,
+		registry: registry,
+		factory:  factory,
+
+		TxMetrics: txmetrics.MakeTxMetrics(Namespace, factory),
+
+		info: *factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "info",
+			Help:      "Pseudo-metric tracking version and config info",
+		}, []string{
+			"version",
+		}),
+		up: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "up",
+			Help:      "1 if the op-challenger has finished starting up",
+		}),
+		gamesAgreed: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "games_agreed",
+			Help:      "Number of games with a root claim matching the output root of the rollup node",
+		}),
+		gamesDisagreed: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "games_disagreed",
+			Help:      "Number of games with a root claim not matching the output root of the rollup node",
+		}),
+		gamesSkipped: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "games_skipped",
+			Help:      "Number of attempts to check a root claim that failed, e.g. because the rollup node has no output at the block yet",
+		}),
+	}
+}
+
+func (m *Metrics) Serve(ctx context.Context, host string, port int) error {
+	return opmetrics.ListenAndServe(ctx, m.registry, host, port)
+}
+
+func (m *Metrics) StartBalanceMetrics(ctx context.Context,
+	l log.Logger, client *ethclient.Client, account common.Address) {
+	opmetrics.LaunchBalanceMetrics(ctx, l, m.registry, m.ns, client, account)
+}
+
+// RecordInfo sets a pseudo-metric that contains versioning and
+// config info for the op-challenger.
+func (m *Metrics) RecordInfo(version string) {
+	m.info.WithLabelValues(version).Set(1)
+}
+
+// RecordUp sets the up metric to 1.
+func (m *Metrics) RecordUp() {
+	m.up.Set(1)
+}
+
+// RecordGameAgreement records whether the root claim of a game matches the output root of the rollup node.
+func (m *Metrics) RecordGameAgreement(agree bool) {
+	if agree {
+		m.gamesAgreed.Inc()
+	} else {
+		m.gamesDisagreed.Inc()
+	}
+}
+
+// RecordGameSkipped records a game that could not be checked against the rollup node.
+func (m *Metrics) RecordGameSkipped() {
+	m.gamesSkipped.Inc()
+}
+
+func (m *Metrics) Document() []opmetrics.DocumentedMetric {
+	return m.factory.Document()
+}
@@ -39,6 +39,46 @@ func (o *DebugClient) CodeByHash(ctx context.Context, hash common.Hash) ([]byte,
 	return code, nil
 }
 
+// PrestateAccount is the state of an account accessed by a block, before the block is executed.
+// Only the code and the accessed storage slots are kept.
+type PrestateAccount struct {
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// BlockPrestate retrieves the accounts and storage slots accessed by the transactions of a block,
+// using the prestate tracer of the debug namespace.
+func (o *DebugClient) BlockPrestate(ctx context.Context, blockHash common.Hash) (map[common.Address]PrestateAccount, error) {
+	var traces []struct {
+		Result map[common.Address]PrestateAccount `json:"result"`
+		Error  string                             `json:"error"`
+	}
+	err := o.callContext(ctx, &traces, "debug_traceBlockByHash", blockHash, map[string]any{"tracer": "prestateTracer"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace block %s: %w", blockHash, err)
+	}
+	accounts := make(map[common.Address]PrestateAccount)
+	for i, trace := range traces {
+		if trace.Error != "" {
+			return nil, fmt.Errorf("failed to trace tx %d of block %s: %s", i, blockHash, trace.Error)
+		}
+		for addr, acc := range trace.Result {
+			merged, ok := accounts[addr]
+			if !ok {
+				merged = PrestateAccount{Code: acc.Code, Storage: make(map[common.Hash]common.Hash)}
+			}
+			for k, v := range acc.Storage {
+				if _, ok := merged.Storage[k]; !ok {
+					// Keep the value of the first access, later transactions see intermediate state
+					merged.Storage[k] = v
+				}
+			}
+			accounts[addr] = merged
+		}
+	}
+	return accounts, nil
+}
+
 func (o *DebugClient) dbGet(ctx context.Context, key []byte) ([]byte, error) {
 	var node hexutil.Bytes
 	err := o.callContext(ctx, &node, "debug_dbGet", hexutil.Encode(key))
@@ -20,6 +20,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/ethereum-optimism/optimism/op-node/client"
 	"github.com/ethereum-optimism/optimism/op-node/eth"
@@ -391,6 +392,42 @@ func (s *EthClient) GetProof(ctx context.Context, address common.Address, storag
 	return getProofResponse, nil
 }
 
+// ProofRequest is an account, and storage slots of the account, to fetch a proof of.
+type ProofRequest struct {
+	Address common.Address
+	Storage []common.Hash
+}
+
+// GetProofs fetches the proofs of multiple accounts at the given block in a single batch request.
+// Unlike GetProof, the storage proof keys are not checked, the proofs are only as good as the RPC provider.
+func (s *EthClient) GetProofs(ctx context.Context, requests []ProofRequest, blockTag string) ([]*eth.AccountResult, error) {
+	results := make([]*eth.AccountResult, len(requests))
+	batch := make([]rpc.BatchElem, len(requests))
+	for i, req := range requests {
+		storage := req.Storage
+		if storage == nil {
+			storage = []common.Hash{}
+		}
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getProof",
+			Args:   []any{req.Address, storage, blockTag},
+			Result: &results[i],
+		}
+	}
+	if err := s.client.BatchCallContext(ctx, batch); err != nil {
+		return nil, err
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("failed to fetch proof of account %s: %w", requests[i].Address, elem.Error)
+		}
+		if results[i] == nil {
+			return nil, fmt.Errorf("no proof of account %s: %w", requests[i].Address, ethereum.NotFound)
+		}
+	}
+	return results, nil
+}
+
 // GetStorageAt returns the storage value at the given address and storage slot, **without verifying the correctness of the result**.
 // This should only ever be used as alternative to GetProof when the user opts in.
 // E.g. Erigon L1 node users may have to use this, since Erigon does not support eth_getProof, see https://github.com/ledgerwatch/erigon/issues/1349
@@ -5,7 +5,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
-	"os"
 )
 
 // OracleClient implements the Oracle by writing the pre-image key to the given stream,
@@ -46,17 +45,18 @@ func (o *OracleClient) Get(key Key) []byte {
 
 // OracleServer serves the pre-image requests of the OracleClient, implementing the same protocol as the onchain VM.
 type OracleServer struct {
-	rw io.ReadWriter
+	rw       io.ReadWriter
+	recorder PreimageRecorder
 }
 
-func NewOracleServer(rw io.ReadWriter) *OracleServer {
-	return &OracleServer{rw: rw}
+// NewOracleServer creates an OracleServer serving requests read from rw.
+// Every served pre-image is passed to the recorder, if not nil.
+func NewOracleServer(rw io.ReadWriter, recorder PreimageRecorder) *OracleServer {
+	return &OracleServer{rw: rw, recorder: recorder}
 }
 
 type PreimageGetter func(key [32]byte) ([]byte, error)
 
-var PreimageFile *os.File
-
 func (o *OracleServer) NextPreimageRequest(getPreimage PreimageGetter) error {
 	var key [32]byte
 	if _, err := io.ReadFull(o.rw, key[:]); err != nil {
@@ -70,20 +70,9 @@ func (o *OracleServer) NextPreimageRequest(getPreimage PreimageGetter) error {
 		return fmt.Errorf("failed to serve pre-image %s request: %w", hex.EncodeToString(key[:]), err)
 	}
 
-	if PreimageFile != nil {
-		// write length & data to preimages.bin
-		binary.Write(PreimageFile, binary.BigEndian, uint64(len(value)))
-		_, err := PreimageFile.Write(value)
-		if err != nil {
-			return fmt.Errorf("failed to dump pre-image binary file: %w", err)
-		}
-
-		// padding some zeros to make preimages length can be divided by 8
-		if len(value)%8 != 0 {
-			_, err := PreimageFile.Write(make([]byte, 8-len(value)%8))
-			if err != nil {
-				return fmt.Errorf("failed to dump pre-image binary file: %w", err)
-			}
+	if o.recorder != nil {
+		if err := o.recorder.RecordPreimage(key, value); err != nil {
+			return fmt.Errorf("failed to record pre-image %s: %w", hex.EncodeToString(key[:]), err)
 		}
 	}
 
@@ -26,7 +26,8 @@ func TestOracle(t *testing.T) {
 	testPreimage := func(preimages ...[]byte) {
 		a, b := bidirectionalPipe()
 		cl := NewOracleClient(a)
-		srv := NewOracleServer(b)
+		recorder := NewMemRecorder()
+		srv := NewOracleServer(b, recorder)
 
 		preimageByHash := make(map[[32]byte][]byte)
 		for _, p := range preimages {
@@ -59,6 +60,12 @@ func TestOracle(t *testing.T) {
 			}()
 			wg.Wait()
 		}
+		recorded := recorder.Preimages()
+		require.Len(t, recorded, len(preimages), "should record every served pre-image")
+		for i, p := range preimages {
+			require.Equal(t, Keccak256Key(Keccak256(p)).PreimageKey(), recorded[i].Key)
+			require.True(t, bytes.Equal(p, recorded[i].Value))
+		}
 	}
 	t.Run("empty preimage", func(t *testing.T) {
 		testPreimage([]byte{})
@@ -0,0 +1,183 @@
+package preimage
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// PreimageRecorder is notified of every pre-image served by an OracleServer, in the order they are requested.
+type PreimageRecorder interface {
+	// RecordPreimage records the value served for the pre-image key.
+	// An error aborts the pre-image request.
+	RecordPreimage(key [32]byte, value []byte) error
+}
+
+type PreimageRecorderFn func(key [32]byte, value []byte) error
+
+func (fn PreimageRecorderFn) RecordPreimage(key [32]byte, value []byte) error {
+	return fn(key, value)
+}
+
+// BinaryRecorder writes the values of served pre-images to an io.Writer,
+// each as a big-endian uint64 length prefix followed by the value, zero-padded to a multiple of 8 bytes.
+// This is the layout of both the private and the public inputs consumed by the zkWasm prover.
+type BinaryRecorder struct {
+	w io.Writer
+}
+
+func NewBinaryRecorder(w io.Writer) *BinaryRecorder {
+	return &BinaryRecorder{w: w}
+}
+
+var _ PreimageRecorder = (*BinaryRecorder)(nil)
+
+func (r *BinaryRecorder) RecordPreimage(_ [32]byte, value []byte) error {
+	if err := binary.Write(r.w, binary.BigEndian, uint64(len(value))); err != nil {
+		return fmt.Errorf("failed to write pre-image length: %w", err)
+	}
+	if _, err := r.w.Write(value); err != nil {
+		return fmt.Errorf("failed to write pre-image value: %w", err)
+	}
+	// padding some zeros to make preimages length can be divided by 8
+	if len(value)%8 != 0 {
+		if _, err := r.w.Write(make([]byte, 8-len(value)%8)); err != nil {
+			return fmt.Errorf("failed to write pre-image padding: %w", err)
+		}
+	}
+	return nil
+}
+
+// CompressedRecorder is a BinaryRecorder writing gzip compressed to an io.Writer.
+type CompressedRecorder struct {
+	*BinaryRecorder
+	gz *gzip.Writer
+}
+
+func NewCompressedRecorder(w io.Writer) *CompressedRecorder {
+	gz := gzip.NewWriter(w)
+	return &CompressedRecorder{BinaryRecorder: NewBinaryRecorder(gz), gz: gz}
+}
+
+// Close flushes the compressed pre-images. It does not close the underlying writer.
+func (r *CompressedRecorder) Close() error {
+	if err := r.gz.Close(); err != nil {
+		return fmt.Errorf("failed to flush compressed pre-images: %w", err)
+	}
+	return nil
+}
+
+// FileRecorder is a BinaryRecorder writing to a file, optionally gzip compressed.
+type FileRecorder struct {
+	PreimageRecorder
+	f   *os.File
+	buf *bufio.Writer
+	gz  *CompressedRecorder
+}
+
+// NewFileRecorder creates (or truncates) the file at path to record pre-images to.
+func NewFileRecorder(path string, compress bool) (*FileRecorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-image file %q: %w", path, err)
+	}
+	buf := bufio.NewWriter(f)
+	if !compress {
+		return &FileRecorder{PreimageRecorder: NewBinaryRecorder(buf), f: f, buf: buf}, nil
+	}
+	gz := NewCompressedRecorder(buf)
+	return &FileRecorder{PreimageRecorder: gz, f: f, buf: buf, gz: gz}, nil
+}
+
+// Close flushes the recorded pre-images and closes the file.
+func (r *FileRecorder) Close() error {
+	if r.gz != nil {
+		if err := r.gz.Close(); err != nil {
+			_ = r.f.Close()
+			return err
+		}
+	}
+	if err := r.buf.Flush(); err != nil {
+		_ = r.f.Close()
+		return fmt.Errorf("failed to write pre-image file: %w", err)
+	}
+	return r.f.Close()
+}
+
+// RecordedPreimage is a pre-image captured by a MemRecorder.
+type RecordedPreimage struct {
+	Key   [32]byte
+	Value []byte
+}
+
+// MemRecorder keeps all served pre-images in memory.
+// MemRecorder is safe for concurrent use.
+type MemRecorder struct {
+	mu        sync.Mutex
+	preimages []RecordedPreimage
+}
+
+func NewMemRecorder() *MemRecorder {
+	return &MemRecorder{}
+}
+
+var _ PreimageRecorder = (*MemRecorder)(nil)
+
+func (r *MemRecorder) RecordPreimage(key [32]byte, value []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.preimages = append(r.preimages, RecordedPreimage{Key: key, Value: append([]byte{}, value...)})
+	return nil
+}
+
+// Preimages returns a copy of the pre-images recorded so far.
+func (r *MemRecorder) Preimages() []RecordedPreimage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedPreimage{}, r.preimages...)
+}
+
+// Keys returns the keys of the pre-images recorded so far.
+func (r *MemRecorder) Keys() [][32]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([][32]byte, len(r.preimages))
+	for i, p := range r.preimages {
+		keys[i] = p.Key
+	}
+	return keys
+}
+
+type multiRecorder []PreimageRecorder
+
+func (m multiRecorder) RecordPreimage(key [32]byte, value []byte) error {
+	for _, r := range m {
+		if err := r.RecordPreimage(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MultiRecorder returns a PreimageRecorder that records to all the given recorders, in order.
+// Nil recorders are skipped. If no recorders remain, nil is returned.
+func MultiRecorder(recorders ...PreimageRecorder) PreimageRecorder {
+	var out multiRecorder
+	for _, r := range recorders {
+		if r != nil {
+			out = append(out, r)
+		}
+	}
+	switch len(out) {
+	case 0:
+		return nil
+	case 1:
+		return out[0]
+	default:
+		return out
+	}
+}
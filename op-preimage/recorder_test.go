@@ -0,0 +1,111 @@
+package preimage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func expectedBinary(values ...[]byte) []byte {
+	var out []byte
+	for _, v := range values {
+		out = binary.BigEndian.AppendUint64(out, uint64(len(v)))
+		out = append(out, v...)
+		if len(v)%8 != 0 {
+			out = append(out, make([]byte, 8-len(v)%8)...)
+		}
+	}
+	return out
+}
+
+func TestBinaryRecorder(t *testing.T) {
+	values := [][]byte{[]byte("abc"), {}, bytes.Repeat([]byte{1}, 16), []byte("123456789")}
+	var buf bytes.Buffer
+	rec := NewBinaryRecorder(&buf)
+	for i, v := range values {
+		require.NoError(t, rec.RecordPreimage([32]byte{byte(i)}, v))
+	}
+	require.Equal(t, expectedBinary(values...), buf.Bytes())
+	require.Zero(t, buf.Len()%8)
+}
+
+func TestCompressedRecorder(t *testing.T) {
+	values := [][]byte{[]byte("hello"), []byte("world")}
+	var buf bytes.Buffer
+	rec := NewCompressedRecorder(&buf)
+	for _, v := range values {
+		require.NoError(t, rec.RecordPreimage([32]byte{1}, v))
+	}
+	require.NoError(t, rec.Close())
+	gz, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	data, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	require.Equal(t, expectedBinary(values...), data)
+}
+
+func TestFileRecorder(t *testing.T) {
+	values := [][]byte{[]byte("hello"), []byte("world")}
+	for _, compress := range []bool{false, true} {
+		compress := compress
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "preimages.bin")
+			rec, err := NewFileRecorder(path, compress)
+			require.NoError(t, err)
+			for _, v := range values {
+				require.NoError(t, rec.RecordPreimage([32]byte{1}, v))
+			}
+			require.NoError(t, rec.Close())
+
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			defer f.Close()
+			var r io.Reader = f
+			if compress {
+				gz, err := gzip.NewReader(f)
+				require.NoError(t, err)
+				r = gz
+			}
+			data, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, expectedBinary(values...), data)
+		})
+	}
+}
+
+func TestMemRecorder(t *testing.T) {
+	rec := NewMemRecorder()
+	value := []byte("abc")
+	require.NoError(t, rec.RecordPreimage([32]byte{1}, value))
+	require.NoError(t, rec.RecordPreimage([32]byte{2}, nil))
+	value[0] = 'x'
+	require.Equal(t, []RecordedPreimage{{Key: [32]byte{1}, Value: []byte("abc")}, {Key: [32]byte{2}, Value: []byte{}}}, rec.Preimages())
+	require.Equal(t, [][32]byte{{1}, {2}}, rec.Keys())
+}
+
+func TestMultiRecorder(t *testing.T) {
+	require.Nil(t, MultiRecorder())
+	require.Nil(t, MultiRecorder(nil, nil))
+
+	a := NewMemRecorder()
+	require.Same(t, a, MultiRecorder(nil, a))
+
+	b := NewMemRecorder()
+	multi := MultiRecorder(a, b)
+	require.NoError(t, multi.RecordPreimage([32]byte{1}, []byte("v")))
+	require.Equal(t, a.Preimages(), b.Preimages())
+	require.Len(t, a.Preimages(), 1)
+
+	failure := errors.New("boom")
+	failing := PreimageRecorderFn(func(key [32]byte, value []byte) error { return failure })
+	require.ErrorIs(t, MultiRecorder(failing, a).RecordPreimage([32]byte{2}, nil), failure)
+	require.Len(t, a.Preimages(), 1, "should stop at first error")
+}
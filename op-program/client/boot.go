@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	cldr "github.com/ethereum-optimism/optimism/op-program/client/driver"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params"
 )
@@ -18,6 +19,8 @@ const (
 	L2ClaimBlockNumberLocalIndex
 	L2ChainConfigLocalIndex
 	RollupConfigLocalIndex
+	L2ClaimsLocalIndex
+	CheckpointLocalIndex
 )
 
 type BootInfo struct {
@@ -25,8 +28,14 @@ type BootInfo struct {
 	L2Head             common.Hash
 	L2Claim            common.Hash
 	L2ClaimBlockNumber uint64
-	L2ChainConfig      *params.ChainConfig
-	RollupConfig       *rollup.Config
+	// L2Claims are additional output root claims checked as derivation passes through their blocks,
+	// in ascending block order, before L2Claim is checked at L2ClaimBlockNumber.
+	L2Claims []cldr.Claim
+	// Checkpoint is the checkpoint output by the previous segment to resume from, if any.
+	// Derivation then starts from the checkpoint safe head, which must be L2Head.
+	Checkpoint    *cldr.Checkpoint
+	L2ChainConfig *params.ChainConfig
+	RollupConfig  *rollup.Config
 }
 
 type oracleClient interface {
@@ -58,12 +67,22 @@ func (br *BootstrapClient) BootInfo() *BootInfo {
 	if err != nil {
 		panic("failed to bootstrap rollup config")
 	}
+	l2Claims, err := cldr.DecodeClaims(br.r.Get(L2ClaimsLocalIndex))
+	if err != nil {
+		panic("failed to bootstrap l2 claims")
+	}
+	checkpoint, err := cldr.DecodeCheckpoint(br.r.Get(CheckpointLocalIndex))
+	if err != nil {
+		panic("failed to bootstrap checkpoint")
+	}
 
 	return &BootInfo{
 		L1Head:             l1Head,
 		L2Head:             l2Head,
 		L2Claim:            l2Claim,
 		L2ClaimBlockNumber: l2ClaimBlockNumber,
+		L2Claims:           l2Claims,
+		Checkpoint:         checkpoint,
 		L2ChainConfig:      l2ChainConfig,
 		RollupConfig:       rollupConfig,
 	}
@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
 	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	cldr "github.com/ethereum-optimism/optimism/op-program/client/driver"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/stretchr/testify/require"
@@ -26,6 +28,43 @@ func TestBootstrapClient(t *testing.T) {
 	require.EqualValues(t, bootInfo, readBootInfo)
 }
 
+func TestBootstrapClientWithClaims(t *testing.T) {
+	bootInfo := &BootInfo{
+		L1Head:             common.HexToHash("0x1111"),
+		L2Head:             common.HexToHash("0x2222"),
+		L2Claim:            common.HexToHash("0x3333"),
+		L2ClaimBlockNumber: 10,
+		L2Claims: []cldr.Claim{
+			{BlockNumber: 4, OutputRoot: eth.Bytes32{0x44}},
+			{BlockNumber: 8, OutputRoot: eth.Bytes32{0x88}},
+		},
+		L2ChainConfig: params.GoerliChainConfig,
+		RollupConfig:  &chaincfg.Goerli,
+	}
+	mockOracle := &mockBoostrapOracle{bootInfo}
+	readBootInfo := NewBootstrapClient(mockOracle).BootInfo()
+	require.EqualValues(t, bootInfo, readBootInfo)
+}
+
+func TestBootstrapClientWithCheckpoint(t *testing.T) {
+	bootInfo := &BootInfo{
+		L1Head:             common.HexToHash("0x1111"),
+		L2Head:             common.HexToHash("0x2222"),
+		L2Claim:            common.HexToHash("0x3333"),
+		L2ClaimBlockNumber: 10,
+		Checkpoint: &cldr.Checkpoint{
+			SafeHead:   eth.BlockID{Hash: common.HexToHash("0x2222"), Number: 6},
+			L1Origin:   eth.BlockID{Hash: common.HexToHash("0x4444"), Number: 3},
+			OutputRoot: eth.Bytes32{0x55},
+		},
+		L2ChainConfig: params.GoerliChainConfig,
+		RollupConfig:  &chaincfg.Goerli,
+	}
+	mockOracle := &mockBoostrapOracle{bootInfo}
+	readBootInfo := NewBootstrapClient(mockOracle).BootInfo()
+	require.EqualValues(t, bootInfo, readBootInfo)
+}
+
 type mockBoostrapOracle struct {
 	b *BootInfo
 }
@@ -46,6 +85,10 @@ func (o *mockBoostrapOracle) Get(key preimage.Key) []byte {
 	case RollupConfigLocalIndex.PreimageKey():
 		b, _ := json.Marshal(o.b.RollupConfig)
 		return b
+	case L2ClaimsLocalIndex.PreimageKey():
+		return cldr.EncodeClaims(o.b.L2Claims)
+	case CheckpointLocalIndex.PreimageKey():
+		return cldr.EncodeCheckpoint(o.b.Checkpoint)
 	default:
 		panic("unknown key")
 	}
@@ -0,0 +1,24 @@
+//go:build (js || wasm || wasip1) && zkwasm_debug
+// +build js wasm wasip1
+// +build zkwasm_debug
+
+package client
+
+import "encoding/binary"
+
+// reportKey reports the key of the pre-image about to be read to the host,
+// so the pre-images requested by the wasm client can be compared with the MIPS client.
+// zkWasm provers do not provide the host function, so this build is only for debugging.
+func reportKey(key [32]byte) {
+	debug_preimage_key(
+		binary.BigEndian.Uint64(key[0:8]),
+		binary.BigEndian.Uint64(key[8:16]),
+		binary.BigEndian.Uint64(key[16:24]),
+		binary.BigEndian.Uint64(key[24:32]))
+}
+
+// debug_preimage_key receives the key as four big-endian limbs.
+//
+//go:wasmimport env debug_preimage_key
+//go:noescape
+func debug_preimage_key(uint64, uint64, uint64, uint64)
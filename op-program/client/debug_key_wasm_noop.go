@@ -0,0 +1,8 @@
+//go:build (js || wasm || wasip1) && !zkwasm_debug
+// +build js wasm wasip1
+// +build !zkwasm_debug
+
+package client
+
+// reportKey does nothing, build with the zkwasm_debug tag to report the requested keys to the host.
+func reportKey(_ [32]byte) {}
@@ -17,33 +17,63 @@ import (
 )
 
 // runDerivation executes the L2 state transition, given a minimal interface to retrieve data.
-func runDerivation(logger log.Logger, cfg *rollup.Config, l2Cfg *params.ChainConfig, l1Head common.Hash, l2Head common.Hash, l2Claim common.Hash, l2ClaimBlockNum uint64, l1Oracle l1.Oracle, l2Oracle l2.Oracle) error {
+// Every claim of l2Claims is checked as derivation passes through its block, followed by l2Claim.
+// Derivation stops at the first invalid claim, returning a *cldr.ClaimError.
+// If start is set, derivation resumes from that checkpoint of a previous segment, which must be at l2Head.
+// The returned checkpoint is set if the derivation completed, whether the claim is valid or not.
+func runDerivation(logger log.Logger, cfg *rollup.Config, l2Cfg *params.ChainConfig, l1Head common.Hash, l2Head common.Hash, l2Claim common.Hash, l2ClaimBlockNum uint64, l2Claims []cldr.Claim, start *cldr.Checkpoint, l1Oracle l1.Oracle, l2Oracle l2.Oracle) (*cldr.Checkpoint, error) {
+	if err := cldr.CheckClaimsOrder(l2Claims, l2ClaimBlockNum); err != nil {
+		return nil, err
+	}
+	claims := append(append([]cldr.Claim{}, l2Claims...), cldr.Claim{BlockNumber: l2ClaimBlockNum, OutputRoot: eth.Bytes32(l2Claim)})
+
 	l1Source := l1.NewOracleL1Client(logger, l1Oracle, l1Head)
 	engineBackend, err := l2.NewOracleBackedL2Chain(logger, l2Oracle, l2Cfg, l2Head)
 	if err != nil {
-		return fmt.Errorf("failed to create oracle-backed L2 chain: %w", err)
+		return nil, fmt.Errorf("failed to create oracle-backed L2 chain: %w", err)
 	}
 	l2Source := l2.NewOracleEngine(cfg, logger, engineBackend)
+	if start != nil {
+		if start.SafeHead.Hash != l2Head {
+			return nil, fmt.Errorf("%w: safe head %s is not the L2 head %s", cldr.ErrInvalidCheckpoint, start.SafeHead, l2Head)
+		}
+		if err := cldr.VerifyCheckpoint(context.Background(), l2Source, *start); err != nil {
+			return nil, err
+		}
+		logger.Info("Resuming from checkpoint", "head", start.SafeHead, "l1Origin", start.L1Origin, "output", start.OutputRoot)
+	}
 
 	logger.Info("Starting derivation")
-	d := cldr.NewDriver(logger, cfg, l1Source, l2Source, l2ClaimBlockNum)
+	d := cldr.NewDriver(logger, cfg, l1Source, l2Source, claims[0].BlockNumber)
 	i := 0
-	for {
-		if i > maximumSteps && maximumSteps >= 0 {
-			break
+	var checkpoint cldr.Checkpoint
+	for _, claim := range claims {
+		d.SetTargetBlockNum(claim.BlockNumber)
+		for {
+			if i > maximumSteps && maximumSteps >= 0 {
+				break
+			}
+			if err = d.Step(context.Background()); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			i += 1
+		}
+		checkpoint, err = d.Checkpoint()
+		if err != nil {
+			return nil, err
 		}
-		if err = d.Step(context.Background()); errors.Is(err, io.EOF) {
-			break
-		} else if err != nil {
-			return err
+		logger.Info("Derivation checkpoint", "head", checkpoint.SafeHead, "l1Origin", checkpoint.L1Origin, "output", checkpoint.OutputRoot)
+		if err := d.ValidateCheckpoint(checkpoint, claim); err != nil {
+			return &checkpoint, err
 		}
-		i += 1
 	}
-	return d.ValidateClaim(eth.Bytes32(l2Claim))
+	return &checkpoint, nil
 }
 
 // RunProgramWithDefault executes the Program, while attached to an IO based pre-image oracle, to be served by a host.
-func RunProgramWithDefault(logger log.Logger) error {
+func RunProgramWithDefault(logger log.Logger) (*cldr.Checkpoint, error) {
 	pClient, hClient := NewOracleClientAndHintWriter()
 	l1PreimageOracle := l1.NewPreimageOracle(pClient, hClient)
 	l2PreimageOracle := l2.NewPreimageOracle(pClient, hClient)
@@ -58,6 +88,8 @@ func RunProgramWithDefault(logger log.Logger) error {
 		bootInfo.L2Head,
 		bootInfo.L2Claim,
 		bootInfo.L2ClaimBlockNumber,
+		bootInfo.L2Claims,
+		bootInfo.Checkpoint,
 		l1PreimageOracle,
 		l2PreimageOracle,
 	)
@@ -0,0 +1,100 @@
+package driver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// CheckpointSize is the length of a serialized Checkpoint in bytes.
+const CheckpointSize = 32 + 8 + 32 + 8 + 32
+
+// CheckpointLimbs is the number of uint64 values a Checkpoint is output as by the wasm client.
+const CheckpointLimbs = CheckpointSize / 8
+
+var ErrInvalidCheckpoint = errors.New("invalid checkpoint")
+
+// Checkpoint is the state reached at the end of a derivation segment.
+// A following segment resumes by booting with the checkpoint: the checkpoint safe head becomes the L2 head,
+// and the derivation pipeline is reset from it, rebuilding its cursor from the L1 origin,
+// exactly like every run starts from the agreed L2 head.
+type Checkpoint struct {
+	// SafeHead is the last L2 block derived in the segment.
+	SafeHead eth.BlockID
+	// L1Origin is the L1 origin of the safe head.
+	L1Origin eth.BlockID
+	// OutputRoot is the L2 output root at the safe head.
+	OutputRoot eth.Bytes32
+}
+
+// MarshalBinary encodes the checkpoint as
+// safe head hash, safe head number, L1 origin hash, L1 origin number, output root,
+// with big-endian numbers.
+func (c Checkpoint) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, CheckpointSize)
+	out = append(out, c.SafeHead.Hash[:]...)
+	out = binary.BigEndian.AppendUint64(out, c.SafeHead.Number)
+	out = append(out, c.L1Origin.Hash[:]...)
+	out = binary.BigEndian.AppendUint64(out, c.L1Origin.Number)
+	out = append(out, c.OutputRoot[:]...)
+	return out, nil
+}
+
+func (c *Checkpoint) UnmarshalBinary(data []byte) error {
+	if len(data) != CheckpointSize {
+		return fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidCheckpoint, CheckpointSize, len(data))
+	}
+	copy(c.SafeHead.Hash[:], data[0:32])
+	c.SafeHead.Number = binary.BigEndian.Uint64(data[32:40])
+	copy(c.L1Origin.Hash[:], data[40:72])
+	c.L1Origin.Number = binary.BigEndian.Uint64(data[72:80])
+	copy(c.OutputRoot[:], data[80:112])
+	return nil
+}
+
+// EncodeCheckpoint encodes the checkpoint to resume from as boot input, or no checkpoint as empty data.
+func EncodeCheckpoint(c *Checkpoint) []byte {
+	if c == nil {
+		return []byte{}
+	}
+	data, _ := c.MarshalBinary()
+	return data
+}
+
+// DecodeCheckpoint decodes a checkpoint encoded with EncodeCheckpoint.
+func DecodeCheckpoint(data []byte) (*Checkpoint, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var c Checkpoint
+	if err := c.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Limbs returns the serialized checkpoint as big-endian uint64 values, as output by the wasm client.
+func (c Checkpoint) Limbs() []uint64 {
+	data, _ := c.MarshalBinary()
+	limbs := make([]uint64, CheckpointLimbs)
+	for i := range limbs {
+		limbs[i] = binary.BigEndian.Uint64(data[i*8:])
+	}
+	return limbs
+}
+
+// CheckpointFromLimbs decodes a checkpoint output by the wasm client.
+func CheckpointFromLimbs(limbs []uint64) (Checkpoint, error) {
+	if len(limbs) != CheckpointLimbs {
+		return Checkpoint{}, fmt.Errorf("%w: expected %d limbs, got %d", ErrInvalidCheckpoint, CheckpointLimbs, len(limbs))
+	}
+	data := make([]byte, 0, CheckpointSize)
+	for _, limb := range limbs {
+		data = binary.BigEndian.AppendUint64(data, limb)
+	}
+	var c Checkpoint
+	err := c.UnmarshalBinary(data)
+	return c, err
+}
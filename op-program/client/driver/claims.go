@@ -0,0 +1,72 @@
+package driver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// claimSize is the length of an encoded Claim in bytes.
+const claimSize = 8 + 32
+
+var ErrInvalidClaims = errors.New("invalid claims encoding")
+
+// Claim is an output root claimed for an L2 block.
+type Claim struct {
+	BlockNumber uint64
+	OutputRoot  eth.Bytes32
+}
+
+// ClaimError is returned when the output root derived for the block of a claim differs from the claimed one.
+type ClaimError struct {
+	Claim  Claim
+	Actual eth.Bytes32
+}
+
+func (e *ClaimError) Error() string {
+	return fmt.Sprintf("%v: block: %d claim: %v actual: %v", ErrClaimNotValid, e.Claim.BlockNumber, e.Claim.OutputRoot, e.Actual)
+}
+
+func (e *ClaimError) Unwrap() error {
+	return ErrClaimNotValid
+}
+
+// EncodeClaims encodes claims as consecutive big-endian block numbers and output roots.
+func EncodeClaims(claims []Claim) []byte {
+	out := make([]byte, 0, len(claims)*claimSize)
+	for _, c := range claims {
+		out = binary.BigEndian.AppendUint64(out, c.BlockNumber)
+		out = append(out, c.OutputRoot[:]...)
+	}
+	return out
+}
+
+// DecodeClaims decodes claims encoded with EncodeClaims.
+func DecodeClaims(data []byte) ([]Claim, error) {
+	if len(data)%claimSize != 0 {
+		return nil, fmt.Errorf("%w: length %d is not a multiple of %d", ErrInvalidClaims, len(data), claimSize)
+	}
+	var claims []Claim
+	for ; len(data) > 0; data = data[claimSize:] {
+		var c Claim
+		c.BlockNumber = binary.BigEndian.Uint64(data[:8])
+		copy(c.OutputRoot[:], data[8:claimSize])
+		claims = append(claims, c)
+	}
+	return claims, nil
+}
+
+// CheckClaimsOrder checks the claims are in strictly ascending block order, and not after the final claim block.
+func CheckClaimsOrder(claims []Claim, finalBlockNumber uint64) error {
+	for i, c := range claims {
+		if i > 0 && c.BlockNumber <= claims[i-1].BlockNumber {
+			return fmt.Errorf("%w: claim %d at block %d is not after block %d", ErrInvalidClaims, i, c.BlockNumber, claims[i-1].BlockNumber)
+		}
+		if c.BlockNumber > finalBlockNumber {
+			return fmt.Errorf("%w: claim %d at block %d is after the final claim block %d", ErrInvalidClaims, i, c.BlockNumber, finalBlockNumber)
+		}
+	}
+	return nil
+}
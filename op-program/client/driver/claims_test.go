@@ -0,0 +1,39 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimsEncoding(t *testing.T) {
+	claims := []Claim{
+		{BlockNumber: 10, OutputRoot: eth.Bytes32{0x11}},
+		{BlockNumber: 20, OutputRoot: eth.Bytes32{0x22}},
+	}
+	data := EncodeClaims(claims)
+	require.Len(t, data, 2*claimSize)
+	decoded, err := DecodeClaims(data)
+	require.NoError(t, err)
+	require.Equal(t, claims, decoded)
+
+	empty, err := DecodeClaims(nil)
+	require.NoError(t, err)
+	require.Empty(t, empty)
+	require.Empty(t, EncodeClaims(nil))
+
+	_, err = DecodeClaims(data[:claimSize+1])
+	require.ErrorIs(t, err, ErrInvalidClaims)
+}
+
+func TestCheckClaimsOrder(t *testing.T) {
+	claim := func(n uint64) Claim {
+		return Claim{BlockNumber: n}
+	}
+	require.NoError(t, CheckClaimsOrder(nil, 10))
+	require.NoError(t, CheckClaimsOrder([]Claim{claim(1), claim(5), claim(10)}, 10))
+	require.ErrorIs(t, CheckClaimsOrder([]Claim{claim(5), claim(5)}, 10), ErrInvalidClaims)
+	require.ErrorIs(t, CheckClaimsOrder([]Claim{claim(5), claim(4)}, 10), ErrInvalidClaims)
+	require.ErrorIs(t, CheckClaimsOrder([]Claim{claim(11)}, 10), ErrInvalidClaims)
+}
@@ -84,22 +84,76 @@ func (d *Driver) Step(ctx context.Context) error {
 	} else if err != nil {
 		return fmt.Errorf("pipeline err: %w", err)
 	}
+	if head := d.pipeline.SafeL2Head(); head.Number >= d.targetBlockNum {
+		// Stop exactly at the target, so the output root is computed for the claimed block.
+		d.logger.Info("Derivation complete: reached L2 block", "head", head)
+		return io.EOF
+	}
 	return nil
 }
 
+// SetTargetBlockNum sets the L2 block to derive up to, to continue derivation to a later block after reaching the current target.
+func (d *Driver) SetTargetBlockNum(targetBlockNum uint64) {
+	d.targetBlockNum = targetBlockNum
+}
+
 func (d *Driver) SafeHead() eth.L2BlockRef {
 	return d.pipeline.SafeL2Head()
 }
 
-func (d *Driver) ValidateClaim(claimedOutputRoot eth.Bytes32) error {
+// Checkpoint returns the state reached by the derivation so far, including the output root at the safe head.
+func (d *Driver) Checkpoint() (Checkpoint, error) {
 	outputRoot, err := d.l2OutputRoot()
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("calculate L2 output root: %w", err)
+	}
+	head := d.SafeHead()
+	return Checkpoint{
+		SafeHead:   head.ID(),
+		L1Origin:   head.L1Origin,
+		OutputRoot: outputRoot,
+	}, nil
+}
+
+// VerifyCheckpoint checks that the L2 head of l2Source is the safe head of the checkpoint to resume from,
+// with the checkpoint L1 origin and output root.
+func VerifyCheckpoint(ctx context.Context, l2Source L2Source, checkpoint Checkpoint) error {
+	head, err := l2Source.L2BlockRefByHash(ctx, checkpoint.SafeHead.Hash)
+	if err != nil {
+		return fmt.Errorf("%w: safe head %s unavailable: %v", ErrInvalidCheckpoint, checkpoint.SafeHead, err)
+	}
+	if head.Number != checkpoint.SafeHead.Number {
+		return fmt.Errorf("%w: safe head %s is block %d", ErrInvalidCheckpoint, checkpoint.SafeHead, head.Number)
+	}
+	if head.L1Origin != checkpoint.L1Origin {
+		return fmt.Errorf("%w: L1 origin %s of safe head does not match %s", ErrInvalidCheckpoint, head.L1Origin, checkpoint.L1Origin)
+	}
+	outputRoot, err := l2Source.L2OutputRoot()
 	if err != nil {
 		return fmt.Errorf("calculate L2 output root: %w", err)
 	}
-	d.logger.Info("Validating claim", "head", d.SafeHead(), "output", outputRoot, "claim", claimedOutputRoot)
-	fmt.Println("Validating claim", "head", d.SafeHead(), "output", outputRoot, "claim", claimedOutputRoot)
-	if claimedOutputRoot != outputRoot {
-		return fmt.Errorf("%w: claim: %v actual: %v", ErrClaimNotValid, claimedOutputRoot, outputRoot)
+	if outputRoot != checkpoint.OutputRoot {
+		return fmt.Errorf("%w: output root %s of safe head does not match %s", ErrInvalidCheckpoint, outputRoot, checkpoint.OutputRoot)
+	}
+	return nil
+}
+
+func (d *Driver) ValidateClaim(claimedOutputRoot eth.Bytes32) error {
+	checkpoint, err := d.Checkpoint()
+	if err != nil {
+		return err
+	}
+	return d.ValidateCheckpoint(checkpoint, Claim{BlockNumber: d.targetBlockNum, OutputRoot: claimedOutputRoot})
+}
+
+// ValidateCheckpoint checks the claimed output root against the output root of the checkpoint.
+// Returns a *ClaimError if the claim is invalid.
+func (d *Driver) ValidateCheckpoint(checkpoint Checkpoint, claim Claim) error {
+	outputRoot := checkpoint.OutputRoot
+	d.logger.Info("Validating claim", "head", d.SafeHead(), "output", outputRoot, "claim", claim.OutputRoot, "block", claim.BlockNumber)
+	fmt.Println("Validating claim", "head", d.SafeHead(), "output", outputRoot, "claim", claim.OutputRoot)
+	if claim.OutputRoot != outputRoot {
+		return &ClaimError{Claim: claim, Actual: outputRoot}
 	}
 	return nil
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/require"
 )
@@ -54,6 +55,19 @@ func TestTargetBlock(t *testing.T) {
 		require.ErrorIs(t, err, io.EOF)
 	})
 
+	t.Run("ReachedAfterProgress", func(t *testing.T) {
+		driver := createDriverWithNextBlock(t, nil, 1000)
+		driver.targetBlockNum = 1000
+		err := driver.Step(context.Background())
+		require.ErrorIs(t, err, io.EOF, "should stop at the target block without waiting for more data")
+	})
+
+	t.Run("SetTarget", func(t *testing.T) {
+		driver := createDriverWithNextBlock(t, derive.NotEnoughData, 1000)
+		driver.SetTargetBlockNum(1001)
+		require.NoError(t, driver.Step(context.Background()))
+	})
+
 	t.Run("NotYetReached", func(t *testing.T) {
 		driver := createDriverWithNextBlock(t, derive.NotEnoughData, 1000)
 		driver.targetBlockNum = 1001
@@ -87,6 +101,10 @@ func TestValidateClaim(t *testing.T) {
 		}
 		err := driver.ValidateClaim(eth.Bytes32{0x11})
 		require.ErrorIs(t, err, ErrClaimNotValid)
+		var claimErr *ClaimError
+		require.ErrorAs(t, err, &claimErr)
+		require.Equal(t, Claim{BlockNumber: driver.targetBlockNum, OutputRoot: eth.Bytes32{0x11}}, claimErr.Claim)
+		require.Equal(t, eth.Bytes32{0x22}, claimErr.Actual)
 	})
 
 	t.Run("Error", func(t *testing.T) {
@@ -127,3 +145,96 @@ func (s stubDerivation) SafeL2Head() eth.L2BlockRef {
 		Number: s.nextBlockNum,
 	}
 }
+
+func TestCheckpoint(t *testing.T) {
+	driver := createDriverWithNextBlock(t, io.EOF, 1000)
+	driver.l2OutputRoot = func() (eth.Bytes32, error) {
+		return eth.Bytes32{0x11}, nil
+	}
+	checkpoint, err := driver.Checkpoint()
+	require.NoError(t, err)
+	require.Equal(t, Checkpoint{SafeHead: eth.BlockID{Number: 1000}, OutputRoot: eth.Bytes32{0x11}}, checkpoint)
+
+	t.Run("Error", func(t *testing.T) {
+		expectedErr := errors.New("boom")
+		driver.l2OutputRoot = func() (eth.Bytes32, error) {
+			return eth.Bytes32{}, expectedErr
+		}
+		_, err := driver.Checkpoint()
+		require.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestCheckpointEncoding(t *testing.T) {
+	checkpoint := Checkpoint{
+		SafeHead:   eth.BlockID{Hash: common.Hash{0xaa}, Number: 1234},
+		L1Origin:   eth.BlockID{Hash: common.Hash{0xbb}, Number: 5678},
+		OutputRoot: eth.Bytes32{0xcc},
+	}
+	data, err := checkpoint.MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, data, CheckpointSize)
+	var decoded Checkpoint
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.Equal(t, checkpoint, decoded)
+
+	limbs := checkpoint.Limbs()
+	require.Len(t, limbs, CheckpointLimbs)
+	fromLimbs, err := CheckpointFromLimbs(limbs)
+	require.NoError(t, err)
+	require.Equal(t, checkpoint, fromLimbs)
+
+	require.ErrorIs(t, decoded.UnmarshalBinary(data[1:]), ErrInvalidCheckpoint)
+	_, err = CheckpointFromLimbs(limbs[1:])
+	require.ErrorIs(t, err, ErrInvalidCheckpoint)
+
+	bootInput, err := DecodeCheckpoint(EncodeCheckpoint(&checkpoint))
+	require.NoError(t, err)
+	require.Equal(t, &checkpoint, bootInput)
+	require.Empty(t, EncodeCheckpoint(nil))
+	bootInput, err = DecodeCheckpoint(nil)
+	require.NoError(t, err)
+	require.Nil(t, bootInput)
+	_, err = DecodeCheckpoint(data[1:])
+	require.ErrorIs(t, err, ErrInvalidCheckpoint)
+}
+
+type stubL2Source struct {
+	L2Source
+	head       eth.L2BlockRef
+	outputRoot eth.Bytes32
+}
+
+func (s *stubL2Source) L2BlockRefByHash(_ context.Context, hash common.Hash) (eth.L2BlockRef, error) {
+	if hash != s.head.Hash {
+		return eth.L2BlockRef{}, errors.New("not found")
+	}
+	return s.head, nil
+}
+
+func (s *stubL2Source) L2OutputRoot() (eth.Bytes32, error) {
+	return s.outputRoot, nil
+}
+
+func TestVerifyCheckpoint(t *testing.T) {
+	src := &stubL2Source{
+		head: eth.L2BlockRef{
+			Hash:     common.Hash{0xaa},
+			Number:   1234,
+			L1Origin: eth.BlockID{Hash: common.Hash{0xbb}, Number: 5678},
+		},
+		outputRoot: eth.Bytes32{0xcc},
+	}
+	checkpoint := Checkpoint{SafeHead: src.head.ID(), L1Origin: src.head.L1Origin, OutputRoot: src.outputRoot}
+	require.NoError(t, VerifyCheckpoint(context.Background(), src, checkpoint))
+
+	invalid := func(modify func(c *Checkpoint)) error {
+		c := checkpoint
+		modify(&c)
+		return VerifyCheckpoint(context.Background(), src, c)
+	}
+	require.ErrorIs(t, invalid(func(c *Checkpoint) { c.SafeHead.Hash = common.Hash{0x11} }), ErrInvalidCheckpoint)
+	require.ErrorIs(t, invalid(func(c *Checkpoint) { c.SafeHead.Number++ }), ErrInvalidCheckpoint)
+	require.ErrorIs(t, invalid(func(c *Checkpoint) { c.L1Origin.Number++ }), ErrInvalidCheckpoint)
+	require.ErrorIs(t, invalid(func(c *Checkpoint) { c.OutputRoot = eth.Bytes32{0x11} }), ErrInvalidCheckpoint)
+}
@@ -0,0 +1,16 @@
+//go:build (js || wasm || wasip1) && !zkwasm_keccak
+// +build js wasm wasip1
+// +build !zkwasm_keccak
+
+package client
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// preimageHash hashes the pre-image inside the guest.
+// Build with the zkwasm_keccak tag to use the keccak host circuit instead.
+func preimageHash(data []byte) common.Hash {
+	return crypto.Keccak256Hash(data)
+}
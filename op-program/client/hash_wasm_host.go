@@ -0,0 +1,41 @@
+//go:build (js || wasm || wasip1) && zkwasm_keccak
+// +build js wasm wasip1
+// +build zkwasm_keccak
+
+package client
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// preimageHash hashes the pre-image with the zkWasm keccak host functions,
+// which is far cheaper to prove than hashing inside the guest.
+func preimageHash(data []byte) common.Hash {
+	keccak_new(1)
+	keccakBlockLimbs(data, keccak_push)
+	var hash common.Hash
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint64(hash[i*8:], keccak_finalize())
+	}
+	return hash
+}
+
+// keccak_new starts a new Keccak-256 hash.
+//
+//go:wasmimport env keccak_new
+//go:noescape
+func keccak_new(uint64)
+
+// keccak_push absorbs the next little-endian limb of the padded message.
+//
+//go:wasmimport env keccak_push
+//go:noescape
+func keccak_push(uint64)
+
+// keccak_finalize returns the next little-endian limb of the digest, four in total.
+//
+//go:wasmimport env keccak_finalize
+//go:noescape
+func keccak_finalize() uint64
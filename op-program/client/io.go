@@ -8,13 +8,16 @@ import (
 	"os"
 
 	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	cldr "github.com/ethereum-optimism/optimism/op-program/client/driver"
 	"github.com/ethereum-optimism/optimism/op-program/client/l1"
 	"github.com/ethereum-optimism/optimism/op-program/client/l2"
 	oppio "github.com/ethereum-optimism/optimism/op-program/io"
 	"github.com/ethereum/go-ethereum/log"
 )
 
-func RunProgram(logger log.Logger, preimageOracle io.ReadWriter, preimageHinter io.ReadWriter) error {
+// RunProgram runs the client with the given pre-image oracle and hinter,
+// returning the checkpoint reached if the derivation completed, as RunProgramWithDefault.
+func RunProgram(logger log.Logger, preimageOracle io.ReadWriter, preimageHinter io.ReadWriter) (*cldr.Checkpoint, error) {
 	pClient := preimage.NewOracleClient(preimageOracle)
 	hClient := preimage.NewHintWriter(preimageHinter)
 
@@ -31,6 +34,8 @@ func RunProgram(logger log.Logger, preimageOracle io.ReadWriter, preimageHinter
 		bootInfo.L2Head,
 		bootInfo.L2Claim,
 		bootInfo.L2ClaimBlockNumber,
+		bootInfo.L2Claims,
+		bootInfo.Checkpoint,
 		l1PreimageOracle,
 		l2PreimageOracle,
 	)
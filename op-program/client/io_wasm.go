@@ -7,7 +7,6 @@ import (
 	"encoding/binary"
 
 	preimage "github.com/ethereum-optimism/optimism/op-preimage"
-	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func NewOracleClientAndHintWriter() (preimage.Oracle, preimage.Hinter) {
@@ -21,19 +20,25 @@ type wasmHostIO struct {
 
 func (o wasmHostIO) Get(key preimage.Key) []byte {
 	_key := key.PreimageKey()
+	// Local boot values bind the proof to a specific dispute, so they are public inputs.
 	_, _isPublic := key.(preimage.LocalIndexKey)
+	var channel uint32
+	if _isPublic {
+		channel = 1
+	}
 
-	size := wasm_input(0)
+	reportKey(_key)
+	size := wasm_input(channel)
 	buf := make([]byte, size)
 
 	ssize := size / 8
 	for i := uint64(0); i < ssize; i++ {
-		data := wasm_input(0)
+		data := wasm_input(channel)
 		binary.BigEndian.PutUint64(buf[i*8:], data)
 	}
 
 	if ssize*8 < size {
-		data := wasm_input(0)
+		data := wasm_input(channel)
 		var sv uint64 = 56
 		for i := uint64(ssize * 8); i < size; i++ {
 			buf[i] = byte(data >> sv)
@@ -41,9 +46,8 @@ func (o wasmHostIO) Get(key preimage.Key) []byte {
 		}
 	}
 	// Integrity check
-	// TODO: can use customized circuit to optimize
 	if !_isPublic {
-		hash := crypto.Keccak256Hash(buf)
+		hash := preimageHash(buf)
 		hash[0] = _key[0]
 		require_bool(hash == _key)
 	}
@@ -0,0 +1,23 @@
+package client
+
+import "encoding/binary"
+
+// keccakRate is the number of bytes absorbed per Keccak-256 permutation.
+const keccakRate = 136
+
+// keccakBlockLimbs applies the Keccak-256 padding to data and passes the padded message to fn
+// as little-endian uint64 limbs, keccakRate/8 limbs per block.
+// This is the layout absorbed by the zkWasm keccak host circuit.
+func keccakBlockLimbs(data []byte, fn func(limb uint64)) {
+	full := len(data) / keccakRate * keccakRate
+	for i := 0; i < full; i += 8 {
+		fn(binary.LittleEndian.Uint64(data[i:]))
+	}
+	var last [keccakRate]byte
+	copy(last[:], data[full:])
+	last[len(data)-full] = 0x01
+	last[keccakRate-1] |= 0x80
+	for i := 0; i < keccakRate; i += 8 {
+		fn(binary.LittleEndian.Uint64(last[i:]))
+	}
+}
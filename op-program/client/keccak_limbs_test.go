@@ -0,0 +1,28 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeccakBlockLimbs(t *testing.T) {
+	for _, size := range []int{0, 1, 8, keccakRate - 1, keccakRate, keccakRate + 1, 3*keccakRate + 17} {
+		data := bytes.Repeat([]byte{0xab}, size)
+		var padded []byte
+		keccakBlockLimbs(data, func(limb uint64) {
+			padded = binary.LittleEndian.AppendUint64(padded, limb)
+		})
+		require.Zero(t, len(padded)%keccakRate, "size %d", size)
+		require.Equal(t, size/keccakRate+1, len(padded)/keccakRate, "size %d", size)
+		require.Equal(t, data, padded[:size], "size %d", size)
+
+		pad := padded[size:]
+		expected := make([]byte, len(pad))
+		expected[0] = 0x01
+		expected[len(expected)-1] |= 0x80
+		require.Equal(t, expected, pad, "size %d", size)
+	}
+}
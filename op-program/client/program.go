@@ -17,7 +17,7 @@ import (
 func Main(logger log.Logger) {
 	log.Info("Starting fault proof program client")
 
-	if err := RunProgramWithDefault(logger); errors.Is(err, cldr.ErrClaimNotValid) {
+	if _, err := RunProgramWithDefault(logger); errors.Is(err, cldr.ErrClaimNotValid) {
 		log.Error("Claim is invalid", "err", err)
 		os.Exit(1)
 	} else if err != nil {
@@ -16,7 +16,14 @@ import (
 func Main(logger log.Logger) {
 	log.Info("Starting fault proof program client")
 
-	if err := RunProgramWithDefault(logger); errors.Is(err, cldr.ErrClaimNotValid) {
+	checkpoint, err := RunProgramWithDefault(logger)
+	if checkpoint != nil {
+		// The checkpoint precedes the result code, so a following segment can resume from it.
+		for _, limb := range checkpoint.Limbs() {
+			wasm_output(limb)
+		}
+	}
+	if errors.Is(err, cldr.ErrClaimNotValid) {
 		log.Error("Claim is invalid", "err", err)
 		wasm_output(1022)
 		require(1)
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum-optimism/optimism/op-program/host"
+	"github.com/ethereum-optimism/optimism/op-program/host/kvstore"
+	"github.com/ethereum-optimism/optimism/op-program/host/types"
+	openum "github.com/ethereum-optimism/optimism/op-service/enum"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	MigrateFromFlag = &cli.PathFlag{
+		Name:      "from",
+		Usage:     "Directory of the pre-image data to migrate, in the directory format",
+		TakesFile: true,
+		Required:  true,
+	}
+	MigrateToFlag = &cli.PathFlag{
+		Name:      "to",
+		Usage:     "Directory to write the migrated pre-image data to",
+		TakesFile: true,
+		Required:  true,
+	}
+	MigrateFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: fmt.Sprintf("Format to migrate the pre-image data to. Available formats: %s", openum.EnumString(types.SupportedDataFormats)),
+		Value: string(types.DataFormatLevelDB),
+	}
+)
+
+func MigrateDataDir(ctx *cli.Context) (err error) {
+	from, to := ctx.Path(MigrateFromFlag.Name), ctx.Path(MigrateToFlag.Name)
+	if from == to {
+		return fmt.Errorf("cannot migrate pre-image data into its own directory %s", from)
+	}
+	if _, err := os.Stat(from); err != nil {
+		return fmt.Errorf("failed to open source datadir: %w", err)
+	}
+	if err := os.MkdirAll(to, 0755); err != nil {
+		return fmt.Errorf("creating datadir: %w", err)
+	}
+	dst, err := host.NewDiskKV(to, types.DataFormat(ctx.String(MigrateFormatFlag.Name)))
+	if err != nil {
+		return err
+	}
+	if closer, ok := dst.(io.Closer); ok {
+		defer func() {
+			if closeErr := closer.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("failed to close pre-image store: %w", closeErr)
+			}
+		}()
+	}
+	copied, err := kvstore.Migrate(kvstore.NewDiskKV(from), dst)
+	if err != nil {
+		return fmt.Errorf("migrated %d pre-images before failing: %w", copied, err)
+	}
+	_, _ = fmt.Fprintf(ctx.App.Writer, "migrated %d pre-images from %s to %s\n", copied, from, to)
+	return nil
+}
+
+var DataDirCommand = &cli.Command{
+	Name:  "datadir",
+	Usage: "Manage pre-image data directories",
+	Subcommands: []*cli.Command{
+		{
+			Name:        "migrate",
+			Usage:       "Migrate a pre-image data directory to another storage format",
+			Description: "Copy every pre-image of a data directory in the directory format, one hex-encoded file per pre-image, into a new data directory. Pre-images already present in the target are skipped, so an interrupted migration can be rerun. The source directory is left unchanged.",
+			Action:      MigrateDataDir,
+			Flags:       []cli.Flag{MigrateFromFlag, MigrateToFlag, MigrateFormatFlag},
+		},
+	},
+}
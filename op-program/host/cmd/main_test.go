@@ -7,9 +7,13 @@ import (
 	"testing"
 
 	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/sources"
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
 	"github.com/ethereum-optimism/optimism/op-program/host/config"
+	"github.com/ethereum-optimism/optimism/op-program/host/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/require"
@@ -96,6 +100,20 @@ func TestDataDir(t *testing.T) {
 	require.Equal(t, expected, cfg.DataDir)
 }
 
+func TestDataFormat(t *testing.T) {
+	t.Run("DefaultDirectory", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs())
+		require.Equal(t, types.DataFormatDirectory, cfg.DataFormat)
+	})
+	for _, format := range types.SupportedDataFormats {
+		format := format
+		t.Run(format.String(), func(t *testing.T) {
+			cfg := configForArgs(t, addRequiredArgs("--data.format", format.String()))
+			require.Equal(t, format, cfg.DataFormat)
+		})
+	}
+}
+
 func TestL2(t *testing.T) {
 	expected := "https://example.com:8545"
 	cfg := configForArgs(t, addRequiredArgs("--l2", expected))
@@ -259,6 +277,105 @@ func TestServerMode(t *testing.T) {
 	})
 }
 
+func TestPreimageTrace(t *testing.T) {
+	t.Run("DefaultEmpty", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs())
+		require.Equal(t, "", cfg.PreimageTrace)
+	})
+	t.Run("Set", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs("--preimage.trace", "/tmp/trace.bin"))
+		require.Equal(t, "/tmp/trace.bin", cfg.PreimageTrace)
+	})
+}
+
+func TestPreimagePublicFile(t *testing.T) {
+	t.Run("DefaultFromPreimageFile", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs("--preimage", "/tmp/preimages.bin"))
+		require.Equal(t, "", cfg.PreimagePublicFile)
+		require.Equal(t, "/tmp/preimages.bin.public", cfg.PreimagePublicPath())
+	})
+	t.Run("Set", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs("--preimage", "/tmp/preimages.bin", "--preimage.public", "/tmp/public.bin"))
+		require.Equal(t, "/tmp/public.bin", cfg.PreimagePublicPath())
+	})
+}
+
+func TestPreimageCompress(t *testing.T) {
+	t.Run("DefaultFalse", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs("--preimage", "/tmp/preimages.bin"))
+		require.False(t, cfg.PreimageCompress)
+	})
+	t.Run("Set", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs("--preimage", "/tmp/preimages.bin", "--preimage.compress"))
+		require.True(t, cfg.PreimageCompress)
+	})
+}
+
+func TestL2Claims(t *testing.T) {
+	t.Run("DefaultEmpty", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs())
+		require.Empty(t, cfg.L2Claims)
+	})
+	t.Run("Valid", func(t *testing.T) {
+		root1 := common.Hash{0x11}
+		root2 := common.Hash{0x22}
+		cfg := configForArgs(t, addRequiredArgs("--l2.claims", "10:"+root1.Hex(), "--l2.claims", "20:"+root2.Hex()))
+		require.Equal(t, []driver.Claim{
+			{BlockNumber: 10, OutputRoot: eth.Bytes32(root1)},
+			{BlockNumber: 20, OutputRoot: eth.Bytes32(root2)},
+		}, cfg.L2Claims)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		verifyArgsInvalid(t, config.ErrInvalidL2Claims.Error(), addRequiredArgs("--l2.claims", "10"))
+		verifyArgsInvalid(t, config.ErrInvalidL2Claims.Error(), addRequiredArgs("--l2.claims", "x:"+common.Hash{}.Hex()))
+		verifyArgsInvalid(t, config.ErrInvalidL2Claims.Error(), addRequiredArgs("--l2.claims", "10:0x1234"))
+	})
+}
+
+func TestL2Checkpoint(t *testing.T) {
+	t.Run("DefaultNone", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs())
+		require.Nil(t, cfg.L2Checkpoint)
+	})
+	t.Run("Valid", func(t *testing.T) {
+		checkpoint := driver.Checkpoint{
+			SafeHead:   eth.BlockID{Hash: common.HexToHash(l2HeadValue), Number: 10},
+			L1Origin:   eth.BlockID{Hash: common.Hash{0x11}, Number: 5},
+			OutputRoot: eth.Bytes32{0x22},
+		}
+		data, err := checkpoint.MarshalBinary()
+		require.NoError(t, err)
+		cfg := configForArgs(t, addRequiredArgs("--l2.checkpoint", hexutil.Encode(data)))
+		require.Equal(t, &checkpoint, cfg.L2Checkpoint)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		verifyArgsInvalid(t, config.ErrInvalidL2Checkpoint.Error(), addRequiredArgs("--l2.checkpoint", "0x1234"))
+		verifyArgsInvalid(t, config.ErrInvalidL2Checkpoint.Error(), addRequiredArgs("--l2.checkpoint", "xyz"))
+	})
+}
+
+func TestSegmentBlocks(t *testing.T) {
+	t.Run("DefaultDisabled", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs())
+		require.Zero(t, cfg.SegmentBlocks)
+	})
+	t.Run("Set", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs("--segment.blocks", "10", "--l1", "http://localhost:8545", "--l2", "http://localhost:9545"))
+		require.EqualValues(t, 10, cfg.SegmentBlocks)
+	})
+}
+
+func TestPrefetchWorkers(t *testing.T) {
+	t.Run("DefaultSynchronous", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs())
+		require.Zero(t, cfg.PrefetchWorkers)
+	})
+	t.Run("Set", func(t *testing.T) {
+		cfg := configForArgs(t, addRequiredArgs("--prefetch.workers", "16"))
+		require.EqualValues(t, 16, cfg.PrefetchWorkers)
+	})
+}
+
 func verifyArgsInvalid(t *testing.T, messageContains string, cliArgs []string) {
 	_, _, err := runWithArgs(cliArgs)
 	require.ErrorContains(t, err, messageContains)
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum-optimism/optimism/op-program/host"
+	"github.com/ethereum-optimism/optimism/op-program/host/diff"
+	"github.com/ethereum-optimism/optimism/op-program/host/trace"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	TraceInputFlag = &cli.PathFlag{
+		Name:      "trace",
+		Usage:     "Path of the pre-image trace file",
+		TakesFile: true,
+		Required:  true,
+	}
+	TraceOutputFlag = &cli.PathFlag{
+		Name:      "out",
+		Usage:     "Path to write the zkWasm pre-image input to",
+		TakesFile: true,
+		Required:  true,
+	}
+	TracePublicOutputFlag = &cli.PathFlag{
+		Name:      "public",
+		Usage:     "Path to write the zkWasm public input, the boot values of the run, to",
+		TakesFile: true,
+		Required:  true,
+	}
+	TraceCompressFlag = &cli.BoolFlag{
+		Name:  "compress",
+		Usage: "Gzip compress the zkWasm pre-image and public inputs",
+	}
+	TraceExecFlag = &cli.StringFlag{
+		Name:  "exec",
+		Usage: "Run the specified client program as a separate process. Default is to run the client program in the host process.",
+	}
+	TraceCannonStateFlag = &cli.PathFlag{
+		Name:      "cannon.state",
+		Usage:     "Path of the cannon VM state of the MIPS client program, as written by cannon load-elf",
+		TakesFile: true,
+		Required:  true,
+	}
+	TraceWasmFlag = &cli.PathFlag{
+		Name:      "wasm",
+		Usage:     "Path of the wasm client program",
+		TakesFile: true,
+		Required:  true,
+	}
+)
+
+func VerifyTrace(ctx *cli.Context) error {
+	f, err := os.Open(ctx.Path(TraceInputFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to open trace: %w", err)
+	}
+	defer f.Close()
+	summary, err := trace.Verify(f)
+	if err != nil {
+		return err
+	}
+	h := summary.Header
+	_, _ = fmt.Fprintf(ctx.App.Writer, "trace version %d: l1 head %s, l2 head %s, l2 claim %s at block %d\n",
+		h.Version, h.L1Head, h.L2Head, h.L2Claim, h.L2ClaimBlockNumber)
+	_, _ = fmt.Fprintf(ctx.App.Writer, "verified %d records (%d bytes)", summary.Records, summary.Bytes)
+	types := make([]int, 0, len(summary.ByType))
+	for typ := range summary.ByType {
+		types = append(types, int(typ))
+	}
+	sort.Ints(types)
+	for _, typ := range types {
+		_, _ = fmt.Fprintf(ctx.App.Writer, ", type %d: %d", typ, summary.ByType[preimage.KeyType(typ)])
+	}
+	_, _ = fmt.Fprintln(ctx.App.Writer)
+	return nil
+}
+
+func ConvertTrace(ctx *cli.Context) error {
+	in, err := os.Open(ctx.Path(TraceInputFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to open trace: %w", err)
+	}
+	defer in.Close()
+	compress := ctx.Bool(TraceCompressFlag.Name)
+	out, err := preimage.NewFileRecorder(ctx.Path(TraceOutputFlag.Name), compress)
+	if err != nil {
+		return fmt.Errorf("failed to create output: %w", err)
+	}
+	defer out.Close()
+	public, err := preimage.NewFileRecorder(ctx.Path(TracePublicOutputFlag.Name), compress)
+	if err != nil {
+		return fmt.Errorf("failed to create public output: %w", err)
+	}
+	defer public.Close()
+	if err := trace.RecordZkWasmInput(out, public, in); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return public.Close()
+}
+
+func ReplayTrace(ctx *cli.Context) error {
+	logger, err := setupLogging(ctx)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(ctx.Path(TraceInputFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to open trace: %w", err)
+	}
+	defer f.Close()
+	return host.ReplayProgram(ctx.Context, logger, f, ctx.String(TraceExecFlag.Name))
+}
+
+func DiffTrace(ctx *cli.Context) error {
+	state, err := mipsevm.LoadStateFile(ctx.Path(TraceCannonStateFlag.Name))
+	if err != nil {
+		return err
+	}
+	program, err := os.ReadFile(ctx.Path(TraceWasmFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to read wasm program: %w", err)
+	}
+	f, err := os.Open(ctx.Path(TraceInputFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to open trace: %w", err)
+	}
+	defer f.Close()
+	report, err := diff.Check(ctx.Context, state, program, f)
+	if err != nil {
+		return err
+	}
+	w := ctx.App.Writer
+	for _, run := range []struct {
+		name string
+		run  *diff.Run
+	}{{"cannon", report.Cannon}, {"wasm", report.Wasm}} {
+		_, _ = fmt.Fprintf(w, "%s: %d pre-images, verdict %s", run.name, len(run.run.Keys), run.run.Verdict)
+		if run.run.Exited {
+			_, _ = fmt.Fprintf(w, ", exit code %d", run.run.ExitCode)
+		}
+		if run.run.Err != nil {
+			_, _ = fmt.Fprintf(w, ", stopped: %v", run.run.Err)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+	if i := report.Divergence; i >= 0 {
+		_, _ = fmt.Fprintf(w, "first divergence at pre-image %d: cannon %s, wasm %s\n",
+			i, keyAt(report.Cannon.Keys, i), keyAt(report.Wasm.Keys, i))
+	}
+	if report.Diverged() {
+		return errors.New("cannon and wasm runs diverged")
+	}
+	return nil
+}
+
+func keyAt(keys []common.Hash, i int) string {
+	if i >= len(keys) {
+		return "none"
+	}
+	return keys[i].String()
+}
+
+var TraceCommand = &cli.Command{
+	Name:  "trace",
+	Usage: "Inspect pre-image trace files",
+	Subcommands: []*cli.Command{
+		{
+			Name:        "verify",
+			Usage:       "Verify a pre-image trace",
+			Description: "Check every record of a pre-image trace against its key and the boot info in the trace header",
+			Action:      VerifyTrace,
+			Flags:       []cli.Flag{TraceInputFlag},
+		},
+		{
+			Name:        "convert",
+			Usage:       "Convert a pre-image trace into zkWasm prover input",
+			Description: "Write the length-prefixed, 8-byte aligned pre-image values of a trace in the order the wasm client reads them. Local boot values are written to the public input, all other pre-images to the private input.",
+			Action:      ConvertTrace,
+			Flags:       []cli.Flag{TraceInputFlag, TraceOutputFlag, TracePublicOutputFlag, TraceCompressFlag},
+		},
+		{
+			Name:        "replay",
+			Usage:       "Replay a pre-image trace against the client program",
+			Description: "Run the client program offline, serving pre-images strictly in trace order. Fails if the client requests a different key than recorded, more pre-images than recorded, or leaves records unused.",
+			Action:      ReplayTrace,
+			Flags:       []cli.Flag{TraceInputFlag, TraceExecFlag},
+		},
+		{
+			Name:        "diff",
+			Usage:       "Compare the pre-images requested by the cannon and wasm client programs",
+			Description: "Run the boot inputs of a pre-image trace through both the MIPS client in cannon and the wasm client, and report the first pre-image they request differently along with the result of each run. Cannon is served pre-images by key, the wasm client in trace order. The wasm client must be built with the zkwasm_debug tag to report the keys it requests.",
+			Action:      DiffTrace,
+			Flags:       []cli.Flag{TraceInputFlag, TraceCannonStateFlag, TraceWasmFlag},
+		},
+	},
+}
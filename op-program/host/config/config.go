@@ -5,16 +5,24 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	opnode "github.com/ethereum-optimism/optimism/op-node"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/sources"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
 	"github.com/ethereum-optimism/optimism/op-program/host/flags"
+	"github.com/ethereum-optimism/optimism/op-program/host/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slices"
 )
 
 var (
@@ -25,8 +33,14 @@ var (
 	ErrL1AndL2Inconsistent = errors.New("l1 and l2 options must be specified together or both omitted")
 	ErrInvalidL2Claim      = errors.New("invalid l2 claim")
 	ErrInvalidL2ClaimBlock = errors.New("invalid l2 claim block number")
+	ErrInvalidL2Claims     = errors.New("invalid l2 claims")
+	ErrInvalidL2Checkpoint = errors.New("invalid l2 checkpoint")
 	ErrDataDirRequired     = errors.New("datadir must be specified when in non-fetching mode")
+	ErrInvalidDataFormat   = errors.New("invalid data format")
 	ErrNoExecInServerMode  = errors.New("exec command must not be set when in server mode")
+	ErrSegmentsNeedFetch   = errors.New("segments require fetching from l1 and l2 nodes")
+	ErrNoSegmentsInServer  = errors.New("segments are not supported in server mode")
+	ErrNoSegmentsWithExec  = errors.New("segments require running the client program in the host process")
 )
 
 type Config struct {
@@ -34,6 +48,8 @@ type Config struct {
 	// DataDir is the directory to read/write pre-image data from/to.
 	//If not set, an in-memory key-value store is used and fetching data must be enabled
 	DataDir string
+	// DataFormat is the storage format of the pre-image data in DataDir.
+	DataFormat types.DataFormat
 
 	// L1Head is the block has of the L1 chain head block
 	L1Head     common.Hash
@@ -49,17 +65,43 @@ type Config struct {
 	// L2ClaimBlockNumber is the block number the claimed L2 output root is from
 	// Must be above 0 and to be a valid claim needs to be above the L2Head block.
 	L2ClaimBlockNumber uint64
+	// L2Claims are additional output roots to check as derivation passes through their blocks,
+	// in ascending block order, before L2Claim is checked.
+	L2Claims []driver.Claim
+	// L2Checkpoint is the checkpoint output by a previous segment to resume derivation from.
+	// Its safe head must be L2Head. If nil, derivation starts from L2Head without a checkpoint.
+	L2Checkpoint *driver.Checkpoint
 	// L2ChainConfig is the op-geth chain config for the L2 execution engine
 	L2ChainConfig *params.ChainConfig
 	// ExecCmd specifies the client program to execute in a separate process.
 	// If unset, the fault proof client is run in the same process.
 	ExecCmd string
 
+	// PrefetchWorkers is the number of concurrent requests to prefetch pre-images with in the background.
+	// If 0, pre-images are fetched synchronously when requested.
+	PrefetchWorkers uint
+
 	// ServerMode indicates that the program should run in pre-image server mode and wait for requests.
 	// No client program is run.
 	ServerMode bool
-	//preimage file
+	// SegmentBlocks splits the run into segments deriving at most this many L2 blocks each.
+	// Each segment is a self-contained run, booted from the checkpoint of the previous one.
+	// If 0, the run is not segmented.
+	SegmentBlocks uint64
+
+	// PreimageFile is the path to write the zkWasm pre-image input to, derived from the pre-image trace.
 	PreimageFile string
+	// PreimagePublicFile is the path to write the zkWasm public input to, derived from the pre-image trace.
+	// If unset, the PreimageFile path with a .public suffix is used.
+	PreimagePublicFile string
+	// PreimageCompress gzip compresses the zkWasm private and public inputs.
+	PreimageCompress bool
+	// PreimageTrace is the path to record the trace of served pre-images to.
+	// If unset and PreimageFile is set, the trace is recorded to a temporary file.
+	PreimageTrace string
+	// PreimageRecorder, if set, is passed every pre-image served to the client program.
+	// This allows callers running the host in-process to capture the pre-images of each run.
+	PreimageRecorder preimage.PreimageRecorder
 }
 
 func (c *Config) Check() error {
@@ -81,6 +123,12 @@ func (c *Config) Check() error {
 	if c.L2ClaimBlockNumber == 0 {
 		return ErrInvalidL2ClaimBlock
 	}
+	if err := driver.CheckClaimsOrder(c.L2Claims, c.L2ClaimBlockNumber); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidL2Claims, err)
+	}
+	if c.L2Checkpoint != nil && c.L2Checkpoint.SafeHead.Hash != c.L2Head {
+		return fmt.Errorf("%w: safe head %s is not the l2 head", ErrInvalidL2Checkpoint, c.L2Checkpoint.SafeHead)
+	}
 	if c.L2ChainConfig == nil {
 		return ErrMissingL2Genesis
 	}
@@ -90,9 +138,23 @@ func (c *Config) Check() error {
 	if !c.FetchingEnabled() && c.DataDir == "" {
 		return ErrDataDirRequired
 	}
+	if !slices.Contains(types.SupportedDataFormats, c.DataFormat) {
+		return ErrInvalidDataFormat
+	}
 	if c.ServerMode && c.ExecCmd != "" {
 		return ErrNoExecInServerMode
 	}
+	if c.SegmentBlocks > 0 {
+		if c.ServerMode {
+			return ErrNoSegmentsInServer
+		}
+		if !c.FetchingEnabled() {
+			return ErrSegmentsNeedFetch
+		}
+		if c.ExecCmd != "" {
+			return ErrNoSegmentsWithExec
+		}
+	}
 	return nil
 }
 
@@ -100,6 +162,14 @@ func (c *Config) FetchingEnabled() bool {
 	return c.L1URL != "" && c.L2URL != ""
 }
 
+// PreimagePublicPath returns the path to write the zkWasm public input to.
+func (c *Config) PreimagePublicPath() string {
+	if c.PreimagePublicFile != "" {
+		return c.PreimagePublicFile
+	}
+	return c.PreimageFile + ".public"
+}
+
 // NewConfig creates a Config with all optional values set to the CLI default value
 func NewConfig(rollupCfg *rollup.Config, l2Genesis *params.ChainConfig, l1Head common.Hash, l2Head common.Hash, l2Claim common.Hash, l2ClaimBlockNum uint64) *Config {
 	return &Config{
@@ -110,6 +180,7 @@ func NewConfig(rollupCfg *rollup.Config, l2Genesis *params.ChainConfig, l1Head c
 		L2Claim:            l2Claim,
 		L2ClaimBlockNumber: l2ClaimBlockNum,
 		L1RPCKind:          sources.RPCKindBasic,
+		DataFormat:         types.DataFormatDirectory,
 	}
 }
 
@@ -130,6 +201,14 @@ func NewConfigFromCLI(log log.Logger, ctx *cli.Context) (*Config, error) {
 		return nil, ErrInvalidL2Claim
 	}
 	l2ClaimBlockNum := ctx.Uint64(flags.L2BlockNumber.Name)
+	l2Claims, err := parseClaims(ctx.StringSlice(flags.L2Claims.Name))
+	if err != nil {
+		return nil, err
+	}
+	l2Checkpoint, err := parseCheckpoint(ctx.String(flags.L2Checkpoint.Name))
+	if err != nil {
+		return nil, err
+	}
 	l1Head := common.HexToHash(ctx.String(flags.L1Head.Name))
 	if l1Head == (common.Hash{}) {
 		return nil, ErrInvalidL1Head
@@ -151,21 +230,66 @@ func NewConfigFromCLI(log log.Logger, ctx *cli.Context) (*Config, error) {
 	return &Config{
 		Rollup:             rollupCfg,
 		DataDir:            ctx.String(flags.DataDir.Name),
+		DataFormat:         types.DataFormat(ctx.String(flags.DataFormat.Name)),
 		L2URL:              ctx.String(flags.L2NodeAddr.Name),
 		L2ChainConfig:      l2ChainConfig,
 		L2Head:             l2Head,
 		L2Claim:            l2Claim,
 		L2ClaimBlockNumber: l2ClaimBlockNum,
+		L2Claims:           l2Claims,
+		L2Checkpoint:       l2Checkpoint,
 		L1Head:             l1Head,
 		L1URL:              ctx.String(flags.L1NodeAddr.Name),
 		L1TrustRPC:         ctx.Bool(flags.L1TrustRPC.Name),
 		L1RPCKind:          sources.RPCProviderKind(ctx.String(flags.L1RPCProviderKind.Name)),
 		ExecCmd:            ctx.String(flags.Exec.Name),
 		ServerMode:         ctx.Bool(flags.Server.Name),
+		PrefetchWorkers:    ctx.Uint(flags.PrefetchWorkers.Name),
+		SegmentBlocks:      ctx.Uint64(flags.SegmentBlocks.Name),
 		PreimageFile:       ctx.String(flags.PreimageFile.Name),
+		PreimagePublicFile: ctx.String(flags.PreimagePublicFile.Name),
+		PreimageCompress:   ctx.Bool(flags.PreimageCompress.Name),
+		PreimageTrace:      ctx.String(flags.PreimageTrace.Name),
 	}, nil
 }
 
+// parseClaims parses claims formatted as <blocknumber>:<outputroot>.
+func parseClaims(values []string) ([]driver.Claim, error) {
+	var claims []driver.Claim
+	for _, v := range values {
+		num, root, ok := strings.Cut(v, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not formatted as <blocknumber>:<outputroot>", ErrInvalidL2Claims, v)
+		}
+		blockNum, err := strconv.ParseUint(num, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid block number in %q: %v", ErrInvalidL2Claims, v, err)
+		}
+		var outputRoot common.Hash
+		if err := outputRoot.UnmarshalText([]byte(root)); err != nil {
+			return nil, fmt.Errorf("%w: invalid output root in %q: %v", ErrInvalidL2Claims, v, err)
+		}
+		claims = append(claims, driver.Claim{BlockNumber: blockNum, OutputRoot: eth.Bytes32(outputRoot)})
+	}
+	return claims, nil
+}
+
+// parseCheckpoint parses a hex encoded checkpoint, as output by the client. An empty value is no checkpoint.
+func parseCheckpoint(value string) (*driver.Checkpoint, error) {
+	if value == "" {
+		return nil, nil
+	}
+	data, err := hexutil.Decode(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidL2Checkpoint, err)
+	}
+	var checkpoint driver.Checkpoint
+	if err := checkpoint.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidL2Checkpoint, err)
+	}
+	return &checkpoint, nil
+}
+
 func loadChainConfigFromGenesis(path string) (*params.ChainConfig, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -4,7 +4,10 @@ import (
 	"testing"
 
 	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
+	"github.com/ethereum-optimism/optimism/op-program/host/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/stretchr/testify/require"
@@ -142,6 +145,22 @@ func TestRequireDataDirInNonFetchingMode(t *testing.T) {
 	require.ErrorIs(t, err, ErrDataDirRequired)
 }
 
+func TestDataFormat(t *testing.T) {
+	for _, format := range types.SupportedDataFormats {
+		format := format
+		t.Run(format.String(), func(t *testing.T) {
+			cfg := validConfig()
+			cfg.DataFormat = format
+			require.NoError(t, cfg.Check())
+		})
+	}
+	t.Run("Invalid", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.DataFormat = "txt"
+		require.ErrorIs(t, cfg.Check(), ErrInvalidDataFormat)
+	})
+}
+
 func TestRejectExecAndServerMode(t *testing.T) {
 	cfg := validConfig()
 	cfg.ServerMode = true
@@ -155,3 +174,63 @@ func validConfig() *Config {
 	cfg.DataDir = "/tmp/configTest"
 	return cfg
 }
+
+func TestSegments(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.SegmentBlocks = 10
+		cfg.L1URL = "https://example.com:1234"
+		cfg.L2URL = "https://example.com:5678"
+		require.NoError(t, cfg.Check())
+	})
+	t.Run("RequireFetching", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.SegmentBlocks = 10
+		require.ErrorIs(t, cfg.Check(), ErrSegmentsNeedFetch)
+	})
+	t.Run("RejectServerMode", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.SegmentBlocks = 10
+		cfg.ServerMode = true
+		require.ErrorIs(t, cfg.Check(), ErrNoSegmentsInServer)
+	})
+	t.Run("RejectExec", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.SegmentBlocks = 10
+		cfg.L1URL = "https://example.com:1234"
+		cfg.L2URL = "https://example.com:5678"
+		cfg.ExecCmd = "./op-program-client"
+		require.ErrorIs(t, cfg.Check(), ErrNoSegmentsWithExec)
+	})
+}
+
+func TestL2Checkpoint(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.L2Checkpoint = &driver.Checkpoint{SafeHead: eth.BlockID{Hash: cfg.L2Head, Number: 10}}
+		require.NoError(t, cfg.Check())
+	})
+	t.Run("NotAtL2Head", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.L2Checkpoint = &driver.Checkpoint{SafeHead: eth.BlockID{Hash: common.Hash{0xff}, Number: 10}}
+		require.ErrorIs(t, cfg.Check(), ErrInvalidL2Checkpoint)
+	})
+}
+
+func TestL2Claims(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.L2Claims = []driver.Claim{{BlockNumber: validL2ClaimBlockNum - 1}, {BlockNumber: validL2ClaimBlockNum}}
+		require.NoError(t, cfg.Check())
+	})
+	t.Run("NotAscending", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.L2Claims = []driver.Claim{{BlockNumber: 5}, {BlockNumber: 3}}
+		require.ErrorIs(t, cfg.Check(), ErrInvalidL2Claims)
+	})
+	t.Run("AfterFinalClaim", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.L2Claims = []driver.Claim{{BlockNumber: validL2ClaimBlockNum + 1}}
+		require.ErrorIs(t, cfg.Check(), ErrInvalidL2Claims)
+	})
+}
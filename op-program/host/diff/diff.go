@@ -0,0 +1,260 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum-optimism/optimism/op-program/host/trace"
+	"github.com/ethereum-optimism/optimism/op-program/zkwasm"
+)
+
+// Verdict is the result of a client run, as reported by its exit code or output.
+type Verdict string
+
+const (
+	VerdictValid   Verdict = "valid"
+	VerdictInvalid Verdict = "invalid"
+	VerdictFailed  Verdict = "failed"
+	// VerdictUnknown is reported when the client did not complete or reported an unexpected result.
+	VerdictUnknown Verdict = "unknown"
+)
+
+// Run describes the execution of the client by one of the VMs.
+type Run struct {
+	// Keys are the pre-image keys requested by the client, in order.
+	Keys []common.Hash
+	// Exited is true if the client ran to completion.
+	Exited   bool
+	ExitCode uint32
+	Verdict  Verdict
+	// Err is the reason the run stopped before the client exited, if any.
+	Err error
+}
+
+// Report compares the runs of the same boot inputs by cannon and the wasm client.
+type Report struct {
+	Cannon *Run
+	Wasm   *Run
+	// Divergence is the index of the first pre-image requested differently by the two runs, or -1 if there is none.
+	Divergence int
+}
+
+// Diverged returns true if the runs requested different pre-images or disagree on the verdict.
+func (r *Report) Diverged() bool {
+	return r.Divergence >= 0 || r.Cannon.Verdict != r.Wasm.Verdict
+}
+
+// Check runs the boot inputs and pre-images of the trace read from r through both cannon,
+// starting from state, and the zkWasm program, and compares the pre-images they request.
+func Check(ctx context.Context, state *mipsevm.State, program []byte, r io.ReaderAt) (*Report, error) {
+	idx, err := trace.BuildIndex(io.NewSectionReader(r, 0, math.MaxInt64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to index trace: %w", err)
+	}
+	type result struct {
+		run *Run
+		err error
+	}
+	wasmResult := make(chan result, 1)
+	go func() {
+		run, err := RunWasm(ctx, program, r)
+		wasmResult <- result{run, err}
+	}()
+	cannon, err := RunCannon(ctx, state, idx, r)
+	wasm := <-wasmResult
+	if err != nil {
+		return nil, fmt.Errorf("failed to run cannon: %w", err)
+	}
+	if wasm.err != nil {
+		return nil, fmt.Errorf("failed to run wasm: %w", wasm.err)
+	}
+	return &Report{
+		Cannon:     cannon,
+		Wasm:       wasm.run,
+		Divergence: FirstDivergence(cannon.Keys, wasm.run.Keys),
+	}, nil
+}
+
+// FirstDivergence returns the index of the first key that differs between a and b,
+// including a key missing from the shorter of the two, or -1 if they are equal.
+func FirstDivergence(a, b []common.Hash) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return len(a)
+		}
+		return len(b)
+	}
+	return -1
+}
+
+// ErrKeysNotReported is returned when the wasm program does not report the keys of the pre-images it requests.
+var ErrKeysNotReported = fmt.Errorf("wasm program does not import %s, build the client with the zkwasm_debug tag", zkwasm.DebugKeyImport)
+
+// errUnknownKey stops the cannon run when the client requests a pre-image that is not in the trace.
+var errUnknownKey = errors.New("pre-image not in trace")
+
+// traceOracle serves the pre-images of a trace by key, recording the requested keys in order.
+type traceOracle struct {
+	idx  *trace.Index
+	r    io.ReaderAt
+	keys []common.Hash
+}
+
+func (o *traceOracle) Hint(v []byte) {}
+
+func (o *traceOracle) GetPreimage(k [32]byte) []byte {
+	o.keys = append(o.keys, k)
+	i, ok := o.idx.Lookup(k)
+	if !ok {
+		panic(fmt.Errorf("%w: %s", errUnknownKey, common.Hash(k)))
+	}
+	rec, err := o.idx.Record(o.r, i)
+	if err != nil {
+		panic(err)
+	}
+	return rec.Value
+}
+
+// RunCannon runs the client from state until it exits, serving pre-images from the trace by key.
+// The run stops early if the client requests a pre-image the trace does not have.
+func RunCannon(ctx context.Context, state *mipsevm.State, idx *trace.Index, r io.ReaderAt) (*Run, error) {
+	oracle := &traceOracle{idx: idx, r: r}
+	us := mipsevm.NewInstrumentedState(state, oracle, io.Discard, io.Discard)
+	run := &Run{Verdict: VerdictUnknown}
+	for !state.Exited {
+		if state.Step%100 == 0 { // don't do the ctx err check (includes lock) too often
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if err := step(us); errors.Is(err, errUnknownKey) {
+			run.Err = err
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed at step %d (PC: %08x): %w", state.Step, state.PC, err)
+		}
+	}
+	run.Keys = oracle.keys
+	if state.Exited {
+		run.Exited = true
+		run.ExitCode = uint32(state.ExitCode)
+		run.Verdict = cannonVerdict(state.ExitCode)
+	}
+	return run, nil
+}
+
+// step executes a single instruction, recovering from oracle failures.
+func step(us *mipsevm.InstrumentedState) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+	_, err = us.Step(false)
+	return err
+}
+
+// cannonVerdict maps the exit code of the native client to its verdict.
+func cannonVerdict(exitCode uint8) Verdict {
+	switch exitCode {
+	case 0:
+		return VerdictValid
+	case 1:
+		return VerdictInvalid
+	case 2:
+		return VerdictFailed
+	default:
+		return VerdictUnknown
+	}
+}
+
+// RunWasm runs the zkWasm program with the input converted from the trace.
+// The wasm client reads pre-images in trace order, and must be built with the zkwasm_debug tag
+// to report the key of each pre-image it requests to the host before reading it.
+func RunWasm(ctx context.Context, program []byte, r io.ReaderAt) (*Run, error) {
+	private, err := os.CreateTemp("", "diff-private-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(private.Name())
+	defer private.Close()
+	public, err := os.CreateTemp("", "diff-public-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(public.Name())
+	defer public.Close()
+	if err := trace.WriteZkWasmInput(private, public, io.NewSectionReader(r, 0, math.MaxInt64)); err != nil {
+		return nil, fmt.Errorf("failed to convert trace: %w", err)
+	}
+	for _, f := range []*os.File{private, public} {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	result, runErr := zkwasm.Run(ctx, program, zkwasm.Config{
+		PrivateInput: private,
+		PublicInput:  public,
+	})
+	if result == nil {
+		return nil, runErr
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return wasmRun(result, runErr)
+}
+
+// wasmRun describes the run of the wasm client from the result of the emulator.
+func wasmRun(result *zkwasm.Result, runErr error) (*Run, error) {
+	if !result.KeysReported {
+		return nil, ErrKeysNotReported
+	}
+	run := &Run{Keys: result.RequestedKeys, ExitCode: result.ExitCode, Verdict: VerdictUnknown}
+	last := len(run.Keys) - 1
+	switch {
+	case errors.Is(runErr, zkwasm.ErrRequireFailed) && last >= 0 && preimage.KeyType(run.Keys[last][0]) != preimage.LocalKeyType:
+		// The client only requires the integrity check of private pre-images to be satisfied,
+		// so the next record of the trace was not the pre-image the client requested.
+		run.Err = fmt.Errorf("integrity check of pre-image %d (%s) failed, the trace has a different pre-image next", last, run.Keys[last])
+	case runErr != nil:
+		run.Err = runErr
+	default:
+		run.Exited = true
+		if output, ok := result.Output(); ok {
+			run.Verdict = wasmVerdict(output)
+		}
+	}
+	return run, nil
+}
+
+// wasmVerdict maps the result code output by the wasm client to its verdict.
+func wasmVerdict(output uint64) Verdict {
+	switch output {
+	case zkwasm.OutputClaimValid:
+		return VerdictValid
+	case zkwasm.OutputClaimInvalid:
+		return VerdictInvalid
+	case zkwasm.OutputProgramFailed:
+		return VerdictFailed
+	default:
+		return VerdictUnknown
+	}
+}
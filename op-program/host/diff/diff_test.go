@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/cannon/mipsevm"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum-optimism/optimism/op-program/host/trace"
+	"github.com/ethereum-optimism/optimism/op-program/zkwasm"
+)
+
+func keccakRecord(value []byte) trace.Record {
+	return trace.Record{Key: preimage.Keccak256Key(crypto.Keccak256Hash(value)).PreimageKey(), Value: value}
+}
+
+func localRecord(i uint64, value []byte) trace.Record {
+	return trace.Record{Key: preimage.LocalIndexKey(i).PreimageKey(), Value: value}
+}
+
+func indexTrace(t *testing.T, records ...trace.Record) (*trace.Index, *bytes.Reader) {
+	var buf bytes.Buffer
+	w, err := trace.NewWriter(&buf, trace.Header{})
+	require.NoError(t, err)
+	for _, rec := range records {
+		require.NoError(t, w.Write(rec.Key, rec.Value))
+	}
+	require.NoError(t, w.Close())
+	idx, err := trace.BuildIndex(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	return idx, bytes.NewReader(buf.Bytes())
+}
+
+func TestFirstDivergence(t *testing.T) {
+	a, b, c := common.Hash{0x01}, common.Hash{0x02}, common.Hash{0x03}
+	tests := []struct {
+		name     string
+		x, y     []common.Hash
+		expected int
+	}{
+		{"Empty", nil, nil, -1},
+		{"Equal", []common.Hash{a, b}, []common.Hash{a, b}, -1},
+		{"Differ", []common.Hash{a, b, c}, []common.Hash{a, c, b}, 1},
+		{"FirstShorter", []common.Hash{a}, []common.Hash{a, b}, 1},
+		{"SecondShorter", []common.Hash{a, b}, nil, 0},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, FirstDivergence(test.x, test.y))
+		})
+	}
+}
+
+func TestWasmRun(t *testing.T) {
+	local0, hashA := localRecord(1, []byte("a")), keccakRecord([]byte("x"))
+
+	t.Run("KeysNotReported", func(t *testing.T) {
+		_, err := wasmRun(&zkwasm.Result{}, nil)
+		require.ErrorIs(t, err, ErrKeysNotReported)
+	})
+
+	t.Run("ReportedKeys", func(t *testing.T) {
+		run, err := wasmRun(&zkwasm.Result{KeysReported: true, RequestedKeys: []common.Hash{local0.Key, hashA.Key}}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []common.Hash{local0.Key, hashA.Key}, run.Keys)
+		require.True(t, run.Exited)
+		require.NoError(t, run.Err)
+	})
+
+	t.Run("IntegrityCheckFailed", func(t *testing.T) {
+		run, err := wasmRun(&zkwasm.Result{KeysReported: true, RequestedKeys: []common.Hash{local0.Key, hashA.Key}}, zkwasm.ErrRequireFailed)
+		require.NoError(t, err)
+		require.Equal(t, []common.Hash{local0.Key, hashA.Key}, run.Keys, "the key failing its check was requested")
+		require.False(t, run.Exited)
+		require.ErrorContains(t, run.Err, "integrity check of pre-image 1")
+	})
+
+	t.Run("RequireFailedAfterLocalKey", func(t *testing.T) {
+		run, err := wasmRun(&zkwasm.Result{KeysReported: true, RequestedKeys: []common.Hash{local0.Key}}, zkwasm.ErrRequireFailed)
+		require.NoError(t, err)
+		require.ErrorIs(t, run.Err, zkwasm.ErrRequireFailed)
+	})
+}
+
+func TestTraceOracle(t *testing.T) {
+	hashA, hashB := keccakRecord([]byte("x")), keccakRecord([]byte("y"))
+	idx, r := indexTrace(t, hashA, hashB)
+	oracle := &traceOracle{idx: idx, r: r}
+
+	require.Equal(t, hashB.Value, oracle.GetPreimage(hashB.Key))
+	require.Equal(t, hashA.Value, oracle.GetPreimage(hashA.Key))
+	unknown := keccakRecord([]byte("z"))
+	require.Panics(t, func() { oracle.GetPreimage(unknown.Key) })
+	require.Equal(t, []common.Hash{hashB.Key, hashA.Key, unknown.Key}, oracle.keys)
+}
+
+func TestRunCannon(t *testing.T) {
+	for exitCode, verdict := range map[uint32]Verdict{0: VerdictValid, 1: VerdictInvalid, 2: VerdictFailed, 3: VerdictUnknown} {
+		exitCode, verdict := exitCode, verdict
+		t.Run(string(verdict), func(t *testing.T) {
+			state := &mipsevm.State{PC: 0, NextPC: 4, Memory: mipsevm.NewMemory()}
+			state.Memory.SetMemory(0, 0x24021096)          // addiu $v0, $zero, 4246 (exit_group)
+			state.Memory.SetMemory(4, 0x24040000|exitCode) // addiu $a0, $zero, exitCode
+			state.Memory.SetMemory(8, 0x0000000c)          // syscall
+			idx, r := indexTrace(t)
+
+			run, err := RunCannon(context.Background(), state, idx, r)
+			require.NoError(t, err)
+			require.True(t, run.Exited)
+			require.Equal(t, exitCode, run.ExitCode)
+			require.Equal(t, verdict, run.Verdict)
+			require.Empty(t, run.Keys)
+			require.NoError(t, run.Err)
+		})
+	}
+}
+
+func TestReportDiverged(t *testing.T) {
+	valid := &Run{Verdict: VerdictValid}
+	require.False(t, (&Report{Cannon: valid, Wasm: valid, Divergence: -1}).Diverged())
+	require.True(t, (&Report{Cannon: valid, Wasm: valid, Divergence: 3}).Diverged())
+	require.True(t, (&Report{Cannon: valid, Wasm: &Run{Verdict: VerdictFailed}, Divergence: -1}).Diverged())
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
 	"github.com/ethereum-optimism/optimism/op-node/sources"
+	"github.com/ethereum-optimism/optimism/op-program/host/types"
 	service "github.com/ethereum-optimism/optimism/op-service"
 	openum "github.com/ethereum-optimism/optimism/op-service/enum"
 	oplog "github.com/ethereum-optimism/optimism/op-service/log"
@@ -35,6 +36,12 @@ var (
 		Usage:   "Directory to use for preimage data storage. Default uses in-memory storage",
 		EnvVars: prefixEnvVars("DATADIR"),
 	}
+	DataFormat = &cli.StringFlag{
+		Name:    "data.format",
+		Usage:   fmt.Sprintf("Format to use for preimage data storage. Available formats: %s", openum.EnumString(types.SupportedDataFormats)),
+		EnvVars: prefixEnvVars("DATA_FORMAT"),
+		Value:   string(types.DataFormatDirectory),
+	}
 	L2NodeAddr = &cli.StringFlag{
 		Name:    "l2",
 		Usage:   "Address of L2 JSON-RPC endpoint to use (eth and debug namespace required)",
@@ -60,6 +67,16 @@ var (
 		Usage:   "Number of the L2 block that the claim is from",
 		EnvVars: prefixEnvVars("L2_BLOCK_NUM"),
 	}
+	L2Claims = &cli.StringSliceFlag{
+		Name:    "l2.claims",
+		Usage:   "Additional L2 output roots to check as derivation passes through their blocks, before the --l2.claim. Each formatted as <blocknumber>:<outputroot>, in ascending block order.",
+		EnvVars: prefixEnvVars("L2_CLAIMS"),
+	}
+	L2Checkpoint = &cli.StringFlag{
+		Name:    "l2.checkpoint",
+		Usage:   "Hex encoded checkpoint output by a previous segment, to resume derivation from. Its safe head must be the --l2.head.",
+		EnvVars: prefixEnvVars("L2_CHECKPOINT"),
+	}
 	L2GenesisPath = &cli.StringFlag{
 		Name:    "l2.genesis",
 		Usage:   "Path to the op-geth genesis file",
@@ -98,9 +115,34 @@ var (
 
 	PreimageFile = &cli.StringFlag{
 		Name:    "preimage",
-		Usage:   "Specify the preimage output path when running in pre-image server mode with client program.",
+		Usage:   "Specify the zkWasm pre-image input output path. The input is derived from the recorded pre-image trace after the run.",
 		EnvVars: prefixEnvVars("PREIMAGE_FILE"),
 	}
+	PrefetchWorkers = &cli.UintFlag{
+		Name:    "prefetch.workers",
+		Usage:   "Prefetch pre-images in the background with this many concurrent requests, including the transactions and receipts of L1 blocks and the state accessed by the L2 blocks up to the claim. Requires an L2 node with the prestate tracer. 0 fetches every pre-image when it is requested.",
+		EnvVars: prefixEnvVars("PREFETCH_WORKERS"),
+	}
+	SegmentBlocks = &cli.Uint64Flag{
+		Name:    "segment.blocks",
+		Usage:   "Split the run into segments deriving at most this many L2 blocks each, with a separate pre-image trace and zkWasm input per segment. Requires fetching from L1 and L2 nodes. 0 disables segmenting.",
+		EnvVars: prefixEnvVars("SEGMENT_BLOCKS"),
+	}
+	PreimagePublicFile = &cli.StringFlag{
+		Name:    "preimage.public",
+		Usage:   "Specify the zkWasm public input output path, holding the boot values of the run. Defaults to the --preimage path with a .public suffix.",
+		EnvVars: prefixEnvVars("PREIMAGE_PUBLIC_FILE"),
+	}
+	PreimageCompress = &cli.BoolFlag{
+		Name:    "preimage.compress",
+		Usage:   "Gzip compress the zkWasm pre-image and public inputs.",
+		EnvVars: prefixEnvVars("PREIMAGE_COMPRESS"),
+	}
+	PreimageTrace = &cli.StringFlag{
+		Name:    "preimage.trace",
+		Usage:   "Path to record the trace of all served pre-images to, with their keys and the boot info of the run.",
+		EnvVars: prefixEnvVars("PREIMAGE_TRACE"),
+	}
 )
 
 // Flags contains the list of configuration options available to the binary.
@@ -113,9 +155,12 @@ var requiredFlags = []cli.Flag{
 	L2BlockNumber,
 }
 var programFlags = []cli.Flag{
+	L2Claims,
+	L2Checkpoint,
 	RollupConfig,
 	Network,
 	DataDir,
+	DataFormat,
 	L2NodeAddr,
 	L2GenesisPath,
 	L1NodeAddr,
@@ -123,7 +168,12 @@ var programFlags = []cli.Flag{
 	L1RPCProviderKind,
 	Exec,
 	Server,
+	PrefetchWorkers,
+	SegmentBlocks,
 	PreimageFile,
+	PreimagePublicFile,
+	PreimageCompress,
+	PreimageTrace,
 }
 
 func init() {
@@ -8,6 +8,7 @@ import (
 	"io/fs"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
@@ -20,6 +21,8 @@ import (
 	"github.com/ethereum-optimism/optimism/op-program/host/flags"
 	"github.com/ethereum-optimism/optimism/op-program/host/kvstore"
 	"github.com/ethereum-optimism/optimism/op-program/host/prefetcher"
+	"github.com/ethereum-optimism/optimism/op-program/host/trace"
+	"github.com/ethereum-optimism/optimism/op-program/host/types"
 	oppio "github.com/ethereum-optimism/optimism/op-program/io"
 	opservice "github.com/ethereum-optimism/optimism/op-service"
 	"github.com/ethereum/go-ethereum/common"
@@ -31,6 +34,8 @@ type L2Source struct {
 	*sources.DebugClient
 }
 
+var _ prefetcher.L2StateSource = (*L2Source)(nil)
+
 func Main(logger log.Logger, cfg *config.Config) error {
 	if err := cfg.Check(); err != nil {
 		return fmt.Errorf("invalid config: %w", err)
@@ -38,23 +43,42 @@ func Main(logger log.Logger, cfg *config.Config) error {
 	opservice.ValidateEnvVars(flags.EnvVarPrefix, flags.Flags, logger)
 	cfg.Rollup.LogDescription(logger, chaincfg.L2ChainIDToNetworkName)
 
-	if cfg.PreimageFile != "" {
-		preimageFile, err := os.Create(cfg.PreimageFile)
+	if cfg.PreimageFile != "" && cfg.PreimageTrace == "" {
+		// The zkWasm input is derived from the pre-image trace, so record one even if it isn't kept.
+		dir, err := os.MkdirTemp("", "op-program-trace")
 		if err != nil {
-			log.Crit("Failed to create preimage file, please provide a correct path", "err", err)
+			return fmt.Errorf("failed to create temporary trace dir: %w", err)
 		}
-		preimage.PreimageFile = preimageFile
-		defer preimage.PreimageFile.Close()
+		defer os.RemoveAll(dir)
+		traceCfg := *cfg
+		traceCfg.PreimageTrace = filepath.Join(dir, "trace.bin")
+		cfg = &traceCfg
 	}
 
 	ctx := context.Background()
+	var err error
 	if cfg.ServerMode {
 		preimageChan := cl.CreatePreimageChannel()
 		hinterChan := cl.CreateHinterChannel()
-		return PreimageServer(ctx, logger, cfg, preimageChan, hinterChan)
+		err = PreimageServer(ctx, logger, cfg, preimageChan, hinterChan)
+	} else if cfg.SegmentBlocks > 0 {
+		// Segments write their own zkWasm inputs
+		err = RunSegments(ctx, logger, cfg)
+	} else {
+		err = FaultProofProgram(ctx, logger, cfg)
+	}
+
+	if cfg.PreimageFile != "" && cfg.SegmentBlocks == 0 && (err == nil || errors.Is(err, driver.ErrClaimNotValid)) {
+		if err := writeZkWasmInput(cfg.PreimageTrace, cfg.PreimageFile, cfg.PreimagePublicPath(), cfg.PreimageCompress); err != nil {
+			return err
+		}
+		logger.Info("Wrote zkWasm pre-image input", "private", cfg.PreimageFile, "public", cfg.PreimagePublicPath())
 	}
 
-	if err := FaultProofProgram(ctx, logger, cfg); errors.Is(err, driver.ErrClaimNotValid) {
+	if cfg.ServerMode {
+		return err
+	}
+	if errors.Is(err, driver.ErrClaimNotValid) {
 		log.Crit("Claim is invalid", "err", err)
 	} else if err != nil {
 		return err
@@ -65,8 +89,42 @@ func Main(logger log.Logger, cfg *config.Config) error {
 	return nil
 }
 
+// writeZkWasmInput converts the pre-image trace at tracePath into the zkWasm prover private and public inputs,
+// gzip compressed if compress is set.
+func writeZkWasmInput(tracePath string, privatePath string, publicPath string, compress bool) error {
+	in, err := os.Open(tracePath)
+	if err != nil {
+		return fmt.Errorf("failed to open pre-image trace: %w", err)
+	}
+	defer in.Close()
+	private, err := preimage.NewFileRecorder(privatePath, compress)
+	if err != nil {
+		return fmt.Errorf("failed to create zkWasm input file: %w", err)
+	}
+	defer private.Close()
+	public, err := preimage.NewFileRecorder(publicPath, compress)
+	if err != nil {
+		return fmt.Errorf("failed to create zkWasm public input file: %w", err)
+	}
+	defer public.Close()
+	if err := trace.RecordZkWasmInput(private, public, in); err != nil {
+		return fmt.Errorf("failed to convert pre-image trace to zkWasm input: %w", err)
+	}
+	if err := private.Close(); err != nil {
+		return fmt.Errorf("failed to close zkWasm input file: %w", err)
+	}
+	return public.Close()
+}
+
 // FaultProofProgram is the programmatic entry-point for the fault proof program
 func FaultProofProgram(ctx context.Context, logger log.Logger, cfg *config.Config) error {
+	_, err := faultProofProgram(ctx, logger, cfg)
+	return err
+}
+
+// faultProofProgram runs the fault proof program, returning the checkpoint reached by the client.
+// The checkpoint is only known if the client runs in-process and completed the derivation.
+func faultProofProgram(ctx context.Context, logger log.Logger, cfg *config.Config) (*driver.Checkpoint, error) {
 	var (
 		serverErr chan error
 		pClientRW oppio.FileChannel
@@ -90,13 +148,13 @@ func FaultProofProgram(ctx context.Context, logger log.Logger, cfg *config.Confi
 	// Setup client I/O for preimage oracle interaction
 	pClientRW, pHostRW, err := oppio.CreateBidirectionalChannel()
 	if err != nil {
-		return fmt.Errorf("failed to create preimage pipe: %w", err)
+		return nil, fmt.Errorf("failed to create preimage pipe: %w", err)
 	}
 
 	// Setup client I/O for hint comms
 	hClientRW, hHostRW, err := oppio.CreateBidirectionalChannel()
 	if err != nil {
-		return fmt.Errorf("failed to create hints pipe: %w", err)
+		return nil, fmt.Errorf("failed to create hints pipe: %w", err)
 	}
 
 	// Use a channel to receive the server result so we can wait for it to complete before returning
@@ -106,39 +164,63 @@ func FaultProofProgram(ctx context.Context, logger log.Logger, cfg *config.Confi
 		serverErr <- PreimageServer(ctx, logger, cfg, pHostRW, hHostRW)
 	}()
 
-	var cmd *exec.Cmd
 	if cfg.ExecCmd != "" {
-		parts := strings.Fields(cfg.ExecCmd)
-		cmd = exec.CommandContext(ctx, parts[0], parts[1:]...)
-		cmd.ExtraFiles = make([]*os.File, cl.MaxFd-3) // not including stdin, stdout and stderr
-		cmd.ExtraFiles[cl.HClientRFd-3] = hClientRW.Reader()
-		cmd.ExtraFiles[cl.HClientWFd-3] = hClientRW.Writer()
-		cmd.ExtraFiles[cl.PClientRFd-3] = pClientRW.Reader()
-		cmd.ExtraFiles[cl.PClientWFd-3] = pClientRW.Writer()
-		cmd.Stdout = os.Stdout // for debugging
-		cmd.Stderr = os.Stderr // for debugging
-
-		err := cmd.Start()
-		if err != nil {
-			return fmt.Errorf("program cmd failed to start: %w", err)
-		}
-		if err := cmd.Wait(); err != nil {
-			return fmt.Errorf("failed to wait for child program: %w", err)
-		}
-		logger.Debug("Client program completed successfully")
-		return nil
+		return nil, runClientCmd(ctx, logger, cfg.ExecCmd, pClientRW, hClientRW)
 	} else {
 		return cl.RunProgram(logger, pClientRW, hClientRW)
 	}
 }
 
+// runClientCmd runs the client program command in a separate process, attached to the given pre-image and hint channels.
+func runClientCmd(ctx context.Context, logger log.Logger, execCmd string, pClientRW oppio.FileChannel, hClientRW oppio.FileChannel) error {
+	parts := strings.Fields(execCmd)
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
+	cmd.ExtraFiles = make([]*os.File, cl.MaxFd-3) // not including stdin, stdout and stderr
+	cmd.ExtraFiles[cl.HClientRFd-3] = hClientRW.Reader()
+	cmd.ExtraFiles[cl.HClientWFd-3] = hClientRW.Writer()
+	cmd.ExtraFiles[cl.PClientRFd-3] = pClientRW.Reader()
+	cmd.ExtraFiles[cl.PClientWFd-3] = pClientRW.Writer()
+	cmd.Stdout = os.Stdout // for debugging
+	cmd.Stderr = os.Stderr // for debugging
+
+	err := cmd.Start()
+	if err != nil {
+		return fmt.Errorf("program cmd failed to start: %w", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("failed to wait for child program: %w", err)
+	}
+	logger.Debug("Client program completed successfully")
+	return nil
+}
+
 // PreimageServer reads hints and preimage requests from the provided channels and processes those requests.
 // This method will block until both the hinter and preimage handlers complete.
 // If either returns an error both handlers are stopped.
 // The supplied preimageChannel and hintChannel will be closed before this function returns.
-func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config, preimageChannel oppio.FileChannel, hintChannel oppio.FileChannel) error {
+func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config, preimageChannel oppio.FileChannel, hintChannel oppio.FileChannel) (err error) {
+	var traceWriter *trace.FileWriter
+	if cfg.PreimageTrace != "" {
+		header, err := trace.NewHeader(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create pre-image trace header: %w", err)
+		}
+		traceWriter, err = trace.CreateFile(cfg.PreimageTrace, header)
+		if err != nil {
+			return err
+		}
+		logger.Info("Recording pre-image trace", "path", cfg.PreimageTrace)
+		// Registered first, so the trace is only finalized after the pre-image server has stopped.
+		defer func() {
+			if closeErr := traceWriter.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("failed to close pre-image trace: %w", closeErr)
+			}
+			logger.Debug("Closed pre-image trace", "records", traceWriter.Count())
+		}()
+	}
 	var serverDone chan error
 	var hinterDone chan error
+	var kvCloser, prefetchCloser io.Closer
 	defer func() {
 		preimageChannel.Close()
 		hintChannel.Close()
@@ -150,6 +232,16 @@ func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config,
 			// Wait for hinter to complete
 			<-hinterDone
 		}
+		if prefetchCloser != nil {
+			// Stop background prefetching before closing the pre-image store it writes to
+			_ = prefetchCloser.Close()
+		}
+		if kvCloser != nil {
+			// Only close the pre-image store once nothing reads from or writes to it anymore
+			if closeErr := kvCloser.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("failed to close pre-image store: %w", closeErr)
+			}
+		}
 	}()
 	logger.Info("Starting preimage server")
 	var kv kvstore.KV
@@ -157,11 +249,15 @@ func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config,
 		logger.Info("Using in-memory storage")
 		kv = kvstore.NewMemKV()
 	} else {
-		logger.Info("Creating disk storage", "datadir", cfg.DataDir)
+		logger.Info("Creating disk storage", "datadir", cfg.DataDir, "format", cfg.DataFormat)
 		if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
 			return fmt.Errorf("creating datadir: %w", err)
 		}
-		kv = kvstore.NewDiskKV(cfg.DataDir)
+		kv, err = NewDiskKV(cfg.DataDir, cfg.DataFormat)
+		if err != nil {
+			return err
+		}
+		kvCloser, _ = kv.(io.Closer)
 	}
 
 	var (
@@ -173,6 +269,7 @@ func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config,
 		if err != nil {
 			return fmt.Errorf("failed to create prefetcher: %w", err)
 		}
+		prefetchCloser = prefetch
 		getPreimage = func(key common.Hash) ([]byte, error) { return prefetch.GetPreimage(ctx, key) }
 		hinter = prefetch.Hint
 	} else {
@@ -188,7 +285,12 @@ func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config,
 	splitter := kvstore.NewPreimageSourceSplitter(localPreimageSource.Get, getPreimage)
 	preimageGetter := splitter.Get
 
-	serverDone = launchOracleServer(logger, preimageChannel, preimageGetter)
+	recorder := cfg.PreimageRecorder
+	if traceWriter != nil {
+		recorder = preimage.MultiRecorder(recorder, traceWriter)
+	}
+
+	serverDone = launchOracleServer(logger, preimageChannel, preimageGetter, recorder)
 	hinterDone = routeHints(logger, hintChannel, hinter)
 	select {
 	case err := <-serverDone:
@@ -198,6 +300,19 @@ func PreimageServer(ctx context.Context, logger log.Logger, cfg *config.Config,
 	}
 }
 
+// NewDiskKV opens the pre-image store of the given format in the dir path.
+// Stores that implement io.Closer must be closed by the caller.
+func NewDiskKV(dir string, format types.DataFormat) (kvstore.KV, error) {
+	switch format {
+	case types.DataFormatDirectory:
+		return kvstore.NewDiskKV(dir), nil
+	case types.DataFormatLevelDB:
+		return kvstore.NewLevelDBKV(dir)
+	default:
+		return nil, fmt.Errorf("invalid data format: %q", format)
+	}
+}
+
 func makePrefetcher(ctx context.Context, logger log.Logger, kv kvstore.KV, cfg *config.Config) (*prefetcher.Prefetcher, error) {
 	logger.Info("Connecting to L1 node", "l1", cfg.L1URL)
 	l1RPC, err := client.NewRPC(ctx, logger, cfg.L1URL)
@@ -222,7 +337,13 @@ func makePrefetcher(ctx context.Context, logger log.Logger, kv kvstore.KV, cfg *
 		return nil, fmt.Errorf("failed to create L2 client: %w", err)
 	}
 	l2DebugCl := &L2Source{L2Client: l2Cl, DebugClient: sources.NewDebugClient(l2RPC.CallContext)}
-	return prefetcher.NewPrefetcher(logger, l1Cl, l2DebugCl, kv), nil
+	if cfg.PrefetchWorkers == 0 {
+		return prefetcher.NewPrefetcher(logger, l1Cl, l2DebugCl, kv), nil
+	}
+	logger.Info("Prefetching in the background", "workers", cfg.PrefetchWorkers)
+	prefetch := prefetcher.NewParallelPrefetcher(logger, l1Cl, l2DebugCl, kv, int(cfg.PrefetchWorkers))
+	prefetch.PrefetchL2Blocks(cfg.L2Head, cfg.L2ClaimBlockNumber)
+	return prefetch, nil
 }
 
 func routeHints(logger log.Logger, hHostRW io.ReadWriter, hinter preimage.HintHandler) chan error {
@@ -245,9 +366,9 @@ func routeHints(logger log.Logger, hHostRW io.ReadWriter, hinter preimage.HintHa
 	return chErr
 }
 
-func launchOracleServer(logger log.Logger, pHostRW io.ReadWriteCloser, getter preimage.PreimageGetter) chan error {
+func launchOracleServer(logger log.Logger, pHostRW io.ReadWriteCloser, getter preimage.PreimageGetter, recorder preimage.PreimageRecorder) chan error {
 	chErr := make(chan error)
-	server := preimage.NewOracleServer(pHostRW)
+	server := preimage.NewOracleServer(pHostRW, recorder)
 	go func() {
 		defer close(chErr)
 		for {
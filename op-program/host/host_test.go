@@ -3,6 +3,8 @@ package host
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-program/client/l1"
 	"github.com/ethereum-optimism/optimism/op-program/host/config"
 	"github.com/ethereum-optimism/optimism/op-program/host/kvstore"
+	"github.com/ethereum-optimism/optimism/op-program/host/trace"
 	"github.com/ethereum-optimism/optimism/op-program/io"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
@@ -52,6 +55,44 @@ func TestServerMode(t *testing.T) {
 	require.ErrorIs(t, waitFor(result), kvstore.ErrNotFound)
 }
 
+func TestServerModeRecordsPreimages(t *testing.T) {
+	dir := t.TempDir()
+
+	l1Head := common.Hash{0x11}
+	cfg := config.NewConfig(&chaincfg.Goerli, config.OPGoerliChainConfig, l1Head, common.Hash{0x22}, common.Hash{0x33}, 1000)
+	cfg.DataDir = dir
+	cfg.ServerMode = true
+	cfg.PreimageTrace = filepath.Join(dir, "trace.bin")
+	recorder := preimage.NewMemRecorder()
+	cfg.PreimageRecorder = recorder
+
+	preimageServer, preimageClient, err := io.CreateBidirectionalChannel()
+	require.NoError(t, err)
+	hintServer, hintClient, err := io.CreateBidirectionalChannel()
+	require.NoError(t, err)
+	defer hintClient.Close()
+	logger := testlog.Logger(t, log.LvlTrace)
+	result := make(chan error)
+	go func() {
+		result <- PreimageServer(context.Background(), logger, cfg, preimageServer, hintServer)
+	}()
+
+	pClient := preimage.NewOracleClient(preimageClient)
+	require.Equal(t, l1Head.Bytes(), pClient.Get(client.L1HeadLocalIndex))
+	require.Equal(t, cfg.L2Claim.Bytes(), pClient.Get(client.L2ClaimLocalIndex))
+	require.NoError(t, preimageClient.Close())
+	require.NoError(t, waitFor(result))
+
+	f, err := os.Open(cfg.PreimageTrace)
+	require.NoError(t, err)
+	defer f.Close()
+	summary, err := trace.Verify(f)
+	require.NoError(t, err)
+	require.Equal(t, l1Head, summary.Header.L1Head)
+	require.EqualValues(t, 2, summary.Records)
+	require.Equal(t, [][32]byte{client.L1HeadLocalIndex.PreimageKey(), client.L2ClaimLocalIndex.PreimageKey()}, recorder.Keys())
+}
+
 func waitFor(ch chan error) error {
 	timeout := time.After(30 * time.Second)
 	select {
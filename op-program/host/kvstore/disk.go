@@ -7,9 +7,11 @@ import (
 	"io"
 	"os"
 	"path"
+	"strings"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 // read/write mode for user/group/other, not executable.
@@ -72,4 +74,27 @@ func (d *DiskKV) Get(k common.Hash) ([]byte, error) {
 	return hex.DecodeString(string(dat))
 }
 
+// Keys returns the keys of all pre-images in the DiskKV directory.
+// Files that are not named like a pre-image are ignored.
+func (d *DiskKV) Keys() ([]common.Hash, error) {
+	d.RLock()
+	defer d.RUnlock()
+	entries, err := os.ReadDir(d.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pre-image files: %w", err)
+	}
+	var keys []common.Hash
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+			continue
+		}
+		b, err := hexutil.Decode(strings.TrimSuffix(entry.Name(), ".txt"))
+		if err != nil || len(b) != common.HashLength {
+			continue
+		}
+		keys = append(keys, common.BytesToHash(b))
+	}
+	return keys, nil
+}
+
 var _ KV = (*DiskKV)(nil)
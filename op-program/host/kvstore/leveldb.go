@@ -0,0 +1,65 @@
+package kvstore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+// LevelDBKV is a key-value store backed by a LevelDB database, storing the raw pre-image bytes
+// in a few large files instead of a file per pre-image.
+// LevelDBKV is safe for concurrent use. A database can only be opened by a single LevelDBKV at a time.
+type LevelDBKV struct {
+	// Held for the existence check and write of Put, so concurrent puts of the same key cannot both succeed.
+	sync.Mutex
+	db *leveldb.DB
+}
+
+// NewLevelDBKV opens the LevelDB database in the given directory path, creating it if it does not exist.
+// The database must be closed with Close to release its lock.
+func NewLevelDBKV(path string) (*LevelDBKV, error) {
+	db, err := leveldb.OpenFile(path, &opt.Options{
+		// Pre-images are written once and read back at most a few times, so favour fewer, larger files.
+		CompactionTableSize: 8 * opt.MiB,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pre-image database %s: %w", path, err)
+	}
+	return &LevelDBKV{db: db}, nil
+}
+
+func (d *LevelDBKV) Put(k common.Hash, v []byte) error {
+	d.Lock()
+	defer d.Unlock()
+	exists, err := d.db.Has(k[:], nil)
+	if err != nil {
+		return fmt.Errorf("failed to check for pre-image %s: %w", k, err)
+	}
+	if exists {
+		return ErrAlreadyExists
+	}
+	if err := d.db.Put(k[:], v, nil); err != nil {
+		return fmt.Errorf("failed to write pre-image %s: %w", k, err)
+	}
+	return nil
+}
+
+func (d *LevelDBKV) Get(k common.Hash) ([]byte, error) {
+	dat, err := d.db.Get(k[:], nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read pre-image %s: %w", k, err)
+	}
+	return dat, nil
+}
+
+func (d *LevelDBKV) Close() error {
+	return d.db.Close()
+}
+
+var _ KV = (*LevelDBKV)(nil)
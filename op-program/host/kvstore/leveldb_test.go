@@ -0,0 +1,34 @@
+package kvstore
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelDBKV(t *testing.T) {
+	tmp := t.TempDir() // automatically removed by testing cleanup
+	kv, err := NewLevelDBKV(tmp)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, kv.Close())
+	})
+	kvTest(t, kv)
+}
+
+func TestLevelDBKVReopen(t *testing.T) {
+	tmp := t.TempDir()
+	kv, err := NewLevelDBKV(tmp)
+	require.NoError(t, err)
+	require.NoError(t, kv.Put(common.Hash{0xaa}, []byte("hello world")))
+	require.NoError(t, kv.Close())
+
+	kv, err = NewLevelDBKV(tmp)
+	require.NoError(t, err)
+	defer kv.Close()
+	dat, err := kv.Get(common.Hash{0xaa})
+	require.NoError(t, err, "pre-image must persist")
+	require.Equal(t, "hello world", string(dat))
+	require.ErrorIs(t, kv.Put(common.Hash{0xaa}, []byte("hello world")), ErrAlreadyExists)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 
 	"github.com/ethereum-optimism/optimism/op-program/client"
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
 	"github.com/ethereum-optimism/optimism/op-program/host/config"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -24,6 +25,8 @@ var (
 	l2ClaimBlockNumberKey = client.L2ClaimBlockNumberLocalIndex.PreimageKey()
 	l2ChainConfigKey      = client.L2ChainConfigLocalIndex.PreimageKey()
 	rollupKey             = client.RollupConfigLocalIndex.PreimageKey()
+	l2ClaimsKey           = client.L2ClaimsLocalIndex.PreimageKey()
+	checkpointKey         = client.CheckpointLocalIndex.PreimageKey()
 )
 
 func (s *LocalPreimageSource) Get(key common.Hash) ([]byte, error) {
@@ -40,6 +43,10 @@ func (s *LocalPreimageSource) Get(key common.Hash) ([]byte, error) {
 		return json.Marshal(s.config.L2ChainConfig)
 	case rollupKey:
 		return json.Marshal(s.config.Rollup)
+	case l2ClaimsKey:
+		return driver.EncodeClaims(s.config.L2Claims), nil
+	case checkpointKey:
+		return driver.EncodeCheckpoint(s.config.L2Checkpoint), nil
 	default:
 		return nil, ErrNotFound
 	}
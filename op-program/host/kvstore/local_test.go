@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
 	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
 	"github.com/ethereum-optimism/optimism/op-program/host/config"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params"
@@ -20,6 +22,8 @@ func TestLocalPreimageSource(t *testing.T) {
 		L2Head:             common.HexToHash("0x2222"),
 		L2Claim:            common.HexToHash("0x3333"),
 		L2ClaimBlockNumber: 1234,
+		L2Claims:           []driver.Claim{{BlockNumber: 1000, OutputRoot: eth.Bytes32{0x44}}},
+		L2Checkpoint:       &driver.Checkpoint{SafeHead: eth.BlockID{Hash: common.HexToHash("0x2222"), Number: 900}},
 		L2ChainConfig:      params.GoerliChainConfig,
 	}
 	source := NewLocalPreimageSource(cfg)
@@ -34,6 +38,8 @@ func TestLocalPreimageSource(t *testing.T) {
 		{"L2ClaimBlockNumber", l2ClaimBlockNumberKey, binary.BigEndian.AppendUint64(nil, cfg.L2ClaimBlockNumber)},
 		{"Rollup", rollupKey, asJson(t, cfg.Rollup)},
 		{"ChainConfig", l2ChainConfigKey, asJson(t, cfg.L2ChainConfig)},
+		{"L2Claims", l2ClaimsKey, driver.EncodeClaims(cfg.L2Claims)},
+		{"Checkpoint", checkpointKey, driver.EncodeCheckpoint(cfg.L2Checkpoint)},
 		{"Unknown", preimage.LocalIndexKey(1000).PreimageKey(), nil},
 	}
 	for _, test := range tests {
@@ -0,0 +1,51 @@
+package kvstore
+
+import (
+	"errors"
+	"fmt"
+
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrCorruptPreimage = errors.New("corrupt pre-image")
+
+// Migrate copies all pre-images of the src directory into dst, returning the number of pre-images copied.
+// Pre-images already in dst are skipped, so an interrupted migration can be resumed.
+// Keccak256 pre-images are verified against their key, the migration stops at the first corrupt pre-image.
+func Migrate(src *DiskKV, dst KV) (int, error) {
+	keys, err := src.Keys()
+	if err != nil {
+		return 0, err
+	}
+	copied := 0
+	for _, k := range keys {
+		v, err := src.Get(k)
+		if err != nil {
+			return copied, fmt.Errorf("failed to read pre-image %s: %w", k, err)
+		}
+		if err := verifyPreimage(k, v); err != nil {
+			return copied, err
+		}
+		if err := dst.Put(k, v); errors.Is(err, ErrAlreadyExists) {
+			continue
+		} else if err != nil {
+			return copied, err
+		}
+		copied++
+	}
+	return copied, nil
+}
+
+// verifyPreimage checks that a keccak256 pre-image hashes to its key.
+// Other key types can't be verified from the value alone.
+func verifyPreimage(k common.Hash, v []byte) error {
+	if preimage.KeyType(k[0]) != preimage.Keccak256KeyType {
+		return nil
+	}
+	if actual := preimage.Keccak256Key(crypto.Keccak256Hash(v)).PreimageKey(); actual != k {
+		return fmt.Errorf("%w: %s holds the pre-image of %s", ErrCorruptPreimage, k, common.Hash(actual))
+	}
+	return nil
+}
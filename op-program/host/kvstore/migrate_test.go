@@ -0,0 +1,63 @@
+package kvstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	srcDir := t.TempDir()
+	src := NewDiskKV(srcDir)
+	preimages := map[common.Hash][]byte{
+		{0xaa}: []byte("hello world"),
+		{0xbb}: {},
+		{}:     {4, 2},
+		preimage.Keccak256Key(crypto.Keccak256Hash([]byte("abc"))).PreimageKey(): []byte("abc"),
+	}
+	for k, v := range preimages {
+		require.NoError(t, src.Put(k, v))
+	}
+	// unrelated files in the datadir are not pre-images
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "notes.txt"), []byte("abcd"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(srcDir, common.Hash{0xcc}.String()+".txt"), 0755))
+
+	keys, err := src.Keys()
+	require.NoError(t, err)
+	require.Len(t, keys, len(preimages))
+
+	dst, err := NewLevelDBKV(t.TempDir())
+	require.NoError(t, err)
+	defer dst.Close()
+	require.NoError(t, dst.Put(common.Hash{0xaa}, []byte("hello world")))
+
+	copied, err := Migrate(src, dst)
+	require.NoError(t, err)
+	require.Equal(t, len(preimages)-1, copied, "existing pre-images are skipped")
+	for k, v := range preimages {
+		dat, err := dst.Get(k)
+		require.NoError(t, err)
+		require.Equal(t, v, dat)
+	}
+
+	copied, err = Migrate(src, dst)
+	require.NoError(t, err)
+	require.Zero(t, copied, "migration is idempotent")
+}
+
+func TestMigrateCorruptPreimage(t *testing.T) {
+	src := NewDiskKV(t.TempDir())
+	key := preimage.Keccak256Key(crypto.Keccak256Hash([]byte("abc"))).PreimageKey()
+	require.NoError(t, src.Put(key, []byte("abd")))
+	dst := NewMemKV()
+	copied, err := Migrate(src, dst)
+	require.ErrorIs(t, err, ErrCorruptPreimage)
+	require.Zero(t, copied)
+	_, err = dst.Get(key)
+	require.ErrorIs(t, err, ErrNotFound, "corrupt pre-images are not migrated")
+}
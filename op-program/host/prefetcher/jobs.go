@@ -0,0 +1,50 @@
+package prefetcher
+
+import (
+	"context"
+)
+
+// hintJob is the background prefetching of a hint.
+type hintJob struct {
+	done chan struct{}
+	err  error
+}
+
+// wait blocks until the job completes and returns its error.
+func (j *hintJob) wait(ctx context.Context) error {
+	select {
+	case <-j.done:
+		return j.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startJob starts prefetching the hint in the background, unless it is already being prefetched.
+// It returns the job prefetching the hint. The caller must hold p.mu.
+func (p *Prefetcher) startJob(hint string) *hintJob {
+	if job, ok := p.jobs[hint]; ok {
+		return job
+	}
+	job := &hintJob{done: make(chan struct{})}
+	p.jobs[hint] = job
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		var err error
+		select {
+		case p.workers <- struct{}{}:
+			err = p.prefetch(p.ctx, hint)
+			<-p.workers
+		case <-p.ctx.Done():
+			err = p.ctx.Err()
+		}
+		p.mu.Lock()
+		// The pre-images are in the KV store now, or the hint has to be prefetched again.
+		delete(p.jobs, hint)
+		p.mu.Unlock()
+		job.err = err
+		close(job.done)
+	}()
+	return job
+}
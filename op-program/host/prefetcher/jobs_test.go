@@ -0,0 +1,43 @@
+package prefetcher
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+	"github.com/ethereum-optimism/optimism/op-program/client/l1"
+	"github.com/ethereum-optimism/optimism/op-program/host/kvstore"
+)
+
+func TestParallelFetchL1Block(t *testing.T) {
+	rng := rand.New(rand.NewSource(123))
+	block, receipts := testutils.RandomBlock(rng, 10)
+	hash := block.Hash()
+
+	logger := testlog.Logger(t, log.LvlDebug)
+	l1Cl := new(testutils.MockL1Source)
+	l2Cl := &l2Client{
+		MockL2Client:    new(testutils.MockL2Client),
+		MockDebugClient: new(testutils.MockDebugClient),
+	}
+	prefetcher := NewParallelPrefetcher(logger, l1Cl, l2Cl, kvstore.NewMemKV(), 4)
+	// The transactions and receipts are only fetched once, when prefetching the header
+	l1Cl.ExpectInfoByHash(hash, eth.BlockToInfo(block), nil)
+	l1Cl.ExpectInfoAndTxsByHash(hash, eth.BlockToInfo(block), block.Transactions(), nil)
+	l1Cl.ExpectFetchReceipts(hash, eth.BlockToInfo(block), receipts, nil)
+
+	oracle := l1.NewPreimageOracle(asOracleFn(t, prefetcher), asHinter(t, prefetcher))
+	header, txs := oracle.TransactionsByBlockHash(hash)
+	require.EqualValues(t, hash, header.Hash())
+	assertTransactionsEqual(t, block.Transactions(), txs)
+	_, actualReceipts := oracle.ReceiptsByBlockHash(hash)
+	assertReceiptsEqual(t, receipts, actualReceipts)
+
+	require.NoError(t, prefetcher.Close())
+	l1Cl.AssertExpectations(t)
+}
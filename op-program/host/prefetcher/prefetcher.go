@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	preimage "github.com/ethereum-optimism/optimism/op-preimage"
@@ -35,21 +36,56 @@ type Prefetcher struct {
 	logger    log.Logger
 	l1Fetcher L1Source
 	l2Fetcher L2Source
-	lastHint  string
-	kvStore   kvstore.KV
+	// l2State is used to prefetch the state of whole L2 blocks, nil if the L2 source does not support it.
+	l2State L2StateSource
+	kvStore kvstore.KV
+
+	mu       sync.Mutex
+	lastHint string
+	// workers limits the number of hints prefetched concurrently, nil if hints are prefetched synchronously.
+	workers chan struct{}
+	// jobs are the hints being prefetched in the background
+	jobs   map[string]*hintJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
+// NewPrefetcher creates a Prefetcher that fetches the pre-images of the last hint when a pre-image is missing.
 func NewPrefetcher(logger log.Logger, l1Fetcher L1Source, l2Fetcher L2Source, kvStore kvstore.KV) *Prefetcher {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Prefetcher{
 		logger:    logger,
 		l1Fetcher: NewRetryingL1Source(logger, l1Fetcher),
 		l2Fetcher: NewRetryingL2Source(logger, l2Fetcher),
 		kvStore:   kvStore,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// NewParallelPrefetcher creates a Prefetcher that prefetches in the background, with up to workers hints at a time.
+// Pre-images that are likely to be requested next are prefetched ahead of their hint:
+// the transactions and receipts of L1 blocks are prefetched with their header,
+// and if the L2 source implements L2StateSource, the state of L2 blocks can be prefetched with PrefetchL2Blocks.
+// A missing pre-image waits for background work already fetching its hint.
+// The Prefetcher must be closed to stop the background work.
+func NewParallelPrefetcher(logger log.Logger, l1Fetcher L1Source, l2Fetcher L2Source, kvStore kvstore.KV, workers int) *Prefetcher {
+	p := NewPrefetcher(logger, l1Fetcher, l2Fetcher, kvStore)
+	if workers > 0 {
+		p.workers = make(chan struct{}, workers)
+		p.jobs = make(map[string]*hintJob)
+	}
+	if l2State, ok := l2Fetcher.(L2StateSource); ok {
+		p.l2State = l2State
 	}
+	return p
 }
 
 func (p *Prefetcher) Hint(hint string) error {
 	p.logger.Trace("Received hint", "hint", hint)
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.lastHint = hint
 	return nil
 }
@@ -57,16 +93,38 @@ func (p *Prefetcher) Hint(hint string) error {
 func (p *Prefetcher) GetPreimage(ctx context.Context, key common.Hash) ([]byte, error) {
 	p.logger.Trace("Pre-image requested", "key", key)
 	pre, err := p.kvStore.Get(key)
-	if errors.Is(err, kvstore.ErrNotFound) && p.lastHint != "" {
-		hint := p.lastHint
-		p.lastHint = ""
+	if !errors.Is(err, kvstore.ErrNotFound) {
+		return pre, err
+	}
+	p.mu.Lock()
+	hint := p.lastHint
+	p.lastHint = ""
+	var job *hintJob
+	if hint != "" && p.workers != nil {
+		job = p.startJob(hint)
+	}
+	p.mu.Unlock()
+	if hint == "" {
+		return pre, err
+	}
+	if p.workers == nil {
 		if err := p.prefetch(ctx, hint); err != nil {
 			return nil, fmt.Errorf("prefetch failed: %w", err)
 		}
-		// Should now be available
-		return p.kvStore.Get(key)
+	} else if job != nil {
+		if err := job.wait(ctx); err != nil {
+			return nil, fmt.Errorf("prefetch failed: %w", err)
+		}
 	}
-	return pre, err
+	// Should now be available
+	return p.kvStore.Get(key)
+}
+
+// Close stops all background prefetching and waits for it to complete.
+func (p *Prefetcher) Close() error {
+	p.cancel()
+	p.wg.Wait()
+	return nil
 }
 
 func (p *Prefetcher) prefetch(ctx context.Context, hint string) error {
@@ -76,7 +134,21 @@ func (p *Prefetcher) prefetch(ctx context.Context, hint string) error {
 	}
 	p.logger.Debug("Prefetching", "type", hintType, "hash", hash)
 	switch hintType {
+	case l1.HintL1BlockHeader, l2.HintL2BlockHeader, l2.HintL2StateNode, l2.HintL2Code:
+		// The pre-image of these hints is keyed by the hash, so it may have been fetched already.
+		if _, err := p.kvStore.Get(preimage.Keccak256Key(hash).PreimageKey()); err == nil {
+			return nil
+		}
+	}
+	switch hintType {
 	case l1.HintL1BlockHeader:
+		if p.workers != nil {
+			// The derivation pipeline reads the transactions and receipts of most L1 blocks it reads the header of.
+			p.mu.Lock()
+			p.startJob(l1.TransactionsHint(hash).Hint())
+			p.startJob(l1.ReceiptsHint(hash).Hint())
+			p.mu.Unlock()
+		}
 		header, err := p.l1Fetcher.InfoByHash(ctx, hash)
 		if err != nil {
 			return fmt.Errorf("failed to fetch L1 block %s header: %w", hash, err)
@@ -85,7 +157,7 @@ func (p *Prefetcher) prefetch(ctx context.Context, hint string) error {
 		if err != nil {
 			return fmt.Errorf("marshall header: %w", err)
 		}
-		return p.kvStore.Put(preimage.Keccak256Key(hash).PreimageKey(), data)
+		return p.put(preimage.Keccak256Key(hash).PreimageKey(), data)
 	case l1.HintL1Transactions:
 		_, txs, err := p.l1Fetcher.InfoAndTxsByHash(ctx, hash)
 		if err != nil {
@@ -107,27 +179,35 @@ func (p *Prefetcher) prefetch(ctx context.Context, hint string) error {
 		if err != nil {
 			return fmt.Errorf("failed to encode header to RLP: %w", err)
 		}
-		err = p.kvStore.Put(preimage.Keccak256Key(hash).PreimageKey(), data)
-		if err != nil {
+		// Store the transactions first, the header is checked for to skip fetching the block again.
+		if err := p.storeTransactions(txs); err != nil {
 			return err
 		}
-		return p.storeTransactions(txs)
+		return p.put(preimage.Keccak256Key(hash).PreimageKey(), data)
 	case l2.HintL2StateNode:
 		node, err := p.l2Fetcher.NodeByHash(ctx, hash)
 		if err != nil {
 			return fmt.Errorf("failed to fetch L2 state node %s: %w", hash, err)
 		}
-		return p.kvStore.Put(preimage.Keccak256Key(hash).PreimageKey(), node)
+		return p.put(preimage.Keccak256Key(hash).PreimageKey(), node)
 	case l2.HintL2Code:
 		code, err := p.l2Fetcher.CodeByHash(ctx, hash)
 		if err != nil {
 			return fmt.Errorf("failed to fetch L2 contract code %s: %w", hash, err)
 		}
-		return p.kvStore.Put(preimage.Keccak256Key(hash).PreimageKey(), code)
+		return p.put(preimage.Keccak256Key(hash).PreimageKey(), code)
 	}
 	return fmt.Errorf("unknown hint type: %v", hintType)
 }
 
+// put stores a pre-image, which may have been stored already by concurrent prefetching.
+func (p *Prefetcher) put(key common.Hash, value []byte) error {
+	if err := p.kvStore.Put(key, value); err != nil && !errors.Is(err, kvstore.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
 func (p *Prefetcher) storeReceipts(receipts types.Receipts) error {
 	opaqueReceipts, err := eth.EncodeReceipts(receipts)
 	if err != nil {
@@ -147,11 +227,9 @@ func (p *Prefetcher) storeTransactions(txs types.Transactions) error {
 func (p *Prefetcher) storeTrieNodes(values []hexutil.Bytes) error {
 	_, nodes := mpt.WriteTrie(values)
 	for _, node := range nodes {
+		// It's not uncommon for different tries to contain common nodes (esp for receipts)
 		key := preimage.Keccak256Key(crypto.Keccak256Hash(node)).PreimageKey()
-		if err := p.kvStore.Put(key, node); errors.Is(err, kvstore.ErrAlreadyExists) {
-			// It's not uncommon for different tries to contain common nodes (esp for receipts)
-			continue
-		} else if err != nil {
+		if err := p.put(key, node); err != nil {
 			return fmt.Errorf("failed to store node: %w", err)
 		}
 	}
@@ -0,0 +1,135 @@
+package prefetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/sources"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// proofBatchSize is the number of accounts to fetch the proofs of in a single batch request.
+const proofBatchSize = 32
+
+// L2StateSource provides the state accessed by L2 blocks, to prefetch the state of whole blocks.
+type L2StateSource interface {
+	InfoByHash(ctx context.Context, hash common.Hash) (eth.BlockInfo, error)
+	InfoByNumber(ctx context.Context, number uint64) (eth.BlockInfo, error)
+	BlockPrestate(ctx context.Context, blockHash common.Hash) (map[common.Address]sources.PrestateAccount, error)
+	GetProofs(ctx context.Context, requests []sources.ProofRequest, blockTag string) ([]*eth.AccountResult, error)
+}
+
+// PrefetchL2Blocks prefetches in the background the state accessed by the L2 blocks after l2Head,
+// up to and including block number end, as the blocks are reported by the L2 source.
+// For every block, the state nodes on the paths to the accessed accounts and storage slots are stored,
+// as of the parent block, along with the code of the accessed accounts.
+// A block derived by the client program executes the same transactions if the L2 source is honest,
+// so its state reads are served from the KV store instead of fetching state nodes one at a time.
+// Prefetching is best-effort and stops at the first failure: the client program still hints every pre-image it needs.
+// It does nothing unless the Prefetcher was created with workers and an L2 source that implements L2StateSource.
+func (p *Prefetcher) PrefetchL2Blocks(l2Head common.Hash, end uint64) {
+	if p.workers == nil || p.l2State == nil {
+		return
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := p.prefetchL2Blocks(p.ctx, l2Head, end); err != nil && !errors.Is(err, context.Canceled) {
+			p.logger.Warn("Stopped prefetching L2 block state", "err", err)
+		}
+	}()
+}
+
+func (p *Prefetcher) prefetchL2Blocks(ctx context.Context, l2Head common.Hash, end uint64) error {
+	parent, err := p.l2State.InfoByHash(ctx, l2Head)
+	if err != nil {
+		return fmt.Errorf("failed to fetch L2 head %s: %w", l2Head, err)
+	}
+	for num := parent.NumberU64() + 1; num <= end; num++ {
+		block, err := p.l2State.InfoByNumber(ctx, num)
+		if err != nil {
+			return fmt.Errorf("failed to fetch L2 block %d: %w", num, err)
+		}
+		if block.ParentHash() != parent.Hash() {
+			return fmt.Errorf("L2 block %d does not build on %s", num, parent.Hash())
+		}
+		if err := p.prefetchL2BlockState(ctx, block, parent); err != nil {
+			return fmt.Errorf("failed to prefetch state of L2 block %d: %w", num, err)
+		}
+		parent = block
+	}
+	p.logger.Info("Prefetched L2 block state", "head", l2Head, "end", end)
+	return nil
+}
+
+func (p *Prefetcher) prefetchL2BlockState(ctx context.Context, block eth.BlockInfo, parent eth.BlockInfo) error {
+	accounts, err := p.l2State.BlockPrestate(ctx, block.Hash())
+	if err != nil {
+		return err
+	}
+	requests := make([]sources.ProofRequest, 0, len(accounts))
+	for addr, acc := range accounts {
+		if len(acc.Code) > 0 {
+			if err := p.put(preimage.Keccak256Key(crypto.Keccak256Hash(acc.Code)).PreimageKey(), acc.Code); err != nil {
+				return fmt.Errorf("failed to store code of %s: %w", addr, err)
+			}
+		}
+		req := sources.ProofRequest{Address: addr}
+		for slot := range acc.Storage {
+			req.Storage = append(req.Storage, slot)
+		}
+		requests = append(requests, req)
+	}
+	for start := 0; start < len(requests); start += proofBatchSize {
+		end := start + proofBatchSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+		if err := p.prefetchProofs(ctx, requests[start:end], parent.Hash()); err != nil {
+			return err
+		}
+	}
+	p.logger.Debug("Prefetched L2 block state", "block", block.NumberU64(), "accounts", len(accounts))
+	return nil
+}
+
+// prefetchProofs fetches a batch of account proofs with a worker and stores the state nodes in them.
+func (p *Prefetcher) prefetchProofs(ctx context.Context, requests []sources.ProofRequest, blockHash common.Hash) error {
+	select {
+	case p.workers <- struct{}{}:
+		defer func() { <-p.workers }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	results, err := p.l2State.GetProofs(ctx, requests, blockHash.String())
+	if err != nil {
+		return err
+	}
+	for _, res := range results {
+		if err := p.storeProofNodes(res.AccountProof); err != nil {
+			return err
+		}
+		for _, entry := range res.StorageProof {
+			if err := p.storeProofNodes(entry.Proof); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// storeProofNodes stores the nodes of a proof, every node is keyed by its hash.
+func (p *Prefetcher) storeProofNodes(nodes []hexutil.Bytes) error {
+	for _, node := range nodes {
+		key := preimage.Keccak256Key(crypto.Keccak256Hash(node)).PreimageKey()
+		if err := p.put(key, node); err != nil {
+			return fmt.Errorf("failed to store proof node: %w", err)
+		}
+	}
+	return nil
+}
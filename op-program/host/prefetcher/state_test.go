@@ -0,0 +1,110 @@
+package prefetcher
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/sources"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum-optimism/optimism/op-program/host/kvstore"
+)
+
+type stubL2StateSource struct {
+	*l2Client
+	blocks    []eth.BlockInfo
+	prestates map[common.Hash]map[common.Address]sources.PrestateAccount
+	// proofs are the proof nodes of every account, by block tag
+	proofs map[string]map[common.Address][]hexutil.Bytes
+}
+
+func (s *stubL2StateSource) InfoByHash(_ context.Context, hash common.Hash) (eth.BlockInfo, error) {
+	for _, b := range s.blocks {
+		if b.Hash() == hash {
+			return b, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *stubL2StateSource) InfoByNumber(_ context.Context, number uint64) (eth.BlockInfo, error) {
+	for _, b := range s.blocks {
+		if b.NumberU64() == number {
+			return b, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *stubL2StateSource) BlockPrestate(_ context.Context, blockHash common.Hash) (map[common.Address]sources.PrestateAccount, error) {
+	return s.prestates[blockHash], nil
+}
+
+func (s *stubL2StateSource) GetProofs(_ context.Context, requests []sources.ProofRequest, blockTag string) ([]*eth.AccountResult, error) {
+	var results []*eth.AccountResult
+	for _, req := range requests {
+		res := &eth.AccountResult{Address: req.Address, AccountProof: s.proofs[blockTag][req.Address]}
+		for _, slot := range req.Storage {
+			res.StorageProof = append(res.StorageProof, eth.StorageProofEntry{Key: slot, Proof: []hexutil.Bytes{slot[:]}})
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func TestPrefetchL2Blocks(t *testing.T) {
+	rng := rand.New(rand.NewSource(123))
+	head := testutils.RandomBlockInfo(rng)
+	child := testutils.RandomBlockInfo(rng)
+	child.InfoNum = head.InfoNum + 1
+	child.InfoParentHash = head.Hash()
+	unrelated := testutils.RandomBlockInfo(rng)
+	unrelated.InfoNum = child.InfoNum + 1
+
+	addr := testutils.RandomAddress(rng)
+	code := []byte{0x60, 0x00}
+	slot := testutils.RandomHash(rng)
+	accountProof := []hexutil.Bytes{{1, 2, 3}, {4, 5, 6}}
+	src := &stubL2StateSource{
+		blocks: []eth.BlockInfo{head, child, unrelated},
+		prestates: map[common.Hash]map[common.Address]sources.PrestateAccount{
+			child.Hash(): {addr: {Code: code, Storage: map[common.Hash]common.Hash{slot: {0x01}}}},
+		},
+		proofs: map[string]map[common.Address][]hexutil.Bytes{
+			head.Hash().String(): {addr: accountProof},
+		},
+	}
+
+	kv := kvstore.NewMemKV()
+	logger := testlog.Logger(t, log.LvlDebug)
+	prefetcher := NewParallelPrefetcher(logger, new(testutils.MockL1Source), src, kv, 2)
+	// The block after the child does not build on it, so prefetching stops there
+	prefetcher.PrefetchL2Blocks(head.Hash(), unrelated.InfoNum)
+	prefetcher.wg.Wait()
+	require.NoError(t, prefetcher.Close())
+
+	expected := [][]byte{code, accountProof[0], accountProof[1], slot[:]}
+	for _, value := range expected {
+		actual, err := kv.Get(preimage.Keccak256Key(crypto.Keccak256Hash(value)).PreimageKey())
+		require.NoError(t, err)
+		require.Equal(t, value, actual)
+	}
+}
+
+func TestPrefetchL2BlocksRequiresStateSource(t *testing.T) {
+	_, _, l2Cl, _ := createPrefetcher(t)
+	prefetcher := NewParallelPrefetcher(testlog.Logger(t, log.LvlDebug), new(testutils.MockL1Source), l2Cl, kvstore.NewMemKV(), 2)
+	// The mock would fail on any call
+	prefetcher.PrefetchL2Blocks(common.Hash{0xaa}, 10)
+	require.NoError(t, prefetcher.Close())
+}
@@ -0,0 +1,152 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	cl "github.com/ethereum-optimism/optimism/op-program/client"
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
+	"github.com/ethereum-optimism/optimism/op-program/host/trace"
+	oppio "github.com/ethereum-optimism/optimism/op-program/io"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	ErrReplayKeyMismatch     = errors.New("requested pre-image key does not match trace")
+	ErrReplayMissingRecord   = errors.New("trace has no record for requested pre-image")
+	ErrReplayLeftoverRecords = errors.New("client completed with unused trace records")
+)
+
+// ReplayProgram runs the client program against the pre-images recorded in the trace read from r.
+// Pre-images are served strictly in trace order, the same way the zkWasm client consumes its input,
+// so the run fails if the client requests a different key than recorded, requests more pre-images than recorded,
+// or completes without consuming every record.
+// If execCmd is set the client is run as a separate process, otherwise in-process.
+func ReplayProgram(ctx context.Context, logger log.Logger, r io.Reader, execCmd string) error {
+	tr, err := trace.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to open trace: %w", err)
+	}
+	h := tr.Header()
+	logger.Info("Replaying pre-image trace", "l1Head", h.L1Head, "l2Head", h.L2Head, "l2Claim", h.L2Claim, "l2ClaimBlockNumber", h.L2ClaimBlockNumber)
+	return replay(ctx, logger, tr, func(pClientRW oppio.FileChannel, hClientRW oppio.FileChannel) error {
+		if execCmd != "" {
+			return runClientCmd(ctx, logger, execCmd, pClientRW, hClientRW)
+		}
+		return runClientInProcess(logger, pClientRW, hClientRW)
+	})
+}
+
+// runClientInProcess runs the client program in the current process.
+// The oracle client panics when the host stops serving pre-images, which is turned into an error here.
+func runClientInProcess(logger log.Logger, pClientRW oppio.FileChannel, hClientRW oppio.FileChannel) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("client program panicked: %v", r)
+		}
+	}()
+	_, err = cl.RunProgram(logger, pClientRW, hClientRW)
+	return err
+}
+
+type traceReplayer struct {
+	tr     *trace.Reader
+	header trace.Header
+	served uint64
+}
+
+func (r *traceReplayer) Get(key [32]byte) ([]byte, error) {
+	rec, err := r.tr.Next()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: request %d for key %x", ErrReplayMissingRecord, r.served, key)
+	} else if err != nil {
+		return nil, err
+	}
+	if rec.Key != key {
+		return nil, fmt.Errorf("%w: request %d for key %x, trace has key %x", ErrReplayKeyMismatch, r.served, key, rec.Key)
+	}
+	if err := trace.VerifyRecord(&r.header, rec); err != nil {
+		return nil, fmt.Errorf("invalid trace record %d: %w", r.served, err)
+	}
+	r.served++
+	return rec.Value, nil
+}
+
+// remaining counts the records that were not served.
+func (r *traceReplayer) remaining() (uint64, error) {
+	var count uint64
+	for {
+		_, err := r.tr.Next()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		} else if err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+func replay(ctx context.Context, logger log.Logger, tr *trace.Reader, runClient func(pClientRW oppio.FileChannel, hClientRW oppio.FileChannel) error) error {
+	pClientRW, pHostRW, err := oppio.CreateBidirectionalChannel()
+	if err != nil {
+		return fmt.Errorf("failed to create preimage pipe: %w", err)
+	}
+	hClientRW, hHostRW, err := oppio.CreateBidirectionalChannel()
+	if err != nil {
+		_ = pClientRW.Close()
+		_ = pHostRW.Close()
+		return fmt.Errorf("failed to create hints pipe: %w", err)
+	}
+
+	replayer := &traceReplayer{tr: tr, header: tr.Header()}
+	serverErr := make(chan error, 1)
+	go func() {
+		defer close(serverErr)
+		serverErr <- replayServer(ctx, logger, replayer, pHostRW, hHostRW)
+	}()
+
+	clientErr := runClient(pClientRW, hClientRW)
+	_ = pClientRW.Close()
+	_ = hClientRW.Close()
+	if err := <-serverErr; err != nil {
+		return fmt.Errorf("replay failed after %d pre-images: %w", replayer.served, err)
+	}
+	if clientErr != nil && !errors.Is(clientErr, driver.ErrClaimNotValid) {
+		return fmt.Errorf("client program failed after %d pre-images: %w", replayer.served, clientErr)
+	}
+	remaining, err := replayer.remaining()
+	if err != nil {
+		return fmt.Errorf("failed to read remaining trace records: %w", err)
+	}
+	if remaining > 0 {
+		return fmt.Errorf("%w: served %d, %d left", ErrReplayLeftoverRecords, replayer.served, remaining)
+	}
+	logger.Info("Trace replay complete", "preimages", replayer.served)
+	return clientErr
+}
+
+// replayServer serves pre-image requests from the replayer, ignoring all hints.
+// The supplied preimageChannel and hintChannel will be closed before this function returns.
+func replayServer(ctx context.Context, logger log.Logger, replayer *traceReplayer, preimageChannel oppio.FileChannel, hintChannel oppio.FileChannel) error {
+	serverDone := launchOracleServer(logger, preimageChannel, replayer.Get, nil)
+	hinterDone := routeHints(logger, hintChannel, func(hint string) error {
+		logger.Trace("ignoring hint during replay", "hint", hint)
+		return nil
+	})
+	defer func() {
+		preimageChannel.Close()
+		hintChannel.Close()
+		<-serverDone
+		<-hinterDone
+	}()
+	select {
+	case err := <-serverDone:
+		return err
+	case err := <-hinterDone:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,106 @@
+package host
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum-optimism/optimism/op-program/client"
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
+	"github.com/ethereum-optimism/optimism/op-program/host/config"
+	"github.com/ethereum-optimism/optimism/op-program/host/trace"
+	"github.com/ethereum-optimism/optimism/op-program/io"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+type testHint string
+
+func (h testHint) Hint() string {
+	return string(h)
+}
+
+func TestReplay(t *testing.T) {
+	cfg := config.NewConfig(&chaincfg.Goerli, config.OPGoerliChainConfig, common.Hash{0x11}, common.Hash{0x22}, common.Hash{0x33}, 1000)
+	header, err := trace.NewHeader(cfg)
+	require.NoError(t, err)
+	keys := []preimage.Key{client.L1HeadLocalIndex}
+	records := []trace.Record{{Key: client.L1HeadLocalIndex.PreimageKey(), Value: cfg.L1Head.Bytes()}}
+	for _, v := range [][]byte{[]byte("a"), []byte("bb"), []byte("ccc")} {
+		key := preimage.Keccak256Key(crypto.Keccak256Hash(v))
+		keys = append(keys, key)
+		records = append(records, trace.Record{Key: key.PreimageKey(), Value: v})
+	}
+
+	runReplay := func(t *testing.T, records []trace.Record, client func(io.FileChannel, io.FileChannel) error) error {
+		var buf bytes.Buffer
+		w, err := trace.NewWriter(&buf, header)
+		require.NoError(t, err)
+		for _, rec := range records {
+			require.NoError(t, w.Write(rec.Key, rec.Value))
+		}
+		require.NoError(t, w.Close())
+		tr, err := trace.NewReader(&buf)
+		require.NoError(t, err)
+		logger := testlog.Logger(t, log.LvlTrace)
+		return replay(context.Background(), logger, tr, client)
+	}
+
+	t.Run("Complete", func(t *testing.T) {
+		require.NoError(t, runReplay(t, records, fakeClient(t, keys, nil)))
+	})
+
+	t.Run("ReportClaimResult", func(t *testing.T) {
+		err := runReplay(t, records, fakeClient(t, keys, driver.ErrClaimNotValid))
+		require.ErrorIs(t, err, driver.ErrClaimNotValid)
+	})
+
+	t.Run("KeyMismatch", func(t *testing.T) {
+		reordered := []preimage.Key{keys[0], keys[2], keys[1], keys[3]}
+		err := runReplay(t, records, fakeClient(t, reordered, nil))
+		require.ErrorIs(t, err, ErrReplayKeyMismatch)
+	})
+
+	t.Run("MissingRecord", func(t *testing.T) {
+		err := runReplay(t, records[:3], fakeClient(t, keys, nil))
+		require.ErrorIs(t, err, ErrReplayMissingRecord)
+	})
+
+	t.Run("LeftoverRecords", func(t *testing.T) {
+		err := runReplay(t, records, fakeClient(t, keys[:2], nil))
+		require.ErrorIs(t, err, ErrReplayLeftoverRecords)
+	})
+
+	t.Run("InvalidRecord", func(t *testing.T) {
+		corrupt := append([]trace.Record{}, records...)
+		corrupt[1] = trace.Record{Key: records[1].Key, Value: []byte("wrong")}
+		err := runReplay(t, corrupt, fakeClient(t, keys, nil))
+		require.ErrorContains(t, err, "invalid trace record 1")
+	})
+}
+
+// fakeClient hints and requests the given keys in order, then returns the result.
+// Failed requests end the client like the real oracle client does, with a panic, which is turned into an error.
+func fakeClient(t *testing.T, keys []preimage.Key, result error) func(pClientRW io.FileChannel, hClientRW io.FileChannel) error {
+	return func(pClientRW io.FileChannel, hClientRW io.FileChannel) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Logf("client stopped: %v", r)
+				err = errors.New("client panicked")
+			}
+		}()
+		oracle := preimage.NewOracleClient(pClientRW)
+		hinter := preimage.NewHintWriter(hClientRW)
+		for _, k := range keys {
+			hinter.Hint(testHint("fetch"))
+			oracle.Get(k)
+		}
+		return result
+	}
+}
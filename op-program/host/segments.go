@@ -0,0 +1,182 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
+	"github.com/ethereum-optimism/optimism/op-node/client"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/sources"
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
+	"github.com/ethereum-optimism/optimism/op-program/host/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var ErrSegmentClaimNotValid = errors.New("segment output root not derivable")
+
+// Segment is a self-contained run of the program over part of the L2 blocks of a claim.
+type Segment struct {
+	// L2Head is the agreed L2 block the segment starts from, the checkpoint of the previous segment.
+	L2Head common.Hash
+	// L2Claim is the output root at L2ClaimBlockNumber.
+	// For all but the last segment it is provided by the L2 node and proven by the segment.
+	L2Claim            common.Hash
+	L2ClaimBlockNumber uint64
+	// L2Claims are the additional claims of the run within the blocks of the segment.
+	L2Claims []driver.Claim
+	// Checkpoint is the checkpoint output by the previous segment, booting the client to resume from it.
+	// It is only set by ResumeFrom, planned segments start from the L2 head alone.
+	Checkpoint *driver.Checkpoint
+}
+
+// SegmentSource provides the L2 blocks and withdrawal storage roots to plan segments with.
+type SegmentSource interface {
+	InfoByHash(ctx context.Context, hash common.Hash) (eth.BlockInfo, error)
+	InfoByNumber(ctx context.Context, number uint64) (eth.BlockInfo, error)
+	GetProof(ctx context.Context, address common.Address, storage []common.Hash, blockTag string) (*eth.AccountResult, error)
+}
+
+// PlanSegments splits the run of cfg into segments deriving at most cfg.SegmentBlocks L2 blocks each.
+// The last segment validates the claim of cfg, even if the claim is not after the L2 head,
+// in which case it is the only segment and checks the claim against the output root of the L2 head.
+func PlanSegments(ctx context.Context, src SegmentSource, cfg *config.Config) ([]Segment, error) {
+	if cfg.SegmentBlocks == 0 {
+		return nil, errors.New("segment size must be positive")
+	}
+	head, err := src.InfoByHash(ctx, cfg.L2Head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch L2 head %s: %w", cfg.L2Head, err)
+	}
+	var segments []Segment
+	start := head.Hash()
+	for num := head.NumberU64(); ; {
+		end := num + cfg.SegmentBlocks
+		if end >= cfg.L2ClaimBlockNumber {
+			segments = append(segments, Segment{
+				L2Head:             start,
+				L2Claim:            cfg.L2Claim,
+				L2ClaimBlockNumber: cfg.L2ClaimBlockNumber,
+				L2Claims:           claimsInRange(cfg.L2Claims, num, cfg.L2ClaimBlockNumber),
+			})
+			break
+		}
+		block, err := src.InfoByNumber(ctx, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch L2 block %d: %w", end, err)
+		}
+		proof, err := src.GetProof(ctx, predeploys.L2ToL1MessagePasserAddr, nil, block.Hash().String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch withdrawals storage root at L2 block %d: %w", end, err)
+		}
+		outputRoot, err := rollup.ComputeL2OutputRootV0(block, proof.StorageHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute output root at L2 block %d: %w", end, err)
+		}
+		segments = append(segments, Segment{
+			L2Head:             start,
+			L2Claim:            common.Hash(outputRoot),
+			L2ClaimBlockNumber: end,
+			L2Claims:           claimsInRange(cfg.L2Claims, num, end),
+		})
+		start, num = block.Hash(), end
+	}
+	return segments, nil
+}
+
+// claimsInRange returns the claims after block from, up to and including block to.
+func claimsInRange(claims []driver.Claim, from uint64, to uint64) []driver.Claim {
+	var result []driver.Claim
+	for _, c := range claims {
+		if c.BlockNumber > from && c.BlockNumber <= to {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Config returns the config to run the segment with, derived from the config of the whole run.
+// Per-segment output files get the segment index as suffix.
+func (s Segment) Config(cfg *config.Config, index int) *config.Config {
+	segCfg := *cfg
+	segCfg.SegmentBlocks = 0
+	segCfg.L2Head = s.L2Head
+	segCfg.L2Claim = s.L2Claim
+	segCfg.L2ClaimBlockNumber = s.L2ClaimBlockNumber
+	segCfg.L2Claims = s.L2Claims
+	segCfg.L2Checkpoint = s.Checkpoint
+	if cfg.PreimageTrace != "" {
+		segCfg.PreimageTrace = fmt.Sprintf("%s.%d", cfg.PreimageTrace, index)
+	}
+	if cfg.PreimageFile != "" {
+		segCfg.PreimageFile = fmt.Sprintf("%s.%d", cfg.PreimageFile, index)
+		segCfg.PreimagePublicFile = fmt.Sprintf("%s.%d", cfg.PreimagePublicPath(), index)
+	}
+	return &segCfg
+}
+
+// ResumeFrom returns the segment booted with the checkpoint output by the previous segment.
+// The checkpoint must be at the L2 head the segment was planned to start from.
+func (s Segment) ResumeFrom(checkpoint driver.Checkpoint) (Segment, error) {
+	if checkpoint.SafeHead.Hash != s.L2Head {
+		return Segment{}, fmt.Errorf("checkpoint safe head %s is not the segment L2 head %s", checkpoint.SafeHead, s.L2Head)
+	}
+	s.Checkpoint = &checkpoint
+	return s, nil
+}
+
+// RunSegments runs the fault proof program for every segment of cfg in order.
+// Every segment after the first resumes from the checkpoint output by the client in the previous segment.
+// The claim is only invalid if the last segment rejects it, earlier segments must prove the output roots of the L2 node.
+func RunSegments(ctx context.Context, logger log.Logger, cfg *config.Config) error {
+	src, err := newSegmentSource(ctx, logger, cfg)
+	if err != nil {
+		return err
+	}
+	segments, err := PlanSegments(ctx, src, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to plan segments: %w", err)
+	}
+	logger.Info("Planned segments", "count", len(segments), "blocks", cfg.SegmentBlocks)
+	checkpoint := cfg.L2Checkpoint
+	for i, segment := range segments {
+		if checkpoint != nil {
+			if segment, err = segment.ResumeFrom(*checkpoint); err != nil {
+				return fmt.Errorf("failed to resume segment %d: %w", i, err)
+			}
+		}
+		segCfg := segment.Config(cfg, i)
+		logger.Info("Running segment", "index", i, "l2Head", segment.L2Head, "claim", segment.L2Claim, "block", segment.L2ClaimBlockNumber)
+		checkpoint, err = faultProofProgram(ctx, logger, segCfg)
+		if segCfg.PreimageFile != "" && (err == nil || errors.Is(err, driver.ErrClaimNotValid)) {
+			if err := writeZkWasmInput(segCfg.PreimageTrace, segCfg.PreimageFile, segCfg.PreimagePublicPath(), segCfg.PreimageCompress); err != nil {
+				return err
+			}
+			logger.Info("Wrote zkWasm segment input", "index", i, "private", segCfg.PreimageFile, "public", segCfg.PreimagePublicPath())
+		}
+		last := i == len(segments)-1
+		var claimErr *driver.ClaimError
+		if !last && errors.As(err, &claimErr) && claimErr.Claim.OutputRoot == eth.Bytes32(segment.L2Claim) {
+			// Not a claim of the run, but the output root the L2 node reported for the end of the segment.
+			return fmt.Errorf("%w: segment %d at block %d: %v", ErrSegmentClaimNotValid, i, segment.L2ClaimBlockNumber, err)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newSegmentSource(ctx context.Context, logger log.Logger, cfg *config.Config) (SegmentSource, error) {
+	l2RPC, err := client.NewRPC(ctx, logger, cfg.L2URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup L2 RPC: %w", err)
+	}
+	l2Cl, err := sources.NewL2Client(l2RPC, logger, nil, sources.L2ClientDefaultConfig(cfg.Rollup, true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create L2 client: %w", err)
+	}
+	return l2Cl, nil
+}
@@ -0,0 +1,173 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
+	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testutils"
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
+	"github.com/ethereum-optimism/optimism/op-program/host/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSegmentSource struct {
+	blocks map[uint64]*testutils.MockBlockInfo
+}
+
+func newStubSegmentSource(from, to uint64) *stubSegmentSource {
+	s := &stubSegmentSource{blocks: make(map[uint64]*testutils.MockBlockInfo)}
+	for n := from; n <= to; n++ {
+		s.blocks[n] = &testutils.MockBlockInfo{
+			InfoHash: common.BigToHash(new(big.Int).SetUint64(n)),
+			InfoNum:  n,
+			InfoRoot: common.Hash{byte(n)},
+		}
+	}
+	return s
+}
+
+func (s *stubSegmentSource) InfoByHash(_ context.Context, hash common.Hash) (eth.BlockInfo, error) {
+	for _, b := range s.blocks {
+		if b.Hash() == hash {
+			return b, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *stubSegmentSource) InfoByNumber(_ context.Context, number uint64) (eth.BlockInfo, error) {
+	if b, ok := s.blocks[number]; ok {
+		return b, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *stubSegmentSource) GetProof(_ context.Context, address common.Address, _ []common.Hash, blockTag string) (*eth.AccountResult, error) {
+	if address != predeploys.L2ToL1MessagePasserAddr {
+		return nil, errors.New("unexpected address")
+	}
+	return &eth.AccountResult{StorageHash: common.HexToHash(blockTag)}, nil
+}
+
+func (s *stubSegmentSource) outputRoot(t *testing.T, n uint64) common.Hash {
+	b := s.blocks[n]
+	root, err := rollup.ComputeL2OutputRootV0(b, b.Hash())
+	require.NoError(t, err)
+	return common.Hash(root)
+}
+
+func TestPlanSegments(t *testing.T) {
+	src := newStubSegmentSource(100, 110)
+	cfg := config.NewConfig(&chaincfg.Goerli, params.GoerliChainConfig, common.Hash{0xaa}, src.blocks[100].Hash(), common.Hash{0xcc}, 110)
+
+	t.Run("Split", func(t *testing.T) {
+		cfg := *cfg
+		cfg.SegmentBlocks = 4
+		segments, err := PlanSegments(context.Background(), src, &cfg)
+		require.NoError(t, err)
+		require.Equal(t, []Segment{
+			{L2Head: src.blocks[100].Hash(), L2Claim: src.outputRoot(t, 104), L2ClaimBlockNumber: 104},
+			{L2Head: src.blocks[104].Hash(), L2Claim: src.outputRoot(t, 108), L2ClaimBlockNumber: 108},
+			{L2Head: src.blocks[108].Hash(), L2Claim: cfg.L2Claim, L2ClaimBlockNumber: 110},
+		}, segments)
+	})
+
+	t.Run("AssignClaims", func(t *testing.T) {
+		cfg := *cfg
+		cfg.SegmentBlocks = 4
+		claim := func(n uint64) driver.Claim {
+			return driver.Claim{BlockNumber: n, OutputRoot: eth.Bytes32{byte(n)}}
+		}
+		cfg.L2Claims = []driver.Claim{claim(101), claim(104), claim(105), claim(110)}
+		segments, err := PlanSegments(context.Background(), src, &cfg)
+		require.NoError(t, err)
+		require.Len(t, segments, 3)
+		require.Equal(t, []driver.Claim{claim(101), claim(104)}, segments[0].L2Claims)
+		require.Equal(t, []driver.Claim{claim(105)}, segments[1].L2Claims)
+		require.Equal(t, []driver.Claim{claim(110)}, segments[2].L2Claims)
+	})
+
+	t.Run("SingleSegment", func(t *testing.T) {
+		cfg := *cfg
+		cfg.SegmentBlocks = 10
+		segments, err := PlanSegments(context.Background(), src, &cfg)
+		require.NoError(t, err)
+		require.Equal(t, []Segment{{L2Head: cfg.L2Head, L2Claim: cfg.L2Claim, L2ClaimBlockNumber: 110}}, segments)
+	})
+
+	t.Run("ClaimAtHead", func(t *testing.T) {
+		cfg := *cfg
+		cfg.SegmentBlocks = 4
+		cfg.L2ClaimBlockNumber = 100
+		segments, err := PlanSegments(context.Background(), src, &cfg)
+		require.NoError(t, err)
+		require.Equal(t, []Segment{{L2Head: cfg.L2Head, L2Claim: cfg.L2Claim, L2ClaimBlockNumber: 100}}, segments)
+	})
+
+	t.Run("ClaimBeforeHead", func(t *testing.T) {
+		cfg := *cfg
+		cfg.SegmentBlocks = 4
+		cfg.L2Head = src.blocks[105].Hash()
+		cfg.L2ClaimBlockNumber = 102
+		cfg.L2Claims = []driver.Claim{{BlockNumber: 101}}
+		segments, err := PlanSegments(context.Background(), src, &cfg)
+		require.NoError(t, err)
+		require.Equal(t, []Segment{{L2Head: cfg.L2Head, L2Claim: cfg.L2Claim, L2ClaimBlockNumber: 102}}, segments)
+	})
+
+	t.Run("ZeroSize", func(t *testing.T) {
+		_, err := PlanSegments(context.Background(), src, cfg)
+		require.Error(t, err)
+	})
+
+	t.Run("MissingBlock", func(t *testing.T) {
+		cfg := *cfg
+		cfg.SegmentBlocks = 4
+		delete(src.blocks, 108)
+		_, err := PlanSegments(context.Background(), src, &cfg)
+		require.ErrorContains(t, err, "failed to fetch L2 block 108")
+	})
+}
+
+func TestSegmentConfig(t *testing.T) {
+	cfg := config.NewConfig(&chaincfg.Goerli, params.GoerliChainConfig, common.Hash{0xaa}, common.Hash{0xbb}, common.Hash{0xcc}, 110)
+	cfg.SegmentBlocks = 4
+	cfg.PreimageTrace = "/tmp/trace.bin"
+	cfg.PreimageFile = "/tmp/preimages.bin"
+
+	claims := []driver.Claim{{BlockNumber: 106, OutputRoot: eth.Bytes32{0x66}}}
+	planned := Segment{L2Head: common.Hash{0xdd}, L2Claim: common.Hash{0xee}, L2ClaimBlockNumber: 108, L2Claims: claims}
+	checkpoint := driver.Checkpoint{
+		SafeHead:   eth.BlockID{Hash: common.Hash{0xdd}, Number: 104},
+		L1Origin:   eth.BlockID{Hash: common.Hash{0x11}, Number: 50},
+		OutputRoot: eth.Bytes32{0x22},
+	}
+	segment, err := planned.ResumeFrom(checkpoint)
+	require.NoError(t, err)
+	segCfg := segment.Config(cfg, 1)
+	require.Equal(t, common.Hash{0xdd}, segCfg.L2Head)
+	require.Equal(t, common.Hash{0xee}, segCfg.L2Claim)
+	require.EqualValues(t, 108, segCfg.L2ClaimBlockNumber)
+	require.Equal(t, claims, segCfg.L2Claims)
+	require.Equal(t, &checkpoint, segCfg.L2Checkpoint)
+	require.Zero(t, segCfg.SegmentBlocks)
+	require.Equal(t, "/tmp/trace.bin.1", segCfg.PreimageTrace)
+	require.Equal(t, "/tmp/preimages.bin.1", segCfg.PreimageFile)
+	require.Equal(t, "/tmp/preimages.bin.public.1", segCfg.PreimagePublicPath())
+	require.Equal(t, common.Hash{0xbb}, cfg.L2Head, "should not modify the original config")
+}
+
+func TestSegmentResumeFrom(t *testing.T) {
+	planned := Segment{L2Head: common.Hash{0xdd}, L2Claim: common.Hash{0xee}, L2ClaimBlockNumber: 108}
+	_, err := planned.ResumeFrom(driver.Checkpoint{SafeHead: eth.BlockID{Hash: common.Hash{0xaa}, Number: 104}})
+	require.ErrorContains(t, err, "is not the segment L2 head")
+	require.Nil(t, planned.Checkpoint, "should not modify the planned segment")
+}
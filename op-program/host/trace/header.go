@@ -0,0 +1,34 @@
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
+	"github.com/ethereum-optimism/optimism/op-program/host/config"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// NewHeader creates the trace header for a program run with the given config.
+// The hashes cover the same encoding that is served to the client as local pre-image.
+func NewHeader(cfg *config.Config) (Header, error) {
+	rollupCfg, err := json.Marshal(cfg.Rollup)
+	if err != nil {
+		return Header{}, fmt.Errorf("failed to encode rollup config: %w", err)
+	}
+	chainCfg, err := json.Marshal(cfg.L2ChainConfig)
+	if err != nil {
+		return Header{}, fmt.Errorf("failed to encode l2 chain config: %w", err)
+	}
+	return Header{
+		Version:            Version,
+		L1Head:             cfg.L1Head,
+		L2Head:             cfg.L2Head,
+		L2Claim:            cfg.L2Claim,
+		L2ClaimBlockNumber: cfg.L2ClaimBlockNumber,
+		RollupConfigHash:   crypto.Keccak256Hash(rollupCfg),
+		L2ChainConfigHash:  crypto.Keccak256Hash(chainCfg),
+		L2ClaimsHash:       crypto.Keccak256Hash(driver.EncodeClaims(cfg.L2Claims)),
+		CheckpointHash:     crypto.Keccak256Hash(driver.EncodeCheckpoint(cfg.L2Checkpoint)),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package trace
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Entry locates a single record within a trace file.
+type Entry struct {
+	Key [32]byte
+	// Offset is the position of the record value in the trace file.
+	Offset uint64
+	Length uint64
+}
+
+// Index provides random access to the records of a trace, without holding the values in memory.
+type Index struct {
+	Header  Header
+	Entries []Entry
+	byKey   map[[32]byte]int
+}
+
+// BuildIndex scans the trace read from r and records the location of every record value.
+func BuildIndex(r io.Reader) (*Index, error) {
+	tr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{Header: tr.Header(), byKey: make(map[[32]byte]int)}
+	for {
+		rec, start, err := tr.next(false)
+		if errors.Is(err, io.EOF) {
+			return idx, nil
+		} else if err != nil {
+			return nil, err
+		}
+		// The value follows the 32 byte key and 8 byte length prefix.
+		entry := Entry{Key: rec.Key, Offset: start + 32 + 8, Length: tr.offset - start - 32 - 8}
+		if _, ok := idx.byKey[rec.Key]; !ok {
+			idx.byKey[rec.Key] = len(idx.Entries)
+		}
+		idx.Entries = append(idx.Entries, entry)
+	}
+}
+
+// Lookup returns the position of the first record with the given key.
+func (idx *Index) Lookup(key [32]byte) (int, bool) {
+	i, ok := idx.byKey[key]
+	return i, ok
+}
+
+// Record loads the i-th record of the trace from r.
+func (idx *Index) Record(r io.ReaderAt, i int) (Record, error) {
+	if i < 0 || i >= len(idx.Entries) {
+		return Record{}, fmt.Errorf("record %d out of range, trace has %d records", i, len(idx.Entries))
+	}
+	entry := idx.Entries[i]
+	value := make([]byte, entry.Length)
+	if _, err := r.ReadAt(value, int64(entry.Offset)); err != nil {
+		return Record{}, fmt.Errorf("failed to read record %d at offset %d: %w", i, entry.Offset, err)
+	}
+	return Record{Key: entry.Key, Value: value}, nil
+}
@@ -0,0 +1,303 @@
+package trace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Version is the version of the trace file format written by Writer.
+const Version uint32 = 1
+
+// magic identifies a pre-image trace file.
+var magic = [4]byte{'O', 'P', 'P', 'T'}
+
+// headerSize is the size of the encoded Header, including the magic and version prefix.
+const headerSize = 4 + 4 + 32 + 32 + 32 + 8 + 32 + 32 + 32 + 32
+
+// endMarker is written in place of a record key to terminate the record section.
+// A pre-image key is never zero, as the zero key type is illegal.
+var endMarker [32]byte
+
+var (
+	ErrInvalidMagic       = errors.New("not a pre-image trace file")
+	ErrUnsupportedVersion = errors.New("unsupported trace version")
+	ErrTruncated          = errors.New("trace is truncated")
+	ErrCountMismatch      = errors.New("trace record count mismatch")
+)
+
+// Header describes the program instance that a trace was recorded for.
+type Header struct {
+	Version            uint32
+	L1Head             common.Hash
+	L2Head             common.Hash
+	L2Claim            common.Hash
+	L2ClaimBlockNumber uint64
+	// RollupConfigHash is the keccak256 hash of the JSON encoded rollup config served to the client.
+	RollupConfigHash common.Hash
+	// L2ChainConfigHash is the keccak256 hash of the JSON encoded L2 chain config served to the client.
+	L2ChainConfigHash common.Hash
+	// L2ClaimsHash is the keccak256 hash of the encoded additional L2 claims served to the client.
+	L2ClaimsHash common.Hash
+	// CheckpointHash is the keccak256 hash of the encoded checkpoint served to the client.
+	CheckpointHash common.Hash
+}
+
+func (h *Header) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, headerSize)
+	out = append(out, magic[:]...)
+	out = binary.BigEndian.AppendUint32(out, h.Version)
+	out = append(out, h.L1Head[:]...)
+	out = append(out, h.L2Head[:]...)
+	out = append(out, h.L2Claim[:]...)
+	out = binary.BigEndian.AppendUint64(out, h.L2ClaimBlockNumber)
+	out = append(out, h.RollupConfigHash[:]...)
+	out = append(out, h.L2ChainConfigHash[:]...)
+	out = append(out, h.L2ClaimsHash[:]...)
+	out = append(out, h.CheckpointHash[:]...)
+	return out, nil
+}
+
+func (h *Header) UnmarshalBinary(data []byte) error {
+	if len(data) != headerSize {
+		return fmt.Errorf("%w: header is %d bytes, expected %d", ErrTruncated, len(data), headerSize)
+	}
+	if !bytes.Equal(data[:4], magic[:]) {
+		return ErrInvalidMagic
+	}
+	h.Version = binary.BigEndian.Uint32(data[4:8])
+	if h.Version != Version {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, h.Version)
+	}
+	data = data[8:]
+	copy(h.L1Head[:], data[0:32])
+	copy(h.L2Head[:], data[32:64])
+	copy(h.L2Claim[:], data[64:96])
+	h.L2ClaimBlockNumber = binary.BigEndian.Uint64(data[96:104])
+	copy(h.RollupConfigHash[:], data[104:136])
+	copy(h.L2ChainConfigHash[:], data[136:168])
+	copy(h.L2ClaimsHash[:], data[168:200])
+	copy(h.CheckpointHash[:], data[200:232])
+	return nil
+}
+
+// Record is a single pre-image served to the client, in the order it was requested.
+type Record struct {
+	Key   [32]byte
+	Value []byte
+}
+
+// Type returns the pre-image key type of the record.
+func (r Record) Type() preimage.KeyType {
+	return preimage.KeyType(r.Key[0])
+}
+
+// Writer writes a pre-image trace: a Header, followed by every served pre-image record,
+// terminated by a trailer holding the total number of records.
+// Close must be called to write the trailer, a trace without trailer is considered truncated.
+type Writer struct {
+	w     *bufio.Writer
+	count uint64
+}
+
+var _ preimage.PreimageRecorder = (*Writer)(nil)
+
+// NewWriter writes the header to w and returns a Writer to append records with.
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	header.Version = Version
+	data, err := header.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write trace header: %w", err)
+	}
+	return &Writer{w: bw}, nil
+}
+
+// Write appends a record of the pre-image value of the given key.
+func (t *Writer) Write(key [32]byte, value []byte) error {
+	if key == endMarker {
+		return errors.New("cannot record pre-image with zero key")
+	}
+	if _, err := t.w.Write(key[:]); err != nil {
+		return fmt.Errorf("failed to write record key: %w", err)
+	}
+	if err := binary.Write(t.w, binary.BigEndian, uint64(len(value))); err != nil {
+		return fmt.Errorf("failed to write record length: %w", err)
+	}
+	if _, err := t.w.Write(value); err != nil {
+		return fmt.Errorf("failed to write record value: %w", err)
+	}
+	t.count++
+	return nil
+}
+
+// RecordPreimage implements preimage.PreimageRecorder, to record a trace of the pre-images served by an oracle server.
+func (t *Writer) RecordPreimage(key [32]byte, value []byte) error {
+	return t.Write(key, value)
+}
+
+// Count returns the number of records written so far.
+func (t *Writer) Count() uint64 {
+	return t.count
+}
+
+// Close writes the trailer and flushes any buffered data.
+// It does not close the underlying writer.
+func (t *Writer) Close() error {
+	if _, err := t.w.Write(endMarker[:]); err != nil {
+		return fmt.Errorf("failed to write trace trailer: %w", err)
+	}
+	if err := binary.Write(t.w, binary.BigEndian, t.count); err != nil {
+		return fmt.Errorf("failed to write trace record count: %w", err)
+	}
+	return t.w.Flush()
+}
+
+// Reader reads the records of a trace written by Writer.
+type Reader struct {
+	r      io.Reader
+	header Header
+	// offset is the position in the trace of the next record.
+	offset uint64
+	count  uint64
+	done   bool
+}
+
+// NewReader reads and validates the trace header from r.
+func NewReader(r io.Reader) (*Reader, error) {
+	data := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: %v", ErrTruncated, err)
+		}
+		return nil, fmt.Errorf("failed to read trace header: %w", err)
+	}
+	var header Header
+	if err := header.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return &Reader{r: r, header: header, offset: headerSize}, nil
+}
+
+func (t *Reader) Header() Header {
+	return t.header
+}
+
+// Next returns the next record in the trace.
+// It returns io.EOF after the last record, once the trailer has been read and the record count checked.
+func (t *Reader) Next() (Record, error) {
+	rec, _, err := t.next(true)
+	return rec, err
+}
+
+// next reads the next record, and returns the offset it started at.
+// The value is only loaded if withValue is set, otherwise it is skipped.
+func (t *Reader) next(withValue bool) (Record, uint64, error) {
+	if t.done {
+		return Record{}, 0, io.EOF
+	}
+	start := t.offset
+	var rec Record
+	if err := t.readFull(rec.Key[:]); err != nil {
+		return Record{}, 0, err
+	}
+	var length uint64
+	if err := binary.Read(t.r, binary.BigEndian, &length); err != nil {
+		return Record{}, 0, t.wrapErr(err)
+	}
+	t.offset += 8
+	if rec.Key == endMarker {
+		t.done = true
+		if length != t.count {
+			return Record{}, 0, fmt.Errorf("%w: trailer reports %d records, read %d", ErrCountMismatch, length, t.count)
+		}
+		return Record{}, 0, io.EOF
+	}
+	if withValue {
+		rec.Value = make([]byte, length)
+		if err := t.readFull(rec.Value); err != nil {
+			return Record{}, 0, err
+		}
+	} else {
+		n, err := io.CopyN(io.Discard, t.r, int64(length))
+		t.offset += uint64(n)
+		if err != nil {
+			return Record{}, 0, t.wrapErr(err)
+		}
+	}
+	t.count++
+	return rec, start, nil
+}
+
+func (t *Reader) readFull(dest []byte) error {
+	n, err := io.ReadFull(t.r, dest)
+	t.offset += uint64(n)
+	if err != nil {
+		return t.wrapErr(err)
+	}
+	return nil
+}
+
+func (t *Reader) wrapErr(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: at offset %d after %d records", ErrTruncated, t.offset, t.count)
+	}
+	return fmt.Errorf("failed to read trace record: %w", err)
+}
+
+// ReadAll reads the header and all records of the trace.
+func ReadAll(r io.Reader) (Header, []Record, error) {
+	tr, err := NewReader(r)
+	if err != nil {
+		return Header{}, nil, err
+	}
+	var records []Record
+	for {
+		rec, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return tr.Header(), records, nil
+		} else if err != nil {
+			return Header{}, nil, err
+		}
+		records = append(records, rec)
+	}
+}
+
+// FileWriter is a Writer recording to a file.
+type FileWriter struct {
+	*Writer
+	f *os.File
+}
+
+// CreateFile creates (or truncates) the file at path and writes the trace header to it.
+func CreateFile(path string, header Header) (*FileWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace file: %w", err)
+	}
+	w, err := NewWriter(f, header)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &FileWriter{Writer: w, f: f}, nil
+}
+
+// Close writes the trace trailer and closes the file.
+func (w *FileWriter) Close() error {
+	if err := w.Writer.Close(); err != nil {
+		_ = w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
@@ -0,0 +1,229 @@
+package trace
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/chaincfg"
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum-optimism/optimism/op-program/client"
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
+	"github.com/ethereum-optimism/optimism/op-program/host/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() *config.Config {
+	return config.NewConfig(&chaincfg.Goerli, config.OPGoerliChainConfig, common.Hash{0x11}, common.Hash{0x22}, common.Hash{0x33}, 1000)
+}
+
+func keccakRecord(value []byte) Record {
+	return Record{Key: preimage.Keccak256Key(crypto.Keccak256Hash(value)).PreimageKey(), Value: value}
+}
+
+func writeTrace(t *testing.T, header Header, records ...Record) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, header)
+	require.NoError(t, err)
+	for _, rec := range records {
+		require.NoError(t, w.Write(rec.Key, rec.Value))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	header, err := NewHeader(testConfig())
+	require.NoError(t, err)
+	records := []Record{
+		{Key: client.L1HeadLocalIndex.PreimageKey(), Value: header.L1Head.Bytes()},
+		keccakRecord([]byte("hello")),
+		keccakRecord([]byte{}),
+		keccakRecord(make([]byte, 1000)),
+	}
+	data := writeTrace(t, header, records...)
+
+	actualHeader, actual, err := ReadAll(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, header, actualHeader)
+	require.Len(t, actual, len(records))
+	for i, rec := range records {
+		require.Equal(t, rec.Key, actual[i].Key)
+		require.Equal(t, rec.Value, actual[i].Value)
+	}
+	require.Equal(t, preimage.LocalKeyType, actual[0].Type())
+	require.Equal(t, preimage.Keccak256KeyType, actual[1].Type())
+}
+
+func TestReadErrors(t *testing.T) {
+	header, err := NewHeader(testConfig())
+	require.NoError(t, err)
+	data := writeTrace(t, header, keccakRecord([]byte("a")), keccakRecord([]byte("b")))
+
+	t.Run("InvalidMagic", func(t *testing.T) {
+		invalid := append([]byte{}, data...)
+		invalid[0] = 'X'
+		_, _, err := ReadAll(bytes.NewReader(invalid))
+		require.ErrorIs(t, err, ErrInvalidMagic)
+	})
+
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		invalid := append([]byte{}, data...)
+		binary.BigEndian.PutUint32(invalid[4:8], Version+1)
+		_, _, err := ReadAll(bytes.NewReader(invalid))
+		require.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+
+	t.Run("TruncatedHeader", func(t *testing.T) {
+		_, _, err := ReadAll(bytes.NewReader(data[:headerSize-1]))
+		require.ErrorIs(t, err, ErrTruncated)
+	})
+
+	t.Run("MissingTrailer", func(t *testing.T) {
+		_, _, err := ReadAll(bytes.NewReader(data[:len(data)-40]))
+		require.ErrorIs(t, err, ErrTruncated)
+	})
+
+	t.Run("CountMismatch", func(t *testing.T) {
+		invalid := append([]byte{}, data...)
+		binary.BigEndian.PutUint64(invalid[len(invalid)-8:], 3)
+		_, _, err := ReadAll(bytes.NewReader(invalid))
+		require.ErrorIs(t, err, ErrCountMismatch)
+	})
+}
+
+func TestIndex(t *testing.T) {
+	header, err := NewHeader(testConfig())
+	require.NoError(t, err)
+	records := []Record{keccakRecord([]byte("a")), keccakRecord([]byte("bb")), keccakRecord([]byte("a"))}
+	data := writeTrace(t, header, records...)
+
+	idx, err := BuildIndex(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, header, idx.Header)
+	require.Len(t, idx.Entries, 3)
+
+	i, ok := idx.Lookup(records[1].Key)
+	require.True(t, ok)
+	require.Equal(t, 1, i)
+	i, ok = idx.Lookup(records[2].Key)
+	require.True(t, ok)
+	require.Equal(t, 0, i, "should find first occurrence")
+	_, ok = idx.Lookup(common.Hash{0x02})
+	require.False(t, ok)
+
+	rec, err := idx.Record(bytes.NewReader(data), 1)
+	require.NoError(t, err)
+	require.Equal(t, records[1], rec)
+	_, err = idx.Record(bytes.NewReader(data), 3)
+	require.Error(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	cfg := testConfig()
+	cfg.L2Claims = []driver.Claim{{BlockNumber: 900, OutputRoot: eth.Bytes32{0x44}}}
+	cfg.L2Checkpoint = &driver.Checkpoint{SafeHead: eth.BlockID{Hash: cfg.L2Head, Number: 800}, OutputRoot: eth.Bytes32{0x55}}
+	header, err := NewHeader(cfg)
+	require.NoError(t, err)
+	rollupCfg, err := json.Marshal(cfg.Rollup)
+	require.NoError(t, err)
+	local := func(key preimage.LocalIndexKey, value []byte) Record {
+		return Record{Key: key.PreimageKey(), Value: value}
+	}
+	valid := []Record{
+		local(client.L1HeadLocalIndex, cfg.L1Head.Bytes()),
+		local(client.L2HeadLocalIndex, cfg.L2Head.Bytes()),
+		local(client.L2ClaimLocalIndex, cfg.L2Claim.Bytes()),
+		local(client.L2ClaimBlockNumberLocalIndex, binary.BigEndian.AppendUint64(nil, cfg.L2ClaimBlockNumber)),
+		local(client.RollupConfigLocalIndex, rollupCfg),
+		local(client.L2ClaimsLocalIndex, driver.EncodeClaims(cfg.L2Claims)),
+		local(client.CheckpointLocalIndex, driver.EncodeCheckpoint(cfg.L2Checkpoint)),
+		keccakRecord([]byte("hello")),
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		summary, err := Verify(bytes.NewReader(writeTrace(t, header, valid...)))
+		require.NoError(t, err)
+		require.EqualValues(t, len(valid), summary.Records)
+		require.EqualValues(t, 7, summary.ByType[preimage.LocalKeyType])
+		require.EqualValues(t, 1, summary.ByType[preimage.Keccak256KeyType])
+	})
+
+	invalid := map[string]Record{
+		"WrongKeccakValue":  {Key: keccakRecord([]byte("hello")).Key, Value: []byte("world")},
+		"WrongL1Head":       local(client.L1HeadLocalIndex, common.Hash{0xaa}.Bytes()),
+		"WrongBlockNumber":  local(client.L2ClaimBlockNumberLocalIndex, binary.BigEndian.AppendUint64(nil, 5)),
+		"WrongRollupConfig": local(client.RollupConfigLocalIndex, []byte("{}")),
+		"WrongL2Claims":     local(client.L2ClaimsLocalIndex, nil),
+		"WrongCheckpoint":   local(client.CheckpointLocalIndex, nil),
+		"UnknownLocalKey":   local(preimage.LocalIndexKey(1000), []byte{1}),
+		"UnknownKeyType":    {Key: [32]byte{0xff}, Value: []byte{1}},
+	}
+	for name, rec := range invalid {
+		rec := rec
+		t.Run(name, func(t *testing.T) {
+			records := append(append([]Record{}, valid...), rec)
+			_, err := Verify(bytes.NewReader(writeTrace(t, header, records...)))
+			require.ErrorIs(t, err, ErrInvalidRecord)
+		})
+	}
+}
+
+func TestWriteZkWasmInput(t *testing.T) {
+	header, err := NewHeader(testConfig())
+	require.NoError(t, err)
+	data := writeTrace(t, header,
+		Record{Key: client.L1HeadLocalIndex.PreimageKey(), Value: header.L1Head.Bytes()},
+		keccakRecord([]byte("abc")),
+		keccakRecord(bytes.Repeat([]byte{7}, 8)),
+		Record{Key: client.L2ClaimBlockNumberLocalIndex.PreimageKey(), Value: binary.BigEndian.AppendUint64(nil, header.L2ClaimBlockNumber)},
+		keccakRecord(nil))
+
+	var private, public bytes.Buffer
+	require.NoError(t, WriteZkWasmInput(&private, &public, bytes.NewReader(data)))
+
+	var expected []byte
+	expected = binary.BigEndian.AppendUint64(expected, 3)
+	expected = append(expected, 'a', 'b', 'c', 0, 0, 0, 0, 0)
+	expected = binary.BigEndian.AppendUint64(expected, 8)
+	expected = append(expected, bytes.Repeat([]byte{7}, 8)...)
+	expected = binary.BigEndian.AppendUint64(expected, 0)
+	require.Equal(t, expected, private.Bytes())
+	require.Zero(t, private.Len()%8)
+
+	var expectedPublic []byte
+	expectedPublic = binary.BigEndian.AppendUint64(expectedPublic, 32)
+	expectedPublic = append(expectedPublic, header.L1Head.Bytes()...)
+	expectedPublic = binary.BigEndian.AppendUint64(expectedPublic, 8)
+	expectedPublic = binary.BigEndian.AppendUint64(expectedPublic, header.L2ClaimBlockNumber)
+	require.Equal(t, expectedPublic, public.Bytes(), "local boot values should be public inputs, in trace order")
+
+	t.Run("Compressed", func(t *testing.T) {
+		var privateGz, publicGz bytes.Buffer
+		privateRec, publicRec := preimage.NewCompressedRecorder(&privateGz), preimage.NewCompressedRecorder(&publicGz)
+		require.NoError(t, RecordZkWasmInput(privateRec, publicRec, bytes.NewReader(data)))
+		require.NoError(t, privateRec.Close())
+		require.NoError(t, publicRec.Close())
+		for _, c := range []struct {
+			compressed *bytes.Buffer
+			expected   []byte
+		}{{&privateGz, expected}, {&publicGz, expectedPublic}} {
+			gz, err := gzip.NewReader(c.compressed)
+			require.NoError(t, err)
+			decompressed, err := io.ReadAll(gz)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, decompressed)
+		}
+	})
+
+	t.Run("RejectTruncated", func(t *testing.T) {
+		err := WriteZkWasmInput(io.Discard, io.Discard, bytes.NewReader(data[:len(data)-1]))
+		require.ErrorIs(t, err, ErrTruncated)
+	})
+}
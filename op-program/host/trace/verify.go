@@ -0,0 +1,103 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum-optimism/optimism/op-program/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrInvalidRecord = errors.New("invalid trace record")
+
+// Summary describes the contents of a verified trace.
+type Summary struct {
+	Header Header
+	// Records is the total number of records in the trace.
+	Records uint64
+	// Bytes is the total size of all record values.
+	Bytes uint64
+	// ByType counts the records per pre-image key type.
+	ByType map[preimage.KeyType]uint64
+}
+
+// Verify reads the full trace from r and checks every record value against its key.
+// Keccak256 records must hash to their key, local records must match the boot info in the header.
+// The first invalid record is reported as ErrInvalidRecord.
+func Verify(r io.Reader) (*Summary, error) {
+	tr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	summary := &Summary{Header: tr.Header(), ByType: make(map[preimage.KeyType]uint64)}
+	for {
+		rec, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return summary, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if err := VerifyRecord(&summary.Header, rec); err != nil {
+			return nil, fmt.Errorf("%w %d (key %x): %v", ErrInvalidRecord, summary.Records, rec.Key, err)
+		}
+		summary.Records++
+		summary.Bytes += uint64(len(rec.Value))
+		summary.ByType[rec.Type()]++
+	}
+}
+
+// VerifyRecord checks the record value against its key, and local records against the boot info of the header.
+func VerifyRecord(header *Header, rec Record) error {
+	switch rec.Type() {
+	case preimage.Keccak256KeyType:
+		expected := preimage.Keccak256Key(crypto.Keccak256Hash(rec.Value)).PreimageKey()
+		if expected != rec.Key {
+			return fmt.Errorf("value hashes to %x", expected)
+		}
+		return nil
+	case preimage.LocalKeyType:
+		return verifyLocalRecord(header, rec)
+	default:
+		return fmt.Errorf("unknown key type %d", rec.Type())
+	}
+}
+
+func verifyLocalRecord(header *Header, rec Record) error {
+	var expected []byte
+	switch rec.Key {
+	case client.L1HeadLocalIndex.PreimageKey():
+		expected = header.L1Head.Bytes()
+	case client.L2HeadLocalIndex.PreimageKey():
+		expected = header.L2Head.Bytes()
+	case client.L2ClaimLocalIndex.PreimageKey():
+		expected = header.L2Claim.Bytes()
+	case client.L2ClaimBlockNumberLocalIndex.PreimageKey():
+		expected = binary.BigEndian.AppendUint64(nil, header.L2ClaimBlockNumber)
+	case client.L2ChainConfigLocalIndex.PreimageKey():
+		return verifyHash(header.L2ChainConfigHash, rec.Value)
+	case client.RollupConfigLocalIndex.PreimageKey():
+		return verifyHash(header.RollupConfigHash, rec.Value)
+	case client.L2ClaimsLocalIndex.PreimageKey():
+		return verifyHash(header.L2ClaimsHash, rec.Value)
+	case client.CheckpointLocalIndex.PreimageKey():
+		return verifyHash(header.CheckpointHash, rec.Value)
+	default:
+		return errors.New("unknown local key")
+	}
+	if !bytes.Equal(expected, rec.Value) {
+		return fmt.Errorf("value %x does not match header value %x", rec.Value, expected)
+	}
+	return nil
+}
+
+func verifyHash(expected common.Hash, value []byte) error {
+	if actual := crypto.Keccak256Hash(value); actual != expected {
+		return fmt.Errorf("value hash %s does not match header hash %s", actual, expected)
+	}
+	return nil
+}
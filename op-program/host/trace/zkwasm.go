@@ -0,0 +1,51 @@
+package trace
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+)
+
+// WriteZkWasmInput converts the trace read from r into the private and public input files consumed by the zkWasm prover.
+// Local key records, the boot values of the run, are written to publicOut as the wasm client reads them with wasm_input(1).
+// All other records are written to privateOut, read with wasm_input(0).
+// Both inputs are written in trace order, in the layout of preimage.BinaryRecorder.
+func WriteZkWasmInput(privateOut io.Writer, publicOut io.Writer, r io.Reader) error {
+	privateW := bufio.NewWriter(privateOut)
+	publicW := bufio.NewWriter(publicOut)
+	if err := RecordZkWasmInput(preimage.NewBinaryRecorder(privateW), preimage.NewBinaryRecorder(publicW), r); err != nil {
+		return err
+	}
+	if err := privateW.Flush(); err != nil {
+		return err
+	}
+	return publicW.Flush()
+}
+
+// RecordZkWasmInput passes the records of the trace read from r to the recorders of the private and public inputs,
+// split as by WriteZkWasmInput.
+func RecordZkWasmInput(private preimage.PreimageRecorder, public preimage.PreimageRecorder, r io.Reader) error {
+	tr, err := NewReader(r)
+	if err != nil {
+		return err
+	}
+	for {
+		rec, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		input := private
+		if rec.Type() == preimage.LocalKeyType {
+			input = public
+		}
+		if err := input.RecordPreimage(rec.Key, rec.Value); err != nil {
+			return fmt.Errorf("failed to write zkWasm input: %w", err)
+		}
+	}
+	return nil
+}
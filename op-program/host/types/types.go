@@ -0,0 +1,17 @@
+package types
+
+// DataFormat is the storage format of the pre-image data directory.
+type DataFormat string
+
+const (
+	// DataFormatDirectory stores every pre-image as a hex-encoded file in the data directory.
+	DataFormatDirectory DataFormat = "directory"
+	// DataFormatLevelDB stores the pre-images in a LevelDB database in the data directory.
+	DataFormatLevelDB DataFormat = "leveldb"
+)
+
+var SupportedDataFormats = []DataFormat{DataFormatDirectory, DataFormatLevelDB}
+
+func (f DataFormat) String() string {
+	return string(f)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/urfave/cli/v2"
+
+	preimage "github.com/ethereum-optimism/optimism/op-preimage"
+	"github.com/ethereum-optimism/optimism/op-program/client/driver"
+	"github.com/ethereum-optimism/optimism/op-program/host/trace"
+	"github.com/ethereum-optimism/optimism/op-program/zkwasm"
+)
+
+var (
+	PublicInputFlag = &cli.PathFlag{
+		Name:      "public",
+		Usage:     "Path of the public input file, read by wasm_input(1)",
+		TakesFile: true,
+	}
+	TraceFlag = &cli.PathFlag{
+		Name:      "trace",
+		Usage:     "Path of a pre-image trace to derive the private and public input from, instead of input files",
+		TakesFile: true,
+	}
+	CountFlag = &cli.BoolFlag{
+		Name:  "count",
+		Usage: "Count the executed wasm instructions",
+	}
+	ProfileFlag = &cli.BoolFlag{
+		Name:  "profile",
+		Usage: "Report the instructions spent reading and checking pre-images, per pre-image type. Implies --count.",
+	}
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "zkwasm"
+	app.Usage = "Run the op-program wasm client in a Go-native zkWasm emulator"
+	app.ArgsUsage = "<program.wasm> [preimages.bin]"
+	app.Flags = []cli.Flag{PublicInputFlag, TraceFlag, CountFlag, ProfileFlag}
+	app.Action = Run
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func Run(ctx *cli.Context) error {
+	tracePath := ctx.Path(TraceFlag.Name)
+	if tracePath != "" && ctx.NArg() != 1 {
+		return fmt.Errorf("expected 1 argument with --%s, got %d", TraceFlag.Name, ctx.NArg())
+	} else if tracePath == "" && ctx.NArg() != 2 {
+		return fmt.Errorf("expected 2 arguments, got %d", ctx.NArg())
+	}
+	program, err := os.ReadFile(ctx.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to read program: %w", err)
+	}
+	profile := ctx.Bool(ProfileFlag.Name)
+	cfg := zkwasm.Config{
+		Stdout:            os.Stdout,
+		Stderr:            os.Stderr,
+		CountInstructions: profile || ctx.Bool(CountFlag.Name),
+		ProfilePreimages:  profile,
+	}
+
+	// types of the pre-images in the private and public input, in read order, if known
+	var privateTypes, publicTypes []preimage.KeyType
+	if tracePath != "" {
+		data, err := os.ReadFile(tracePath)
+		if err != nil {
+			return fmt.Errorf("failed to read trace: %w", err)
+		}
+		var private, public bytes.Buffer
+		if err := trace.WriteZkWasmInput(&private, &public, bytes.NewReader(data)); err != nil {
+			return err
+		}
+		cfg.PrivateInput, cfg.PublicInput = &private, &public
+		_, records, err := trace.ReadAll(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		for _, rec := range records {
+			if rec.Type() == preimage.LocalKeyType {
+				publicTypes = append(publicTypes, rec.Type())
+			} else {
+				privateTypes = append(privateTypes, rec.Type())
+			}
+		}
+	} else {
+		private, closePrivate, err := openInput(ctx.Args().Get(1))
+		if err != nil {
+			return fmt.Errorf("failed to open private input: %w", err)
+		}
+		defer closePrivate()
+		cfg.PrivateInput = private
+		if path := ctx.Path(PublicInputFlag.Name); path != "" {
+			public, closePublic, err := openInput(path)
+			if err != nil {
+				return fmt.Errorf("failed to open public input: %w", err)
+			}
+			defer closePublic()
+			cfg.PublicInput = public
+		}
+	}
+
+	result, runErr := zkwasm.Run(context.Background(), program, cfg)
+	if result == nil {
+		return runErr
+	}
+	for _, v := range result.Outputs {
+		fmt.Printf("wasm_output: %d\n", v)
+	}
+	if len(result.Outputs) == driver.CheckpointLimbs+1 {
+		if cp, err := driver.CheckpointFromLimbs(result.Outputs[:driver.CheckpointLimbs]); err == nil {
+			fmt.Printf("checkpoint: safe head %s, l1 origin %s, output root %s\n", cp.SafeHead, cp.L1Origin, cp.OutputRoot)
+		}
+	}
+	fmt.Printf("inputs: %d private, %d public\n", result.PrivateInputs, result.PublicInputs)
+	if cfg.CountInstructions {
+		fmt.Printf("instructions: %d\n", result.Instructions)
+	}
+	if profile {
+		fmt.Printf("keccak host hashes: %d\n", result.KeccakHashes)
+		printProfile(os.Stdout, result.Preimages, privateTypes, publicTypes)
+	}
+	if runErr != nil {
+		return runErr
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("program exited with code %d", result.ExitCode)
+	}
+	if out, ok := result.Output(); !ok || out != zkwasm.OutputClaimValid {
+		return errors.New("program did not output a valid claim")
+	}
+	return nil
+}
+
+type profileTotals struct {
+	count, bytes, read, check uint64
+}
+
+// printProfile prints the pre-image profiles aggregated by pre-image type.
+// Without known types, pre-images are grouped by input channel.
+// openInput opens the input file at path, decompressing it if it is gzip compressed.
+// Uncompressed inputs start with a pre-image length prefix, which is never as large as the gzip magic.
+func openInput(path string) (io.Reader, func(), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := bufio.NewReader(f)
+	if magic, err := r.Peek(2); err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return r, func() { _ = f.Close() }, nil
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	return bufio.NewReader(gz), func() {
+		_ = gz.Close()
+		_ = f.Close()
+	}, nil
+}
+
+func printProfile(w io.Writer, profiles []zkwasm.PreimageProfile, privateTypes, publicTypes []preimage.KeyType) {
+	totals := make(map[string]*profileTotals)
+	var privateIdx, publicIdx int
+	for _, p := range profiles {
+		name := "private"
+		if p.Public {
+			name = "public"
+			if publicIdx < len(publicTypes) {
+				name = keyTypeName(publicTypes[publicIdx])
+			}
+			publicIdx++
+		} else {
+			if privateIdx < len(privateTypes) {
+				name = keyTypeName(privateTypes[privateIdx])
+			}
+			privateIdx++
+		}
+		t, ok := totals[name]
+		if !ok {
+			t = &profileTotals{}
+			totals[name] = t
+		}
+		t.count++
+		t.bytes += p.Size
+		t.read += p.ReadInstructions
+		t.check += p.CheckInstructions
+	}
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	_, _ = fmt.Fprintf(w, "%-10s %8s %12s %14s %14s %12s\n", "type", "count", "bytes", "read instr", "check instr", "check/byte")
+	for _, name := range names {
+		t := totals[name]
+		perByte := 0.0
+		if t.bytes > 0 {
+			perByte = float64(t.check) / float64(t.bytes)
+		}
+		_, _ = fmt.Fprintf(w, "%-10s %8d %12d %14d %14d %12.1f\n", name, t.count, t.bytes, t.read, t.check, perByte)
+	}
+}
+
+func keyTypeName(typ preimage.KeyType) string {
+	switch typ {
+	case preimage.LocalKeyType:
+		return "local"
+	case preimage.Keccak256KeyType:
+		return "keccak256"
+	default:
+		return fmt.Sprintf("type-%d", typ)
+	}
+}
@@ -0,0 +1,363 @@
+package zkwasm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// Output codes written by the op-program wasm client through wasm_output.
+const (
+	OutputClaimInvalid  uint64 = 1022
+	OutputProgramFailed uint64 = 1023
+	OutputClaimValid    uint64 = 1024
+)
+
+// DebugKeyImport is the host function the op-program wasm client built with the zkwasm_debug tag
+// reports the key of each pre-image with, as four big-endian limbs, before reading it.
+// zkWasm provers do not provide it, it is only for comparing the pre-images the client requests.
+const DebugKeyImport = "debug_preimage_key"
+
+var (
+	// ErrRequireFailed is returned when the program calls require with a zero condition,
+	// which makes the zkWasm proof unsatisfiable.
+	ErrRequireFailed = errors.New("require is not satisfied")
+	// ErrInputExhausted is returned when the program reads more input than available.
+	ErrInputExhausted = errors.New("input exhausted")
+	// ErrInvalidKeccakInput is returned when the program uses the keccak host functions incorrectly.
+	ErrInvalidKeccakInput = errors.New("invalid keccak host input")
+)
+
+// Config configures a single execution of a zkWasm program.
+type Config struct {
+	// PrivateInput is read by wasm_input(0), one big-endian uint64 per call.
+	PrivateInput io.Reader
+	// PublicInput is read by wasm_input(1), one big-endian uint64 per call.
+	PublicInput io.Reader
+	// Entry is the exported function to execute. Defaults to "_start".
+	Entry string
+	// Stdout and Stderr receive the WASI output of the program. Discarded if nil.
+	Stdout io.Writer
+	Stderr io.Writer
+	// CountInstructions instruments the program to count the executed wasm instructions.
+	CountInstructions bool
+	// ProfilePreimages attributes counted instructions to the pre-images read by the program.
+	// The inputs must be pre-image streams, as written by the op-program host. Requires CountInstructions.
+	ProfilePreimages bool
+}
+
+// Result describes a completed execution.
+type Result struct {
+	// Outputs are the values passed to wasm_output, in order.
+	Outputs []uint64
+	// PrivateInputs and PublicInputs are the number of values read by wasm_input.
+	PrivateInputs uint64
+	PublicInputs  uint64
+	// ExitCode is the WASI exit code of the program.
+	ExitCode uint32
+	// Instructions is the number of executed instructions, if counted.
+	Instructions uint64
+	// Preimages profiles every pre-image read, if ProfilePreimages is set.
+	Preimages []PreimageProfile
+	// KeccakHashes is the number of hashes computed with the keccak host functions.
+	KeccakHashes uint64
+	// KeysReported is true if the program imports DebugKeyImport to report the keys it requests.
+	KeysReported bool
+	// RequestedKeys are the pre-image keys reported through DebugKeyImport, in order.
+	RequestedKeys []common.Hash
+}
+
+// PreimageProfile describes the guest cost of a single pre-image read.
+// Instruction counts are accurate to the basic block that contains the host call.
+type PreimageProfile struct {
+	// Public is true if the pre-image was read from the public input.
+	Public bool
+	// Size is the length of the pre-image in bytes.
+	Size uint64
+	// ReadInstructions are executed from reading the length until the last value of the pre-image is read.
+	ReadInstructions uint64
+	// CheckInstructions are executed after the pre-image is read until the next require call,
+	// which is the integrity check of the pre-image.
+	CheckInstructions uint64
+}
+
+// Output returns the last value passed to wasm_output, or false if there is none.
+func (r *Result) Output() (uint64, bool) {
+	if len(r.Outputs) == 0 {
+		return 0, false
+	}
+	return r.Outputs[len(r.Outputs)-1], true
+}
+
+type hostIO struct {
+	private, public        io.Reader
+	privateCount, pubCount uint64
+	outputs                []uint64
+	keys                   []common.Hash
+
+	keccak *keccakHost
+
+	profile        bool
+	preimages      []PreimageProfile
+	privateStream  preimageStream
+	publicStream   preimageStream
+	pendingCheck   int // index of the pre-image awaiting its integrity check, or -1
+	pendingCounter uint64
+}
+
+// preimageStream tracks the framing of the pre-images of one input channel.
+type preimageStream struct {
+	remaining uint64 // values left in the current pre-image
+	current   int    // index of the current pre-image profile
+	start     uint64 // counter value when the current pre-image was started
+}
+
+func (h *hostIO) debugKey(_ context.Context, a, b, c, d uint64) {
+	var key common.Hash
+	for i, limb := range []uint64{a, b, c, d} {
+		binary.BigEndian.PutUint64(key[i*8:], limb)
+	}
+	h.keys = append(h.keys, key)
+}
+
+func (h *hostIO) wasmInput(_ context.Context, mod api.Module, isPublic uint32) uint64 {
+	v := h.readInput(isPublic)
+	if h.profile {
+		h.profileInput(counter(mod), isPublic != 0, v)
+	}
+	return v
+}
+
+func (h *hostIO) readInput(isPublic uint32) uint64 {
+	src, count, name := h.private, &h.privateCount, "private"
+	if isPublic != 0 {
+		src, count, name = h.public, &h.pubCount, "public"
+	}
+	if src == nil {
+		panic(fmt.Errorf("%w: no %s input", ErrInputExhausted, name))
+	}
+	var buf [8]byte
+	if _, err := io.ReadFull(src, buf[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			panic(fmt.Errorf("%w: %s input has %d values", ErrInputExhausted, name, *count))
+		}
+		panic(fmt.Errorf("failed to read %s input: %w", name, err))
+	}
+	*count++
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+func (h *hostIO) profileInput(count uint64, public bool, v uint64) {
+	s := &h.privateStream
+	if public {
+		s = &h.publicStream
+	}
+	if s.remaining > 0 {
+		s.remaining--
+	} else {
+		// v is the length of a new pre-image
+		s.current = len(h.preimages)
+		s.remaining = (v + 7) / 8
+		s.start = count
+		h.preimages = append(h.preimages, PreimageProfile{Public: public, Size: v})
+	}
+	if s.remaining == 0 {
+		h.preimages[s.current].ReadInstructions = count - s.start
+		h.pendingCheck = s.current
+		h.pendingCounter = count
+	}
+}
+
+func (h *hostIO) wasmOutput(_ context.Context, value uint64) {
+	h.outputs = append(h.outputs, value)
+}
+
+func (h *hostIO) require(_ context.Context, mod api.Module, cond uint32) {
+	if h.profile && h.pendingCheck >= 0 {
+		h.preimages[h.pendingCheck].CheckInstructions = counter(mod) - h.pendingCounter
+		h.pendingCheck = -1
+	}
+	if cond == 0 {
+		panic(ErrRequireFailed)
+	}
+}
+
+// keccakHost implements the zkWasm keccak host functions.
+// The program absorbs the Keccak-256 padded message as little-endian limbs,
+// then reads the digest as four little-endian limbs.
+type keccakHost struct {
+	padded []byte
+	digest []byte
+	hashes uint64
+}
+
+func (k *keccakHost) new(_ context.Context, _ uint64) {
+	k.padded = k.padded[:0]
+	k.digest = nil
+}
+
+func (k *keccakHost) push(_ context.Context, limb uint64) {
+	if k.digest != nil {
+		panic(fmt.Errorf("%w: push after finalize", ErrInvalidKeccakInput))
+	}
+	k.padded = binary.LittleEndian.AppendUint64(k.padded, limb)
+}
+
+func (k *keccakHost) finalize(_ context.Context) uint64 {
+	if k.digest == nil {
+		msg, err := keccakUnpad(k.padded)
+		if err != nil {
+			panic(err)
+		}
+		k.digest = crypto.Keccak256(msg)
+		k.hashes++
+	}
+	if len(k.digest) == 0 {
+		panic(fmt.Errorf("%w: digest already read", ErrInvalidKeccakInput))
+	}
+	limb := binary.LittleEndian.Uint64(k.digest)
+	k.digest = k.digest[8:]
+	return limb
+}
+
+// keccakRate is the number of bytes absorbed per Keccak-256 permutation.
+const keccakRate = 136
+
+// keccakUnpad strips the Keccak-256 padding from a padded message, checking it is well-formed.
+func keccakUnpad(padded []byte) ([]byte, error) {
+	if len(padded) == 0 || len(padded)%keccakRate != 0 {
+		return nil, fmt.Errorf("%w: padded length %d is not a multiple of the rate", ErrInvalidKeccakInput, len(padded))
+	}
+	last := len(padded) - 1
+	if padded[last]&0x80 == 0 {
+		return nil, fmt.Errorf("%w: missing final padding bit", ErrInvalidKeccakInput)
+	}
+	block := len(padded) - keccakRate
+	i := last
+	if padded[last] == 0x80 {
+		for i = last - 1; i >= block && padded[i] == 0; i-- {
+		}
+		if i < block || padded[i] != 0x01 {
+			return nil, fmt.Errorf("%w: invalid padding", ErrInvalidKeccakInput)
+		}
+	} else if padded[last] != 0x81 {
+		return nil, fmt.Errorf("%w: invalid padding", ErrInvalidKeccakInput)
+	}
+	return padded[:i], nil
+}
+
+// counter reads the instruction counter of an instrumented module.
+func counter(mod api.Module) uint64 {
+	if g := mod.ExportedGlobal(CounterExport); g != nil {
+		return g.Get()
+	}
+	return 0
+}
+
+// Run executes the wasm program, providing the zkWasm host functions of the "env" module and WASI preview1.
+// It returns the Result together with any execution error, so outputs and counts are available for failed runs too.
+func Run(ctx context.Context, program []byte, cfg Config) (*Result, error) {
+	if cfg.ProfilePreimages && !cfg.CountInstructions {
+		return nil, errors.New("profiling pre-images requires counting instructions")
+	}
+	if cfg.CountInstructions {
+		instrumented, err := Instrument(program)
+		if err != nil {
+			return nil, fmt.Errorf("failed to instrument program: %w", err)
+		}
+		program = instrumented
+	}
+	entry := cfg.Entry
+	if entry == "" {
+		entry = "_start"
+	}
+
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+	wasi_snapshot_preview1.MustInstantiate(ctx, r)
+
+	host := &hostIO{
+		private:      cfg.PrivateInput,
+		public:       cfg.PublicInput,
+		keccak:       &keccakHost{},
+		profile:      cfg.ProfilePreimages,
+		pendingCheck: -1,
+	}
+	_, err := r.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(host.wasmInput).Export("wasm_input").
+		NewFunctionBuilder().WithFunc(host.wasmOutput).Export("wasm_output").
+		NewFunctionBuilder().WithFunc(host.require).Export("require").
+		NewFunctionBuilder().WithFunc(host.keccak.new).Export("keccak_new").
+		NewFunctionBuilder().WithFunc(host.keccak.push).Export("keccak_push").
+		NewFunctionBuilder().WithFunc(host.keccak.finalize).Export("keccak_finalize").
+		NewFunctionBuilder().WithFunc(host.debugKey).Export(DebugKeyImport).
+		Instantiate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate host module: %w", err)
+	}
+
+	compiled, err := r.CompileModule(ctx, program)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile program: %w", err)
+	}
+	modCfg := wazero.NewModuleConfig().
+		WithStartFunctions(). // the entry is called explicitly below
+		WithArgs("zkwasm").
+		WithStdout(orDiscard(cfg.Stdout)).
+		WithStderr(orDiscard(cfg.Stderr))
+	mod, err := r.InstantiateModule(ctx, compiled, modCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate program: %w", err)
+	}
+	fn := mod.ExportedFunction(entry)
+	if fn == nil {
+		return nil, fmt.Errorf("program does not export entry function %q", entry)
+	}
+
+	_, runErr := fn.Call(ctx)
+	result := &Result{
+		Outputs:       host.outputs,
+		PrivateInputs: host.privateCount,
+		PublicInputs:  host.pubCount,
+		Preimages:     host.preimages,
+		KeccakHashes:  host.keccak.hashes,
+		KeysReported:  importsFunction(compiled, "env", DebugKeyImport),
+		RequestedKeys: host.keys,
+	}
+	if cfg.CountInstructions {
+		result.Instructions = counter(mod)
+	}
+	var exitErr *sys.ExitError
+	if errors.As(runErr, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		if result.ExitCode == 0 {
+			runErr = nil
+		}
+	}
+	return result, runErr
+}
+
+func importsFunction(compiled wazero.CompiledModule, module string, name string) bool {
+	for _, fn := range compiled.ImportedFunctions() {
+		if m, n, _ := fn.Import(); m == module && n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func orDiscard(w io.Writer) io.Writer {
+	if w == nil {
+		return io.Discard
+	}
+	return w
+}
@@ -0,0 +1,150 @@
+package zkwasm
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func inputs(values ...uint64) *bytes.Reader {
+	var out []byte
+	for _, v := range values {
+		out = binary.BigEndian.AppendUint64(out, v)
+	}
+	return bytes.NewReader(out)
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	program := testModule()
+
+	t.Run("Outputs", func(t *testing.T) {
+		result, err := Run(ctx, program, Config{PrivateInput: inputs(3, 1, 2, 3)})
+		require.NoError(t, err)
+		require.Equal(t, []uint64{6}, result.Outputs)
+		out, ok := result.Output()
+		require.True(t, ok)
+		require.EqualValues(t, 6, out)
+		require.EqualValues(t, 4, result.PrivateInputs)
+		require.Zero(t, result.Instructions, "should not count by default")
+	})
+
+	t.Run("CountInstructions", func(t *testing.T) {
+		for _, n := range []uint64{0, 1, 5} {
+			values := []uint64{n}
+			for i := uint64(0); i < n; i++ {
+				values = append(values, i+1)
+			}
+			result, _ := Run(ctx, program, Config{PrivateInput: inputs(values...), CountInstructions: true})
+			require.Equal(t, testModuleInstructions(n), result.Instructions, "inputs: %d", n)
+		}
+	})
+
+	t.Run("RequireFailed", func(t *testing.T) {
+		result, err := Run(ctx, program, Config{PrivateInput: inputs(2, 0, 0)})
+		require.ErrorIs(t, err, ErrRequireFailed)
+		require.Equal(t, []uint64{0}, result.Outputs, "outputs before failure should be reported")
+	})
+
+	t.Run("InputExhausted", func(t *testing.T) {
+		_, err := Run(ctx, program, Config{PrivateInput: inputs(3, 1)})
+		require.ErrorIs(t, err, ErrInputExhausted)
+	})
+
+	t.Run("PublicInput", func(t *testing.T) {
+		result, err := Run(ctx, program, Config{PublicInput: inputs(42), Entry: "public"})
+		require.NoError(t, err)
+		require.Equal(t, []uint64{42}, result.Outputs)
+		require.EqualValues(t, 1, result.PublicInputs)
+		require.Zero(t, result.PrivateInputs)
+	})
+
+	t.Run("NoPublicInput", func(t *testing.T) {
+		_, err := Run(ctx, program, Config{Entry: "public"})
+		require.ErrorIs(t, err, ErrInputExhausted)
+	})
+
+	t.Run("ProfilePreimages", func(t *testing.T) {
+		// A single private pre-image of 1 byte, checked by the require call of the program.
+		result, err := Run(ctx, program, Config{PrivateInput: inputs(1, 7), CountInstructions: true, ProfilePreimages: true})
+		require.NoError(t, err)
+		require.Len(t, result.Preimages, 1)
+		p := result.Preimages[0]
+		require.False(t, p.Public)
+		require.EqualValues(t, 1, p.Size)
+		require.NotZero(t, p.ReadInstructions)
+		require.NotZero(t, p.CheckInstructions)
+		require.LessOrEqual(t, p.ReadInstructions+p.CheckInstructions, result.Instructions)
+	})
+
+	t.Run("ProfileRequiresCount", func(t *testing.T) {
+		_, err := Run(ctx, program, Config{PrivateInput: inputs(1, 7), ProfilePreimages: true})
+		require.ErrorContains(t, err, "requires counting instructions")
+	})
+
+	t.Run("DebugKeys", func(t *testing.T) {
+		result, err := Run(ctx, program, Config{Entry: "key"})
+		require.NoError(t, err)
+		require.True(t, result.KeysReported)
+		var key common.Hash
+		for i := 0; i < 4; i++ {
+			binary.BigEndian.PutUint64(key[i*8:], uint64(i+1))
+		}
+		require.Equal(t, []common.Hash{key}, result.RequestedKeys)
+	})
+
+	t.Run("UnknownEntry", func(t *testing.T) {
+		_, err := Run(ctx, program, Config{Entry: "main"})
+		require.ErrorContains(t, err, "does not export entry function")
+	})
+}
+
+func TestKeccakHost(t *testing.T) {
+	ctx := context.Background()
+	pad := func(data []byte) []byte {
+		padded := append([]byte{}, data...)
+		padded = append(padded, 0x01)
+		for len(padded)%keccakRate != 0 {
+			padded = append(padded, 0)
+		}
+		padded[len(padded)-1] |= 0x80
+		return padded
+	}
+	hash := func(k *keccakHost, padded []byte) []byte {
+		k.new(ctx, 1)
+		for i := 0; i < len(padded); i += 8 {
+			k.push(ctx, binary.LittleEndian.Uint64(padded[i:]))
+		}
+		var digest []byte
+		for i := 0; i < 4; i++ {
+			digest = binary.LittleEndian.AppendUint64(digest, k.finalize(ctx))
+		}
+		return digest
+	}
+
+	k := &keccakHost{}
+	for _, size := range []int{0, 1, keccakRate - 1, keccakRate, 2*keccakRate + 5} {
+		data := bytes.Repeat([]byte{0x5a}, size)
+		require.Equal(t, crypto.Keccak256(data), hash(k, pad(data)), "size %d", size)
+	}
+	require.EqualValues(t, 5, k.hashes)
+
+	t.Run("InvalidPadding", func(t *testing.T) {
+		for _, padded := range [][]byte{nil, make([]byte, 8), make([]byte, keccakRate), append(make([]byte, keccakRate-1), 0x82)} {
+			_, err := keccakUnpad(padded)
+			require.ErrorIs(t, err, ErrInvalidKeccakInput)
+		}
+	})
+
+	t.Run("ReadPastDigest", func(t *testing.T) {
+		hash(k, pad(nil))
+		require.PanicsWithError(t, ErrInvalidKeccakInput.Error()+": digest already read", func() {
+			k.finalize(ctx)
+		})
+	})
+}
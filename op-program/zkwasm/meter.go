@@ -0,0 +1,518 @@
+package zkwasm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// CounterExport is the name under which Instrument exports the instruction counter global.
+const CounterExport = "__zkwasm_instruction_count"
+
+const (
+	sectionCustom    = 0
+	sectionImport    = 2
+	sectionGlobal    = 6
+	sectionExport    = 7
+	sectionCode      = 10
+	sectionData      = 11
+	sectionDataCount = 12
+
+	importKindGlobal = 0x03
+	exportKindGlobal = 0x03
+)
+
+var wasmHeader = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+var (
+	ErrInvalidModule       = errors.New("invalid wasm module")
+	ErrAlreadyInstrumented = errors.New("module is already instrumented")
+)
+
+// sectionOrder returns the position of a known section in a module, as required by the binary format.
+func sectionOrder(id byte) int {
+	switch id {
+	case sectionDataCount:
+		// the data count section is placed between the element and code sections
+		return 9
+	case sectionCode:
+		return 10
+	case sectionData:
+		return 11
+	default:
+		return int(id)
+	}
+}
+
+type section struct {
+	id      byte
+	content []byte
+}
+
+// Instrument rewrites the wasm module to count the executed instructions in an exported, mutable i64 global.
+// The counter is increased at the start of every straight-line sequence of instructions by the length of the sequence,
+// so the count is exact for every sequence that runs to completion.
+// The counter can be read after execution through the CounterExport global.
+func Instrument(module []byte) ([]byte, error) {
+	if !bytes.HasPrefix(module, wasmHeader) {
+		return nil, fmt.Errorf("%w: bad header", ErrInvalidModule)
+	}
+	sections, err := readSections(module[len(wasmHeader):])
+	if err != nil {
+		return nil, err
+	}
+
+	var importedGlobals, definedGlobals uint32
+	for _, s := range sections {
+		switch s.id {
+		case sectionImport:
+			if importedGlobals, err = countImportedGlobals(s.content); err != nil {
+				return nil, err
+			}
+		case sectionGlobal:
+			r := &reader{buf: s.content}
+			if definedGlobals, err = r.u32(); err != nil {
+				return nil, err
+			}
+		case sectionExport:
+			exported, err := hasExport(s.content, CounterExport)
+			if err != nil {
+				return nil, err
+			}
+			if exported {
+				return nil, ErrAlreadyInstrumented
+			}
+		}
+	}
+	counter := importedGlobals + definedGlobals
+
+	hasGlobals, hasExports := false, false
+	var out []section
+	for _, s := range sections {
+		if s.id != sectionCustom {
+			if !hasGlobals && sectionOrder(s.id) > sectionOrder(sectionGlobal) {
+				out = append(out, section{id: sectionGlobal, content: appendGlobal(nil)})
+				hasGlobals = true
+			}
+			if !hasExports && sectionOrder(s.id) > sectionOrder(sectionExport) {
+				out = append(out, section{id: sectionExport, content: appendExport(nil, counter)})
+				hasExports = true
+			}
+		}
+		switch s.id {
+		case sectionGlobal:
+			s.content = appendGlobal(s.content)
+			hasGlobals = true
+		case sectionExport:
+			s.content = appendExport(s.content, counter)
+			hasExports = true
+		case sectionCode:
+			if s.content, err = instrumentCode(s.content, counter); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, s)
+	}
+	if !hasGlobals {
+		out = append(out, section{id: sectionGlobal, content: appendGlobal(nil)})
+	}
+	if !hasExports {
+		out = append(out, section{id: sectionExport, content: appendExport(nil, counter)})
+	}
+
+	result := append([]byte{}, wasmHeader...)
+	for _, s := range out {
+		result = append(result, s.id)
+		result = appendU32(result, uint32(len(s.content)))
+		result = append(result, s.content...)
+	}
+	return result, nil
+}
+
+func readSections(data []byte) ([]section, error) {
+	r := &reader{buf: data}
+	var sections []section
+	for r.remaining() > 0 {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		content, err := r.vecBytes()
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", id, err)
+		}
+		sections = append(sections, section{id: id, content: content})
+	}
+	return sections, nil
+}
+
+func countImportedGlobals(content []byte) (uint32, error) {
+	r := &reader{buf: content}
+	n, err := r.u32()
+	if err != nil {
+		return 0, err
+	}
+	var globals uint32
+	for i := uint32(0); i < n; i++ {
+		// module and field names
+		if _, err := r.vecBytes(); err != nil {
+			return 0, err
+		}
+		if _, err := r.vecBytes(); err != nil {
+			return 0, err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		switch kind {
+		case 0x00: // function: type index
+			_, err = r.u32()
+		case 0x01: // table: reftype and limits
+			if _, err = r.byte(); err == nil {
+				err = r.limits()
+			}
+		case 0x02: // memory: limits
+			err = r.limits()
+		case importKindGlobal: // global: valtype and mutability
+			err = r.skip(2)
+			globals++
+		default:
+			err = fmt.Errorf("%w: unknown import kind %d", ErrInvalidModule, kind)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return globals, nil
+}
+
+func hasExport(content []byte, name string) (bool, error) {
+	r := &reader{buf: content}
+	n, err := r.u32()
+	if err != nil {
+		return false, err
+	}
+	for i := uint32(0); i < n; i++ {
+		exportName, err := r.vecBytes()
+		if err != nil {
+			return false, err
+		}
+		// export kind and index
+		if err := r.skip(1); err != nil {
+			return false, err
+		}
+		if _, err := r.u32(); err != nil {
+			return false, err
+		}
+		if string(exportName) == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// appendGlobal adds the mutable i64 counter global, initialized to zero, to the global section content.
+func appendGlobal(content []byte) []byte {
+	return appendVecEntry(content, []byte{0x7E, 0x01, 0x42, 0x00, 0x0B})
+}
+
+// appendExport adds the export of the counter global to the export section content.
+func appendExport(content []byte, counter uint32) []byte {
+	entry := appendU32(nil, uint32(len(CounterExport)))
+	entry = append(entry, CounterExport...)
+	entry = append(entry, exportKindGlobal)
+	entry = appendU32(entry, counter)
+	return appendVecEntry(content, entry)
+}
+
+// appendVecEntry appends the encoded entry to a section consisting of a single vector, updating its length.
+func appendVecEntry(content []byte, entry []byte) []byte {
+	var n uint32
+	var rest []byte
+	if len(content) > 0 {
+		r := &reader{buf: content}
+		// the section was already read successfully, so the length prefix is valid
+		n, _ = r.u32()
+		rest = r.buf[r.pos:]
+	}
+	out := appendU32(nil, n+1)
+	out = append(out, rest...)
+	return append(out, entry...)
+}
+
+func instrumentCode(content []byte, counter uint32) ([]byte, error) {
+	r := &reader{buf: content}
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	out := appendU32(nil, n)
+	for i := uint32(0); i < n; i++ {
+		body, err := r.vecBytes()
+		if err != nil {
+			return nil, fmt.Errorf("function body %d: %w", i, err)
+		}
+		instrumented, err := instrumentBody(body, counter)
+		if err != nil {
+			return nil, fmt.Errorf("function body %d: %w", i, err)
+		}
+		out = appendU32(out, uint32(len(instrumented)))
+		out = append(out, instrumented...)
+	}
+	return out, nil
+}
+
+type segment struct {
+	start, end int
+	count      int64
+}
+
+func instrumentBody(body []byte, counter uint32) ([]byte, error) {
+	r := &reader{buf: body}
+	groups, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < groups; i++ {
+		if _, err := r.u32(); err != nil {
+			return nil, err
+		}
+		if _, err := r.byte(); err != nil {
+			return nil, err
+		}
+	}
+	locals := body[:r.pos]
+
+	var segments []segment
+	cur := segment{start: r.pos}
+	for r.remaining() > 0 {
+		op, err := r.instruction()
+		if err != nil {
+			return nil, err
+		}
+		cur.count++
+		switch op {
+		case 0x00, 0x03, 0x04, 0x05, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F:
+			// unreachable, loop, if, else, end, br, br_if, br_table, return:
+			// the next instruction may be a branch target or is only conditionally reached.
+			cur.end = r.pos
+			segments = append(segments, cur)
+			cur = segment{start: r.pos}
+		}
+	}
+	if cur.count > 0 {
+		return nil, fmt.Errorf("%w: function body does not end with end instruction", ErrInvalidModule)
+	}
+
+	out := append([]byte{}, locals...)
+	for _, s := range segments {
+		out = append(out, 0x23) // global.get
+		out = appendU32(out, counter)
+		out = append(out, 0x42) // i64.const
+		out = appendS64(out, s.count)
+		out = append(out, 0x7C) // i64.add
+		out = append(out, 0x24) // global.set
+		out = appendU32(out, counter)
+		out = append(out, body[s.start:s.end]...)
+	}
+	return out, nil
+}
+
+type reader struct {
+	buf []byte
+	pos int
+}
+
+func (r *reader) remaining() int {
+	return len(r.buf) - r.pos
+}
+
+func (r *reader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, fmt.Errorf("%w: unexpected end", ErrInvalidModule)
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *reader) skip(n int) error {
+	if r.remaining() < n {
+		return fmt.Errorf("%w: unexpected end", ErrInvalidModule)
+	}
+	r.pos += n
+	return nil
+}
+
+// leb reads an LEB128 encoded integer of at most maxBits bits, returning the unsigned value of the raw bits.
+func (r *reader) leb(maxBits uint) (uint64, error) {
+	var result uint64
+	var shift uint
+	for {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		result |= uint64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			return result, nil
+		}
+		if shift >= maxBits {
+			return 0, fmt.Errorf("%w: LEB128 integer too long", ErrInvalidModule)
+		}
+	}
+}
+
+func (r *reader) u32() (uint32, error) {
+	v, err := r.leb(32)
+	return uint32(v), err
+}
+
+func (r *reader) vecBytes() ([]byte, error) {
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	start := r.pos
+	if err := r.skip(int(n)); err != nil {
+		return nil, err
+	}
+	return r.buf[start:r.pos], nil
+}
+
+func (r *reader) limits() error {
+	flags, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if _, err := r.u32(); err != nil {
+		return err
+	}
+	if flags&1 != 0 {
+		_, err = r.u32()
+	}
+	return err
+}
+
+func (r *reader) blockType() error {
+	b, err := r.byte()
+	if err != nil {
+		return err
+	}
+	switch b {
+	case 0x40, 0x7F, 0x7E, 0x7D, 0x7C, 0x7B, 0x70, 0x6F:
+		return nil
+	}
+	// type index, encoded as s33
+	r.pos--
+	_, err = r.leb(33)
+	return err
+}
+
+// instruction reads a single instruction including its immediates and returns the opcode.
+func (r *reader) instruction() (byte, error) {
+	op, err := r.byte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case op == 0x02 || op == 0x03 || op == 0x04: // block, loop, if
+		err = r.blockType()
+	case op == 0x0C || op == 0x0D || op == 0x10 || op == 0x12: // br, br_if, call, return_call
+		_, err = r.u32()
+	case op == 0x0E: // br_table
+		var n uint32
+		if n, err = r.u32(); err == nil {
+			for i := uint32(0); i <= n && err == nil; i++ {
+				_, err = r.u32()
+			}
+		}
+	case op == 0x11 || op == 0x13: // call_indirect, return_call_indirect
+		if _, err = r.u32(); err == nil {
+			_, err = r.u32()
+		}
+	case op == 0x1C: // typed select
+		var n uint32
+		if n, err = r.u32(); err == nil {
+			err = r.skip(int(n))
+		}
+	case op >= 0x20 && op <= 0x26: // local, global and table get/set
+		_, err = r.u32()
+	case op >= 0x28 && op <= 0x3E: // memory load/store: align and offset
+		if _, err = r.u32(); err == nil {
+			_, err = r.u32()
+		}
+	case op == 0x3F || op == 0x40: // memory.size, memory.grow
+		_, err = r.u32()
+	case op == 0x41: // i32.const
+		_, err = r.leb(32)
+	case op == 0x42: // i64.const
+		_, err = r.leb(64)
+	case op == 0x43: // f32.const
+		err = r.skip(4)
+	case op == 0x44: // f64.const
+		err = r.skip(8)
+	case op == 0xD0: // ref.null
+		err = r.skip(1)
+	case op == 0xD2: // ref.func
+		_, err = r.u32()
+	case op == 0xFC:
+		err = r.miscInstruction()
+	case op == 0x00 || op == 0x01 || op == 0x05 || op == 0x0B || op == 0x0F || op == 0x1A || op == 0x1B || op == 0xD1:
+		// unreachable, nop, else, end, return, drop, select, ref.is_null
+	case op >= 0x45 && op <= 0xC4: // numeric instructions without immediates
+	default:
+		err = fmt.Errorf("%w: unsupported opcode 0x%02x at offset %d", ErrInvalidModule, op, r.pos-1)
+	}
+	return op, err
+}
+
+// miscInstruction reads the immediates of a 0xFC prefixed instruction.
+func (r *reader) miscInstruction() error {
+	sub, err := r.u32()
+	if err != nil {
+		return err
+	}
+	immediates := 0
+	switch {
+	case sub <= 7: // saturating truncation
+	case sub == 9 || sub == 11 || sub == 13 || sub == 15 || sub == 16 || sub == 17:
+		// data.drop, memory.fill, elem.drop, table.grow, table.size, table.fill
+		immediates = 1
+	case sub == 8 || sub == 10 || sub == 12 || sub == 14:
+		// memory.init, memory.copy, table.init, table.copy
+		immediates = 2
+	default:
+		return fmt.Errorf("%w: unsupported opcode 0xFC %d", ErrInvalidModule, sub)
+	}
+	for i := 0; i < immediates; i++ {
+		if _, err := r.u32(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendU32(out []byte, v uint32) []byte {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func appendS64(out []byte, v int64) []byte {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
@@ -0,0 +1,48 @@
+package zkwasm
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+)
+
+func TestInstrument(t *testing.T) {
+	ctx := context.Background()
+	instrumented, err := Instrument(testModule())
+	require.NoError(t, err)
+
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+	_, err = r.CompileModule(ctx, instrumented)
+	require.NoError(t, err, "instrumented module must be valid")
+
+	_, err = Instrument(instrumented)
+	require.ErrorIs(t, err, ErrAlreadyInstrumented)
+}
+
+func TestInstrumentInvalid(t *testing.T) {
+	_, err := Instrument([]byte("not wasm"))
+	require.ErrorIs(t, err, ErrInvalidModule)
+
+	module := testModule()
+	_, err = Instrument(module[:len(module)-3])
+	require.ErrorIs(t, err, ErrInvalidModule)
+}
+
+func TestAppendS64(t *testing.T) {
+	for _, v := range []int64{0, 1, 63, 64, -1, -64, -65, 1 << 40, math.MaxInt64, math.MinInt64} {
+		r := &reader{buf: appendS64(nil, v)}
+		raw, err := r.leb(64)
+		require.NoError(t, err)
+		require.Zero(t, r.remaining())
+		// sign extend the decoded raw bits
+		shift := 64 - uint(len(r.buf))*7
+		if len(r.buf)*7 < 64 {
+			raw = uint64(int64(raw<<shift) >> shift)
+		}
+		require.Equal(t, v, int64(raw), "value %d", v)
+	}
+}
@@ -0,0 +1,85 @@
+package zkwasm
+
+// testModule assembles a minimal zkWasm program, importing the zkWasm host functions:
+//
+//	_start: reads n, then sums the next n private inputs, outputs the sum and requires it to be non-zero.
+//	public: outputs the first public input.
+//	key: reports the pre-image key with limbs 1, 2, 3 and 4 through the debug host function.
+func testModule() []byte {
+	vec := func(entries ...[]byte) []byte {
+		out := appendU32(nil, uint32(len(entries)))
+		for _, e := range entries {
+			out = append(out, e...)
+		}
+		return out
+	}
+	name := func(s string) []byte {
+		return append(appendU32(nil, uint32(len(s))), s...)
+	}
+	sec := func(id byte, content []byte) []byte {
+		return append(append([]byte{id}, appendU32(nil, uint32(len(content)))...), content...)
+	}
+	body := func(code []byte) []byte {
+		return append(appendU32(nil, uint32(len(code))), code...)
+	}
+	join := func(parts ...[]byte) []byte {
+		var out []byte
+		for _, p := range parts {
+			out = append(out, p...)
+		}
+		return out
+	}
+
+	types := vec(
+		[]byte{0x60, 0x01, 0x7F, 0x01, 0x7E},             // (i32) -> i64
+		[]byte{0x60, 0x01, 0x7E, 0x00},                   // (i64) -> ()
+		[]byte{0x60, 0x01, 0x7F, 0x00},                   // (i32) -> ()
+		[]byte{0x60, 0x00, 0x00},                         // () -> ()
+		[]byte{0x60, 0x04, 0x7E, 0x7E, 0x7E, 0x7E, 0x00}, // (i64, i64, i64, i64) -> ()
+	)
+	imports := vec(
+		join(name("env"), name("wasm_input"), []byte{0x00, 0x00}),
+		join(name("env"), name("wasm_output"), []byte{0x00, 0x01}),
+		join(name("env"), name("require"), []byte{0x00, 0x02}),
+		join(name("env"), name(DebugKeyImport), []byte{0x00, 0x04}),
+	)
+	funcs := vec([]byte{0x03}, []byte{0x03}, []byte{0x03})
+	exports := vec(
+		join(name("_start"), []byte{0x00, 0x04}),
+		join(name("public"), []byte{0x00, 0x05}),
+		join(name("key"), []byte{0x00, 0x06}),
+	)
+	start := []byte{
+		0x01, 0x02, 0x7E, // locals: 2 x i64 (n, sum)
+		0x41, 0x00, 0x10, 0x00, 0x21, 0x00, // n = wasm_input(0)
+		0x02, 0x40, // block
+		0x03, 0x40, // loop
+		0x20, 0x00, 0x50, 0x0D, 0x01, // br_if 1 (n == 0)
+		0x20, 0x01, 0x41, 0x00, 0x10, 0x00, 0x7C, 0x21, 0x01, // sum += wasm_input(0)
+		0x20, 0x00, 0x42, 0x01, 0x7D, 0x21, 0x00, // n -= 1
+		0x0C, 0x00, // br 0
+		0x0B,                   // end loop
+		0x0B,                   // end block
+		0x20, 0x01, 0x10, 0x01, // wasm_output(sum)
+		0x20, 0x01, 0x42, 0x00, 0x52, 0x10, 0x02, // require(sum != 0)
+		0x0B,
+	}
+	public := []byte{
+		0x00,                                     // no locals
+		0x41, 0x01, 0x10, 0x00, 0x10, 0x01, 0x0B, // wasm_output(wasm_input(1))
+	}
+	key := []byte{
+		0x00,                                           // no locals
+		0x42, 0x01, 0x42, 0x02, 0x42, 0x03, 0x42, 0x04, // i64.const 1, 2, 3, 4
+		0x10, 0x03, // debug_preimage_key
+		0x0B,
+	}
+	code := vec(body(start), body(public), body(key))
+
+	return join(wasmHeader, sec(1, types), sec(2, imports), sec(3, funcs), sec(7, exports), sec(10, code))
+}
+
+// testModuleInstructions is the number of instructions _start of testModule executes for n inputs.
+func testModuleInstructions(n uint64) uint64 {
+	return 15 + 13*n
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Writer is an io.Writer that logs every line written to it, e.g. the output of a sub-process.
+// Incomplete lines are buffered until they are completed or the Writer is closed.
+type Writer struct {
+	log  func(msg string, ctx ...interface{})
+	lock sync.Mutex
+	buf  []byte
+}
+
+// NewWriter creates a Writer that logs every line to l at the given level.
+// Lines are logged at info level for log.LvlCrit, which would exit the process, and for unknown levels.
+func NewWriter(l log.Logger, lvl log.Lvl) *Writer {
+	var logFn func(msg string, ctx ...interface{})
+	switch lvl {
+	case log.LvlTrace:
+		logFn = l.Trace
+	case log.LvlDebug:
+		logFn = l.Debug
+	case log.LvlWarn:
+		logFn = l.Warn
+	case log.LvlError:
+		logFn = l.Error
+	default:
+		logFn = l.Info
+	}
+	return &Writer{log: logFn}
+}
+
+func (w *Writer) Write(b []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.buf = append(w.buf, b...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+// Close logs any remaining incomplete line.
+func (w *Writer) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.buf) > 0 {
+		w.log(string(w.buf))
+		w.buf = nil
+	}
+	return nil
+}
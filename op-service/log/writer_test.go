@@ -0,0 +1,40 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	var lines []string
+	w := &Writer{log: func(msg string, ctx ...interface{}) {
+		lines = append(lines, msg)
+	}}
+	n, err := w.Write([]byte("first line\nsecond"))
+	require.NoError(t, err)
+	require.Equal(t, 17, n)
+	require.Equal(t, []string{"first line"}, lines)
+
+	_, err = w.Write([]byte(" line\n\nthird"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"first line", "second line", ""}, lines)
+
+	require.NoError(t, w.Close())
+	require.Equal(t, []string{"first line", "second line", "", "third"}, lines)
+}
+
+func TestWriterLevel(t *testing.T) {
+	var levels []log.Lvl
+	l := log.New()
+	l.SetHandler(log.FuncHandler(func(r *log.Record) error {
+		levels = append(levels, r.Lvl)
+		return nil
+	}))
+	for _, lvl := range []log.Lvl{log.LvlTrace, log.LvlDebug, log.LvlInfo, log.LvlWarn, log.LvlError, log.LvlCrit, log.Lvl(99)} {
+		_, err := NewWriter(l, lvl).Write([]byte("line\n"))
+		require.NoError(t, err)
+	}
+	require.Equal(t, []log.Lvl{log.LvlTrace, log.LvlDebug, log.LvlInfo, log.LvlWarn, log.LvlError, log.LvlInfo, log.LvlInfo}, levels)
+}